@@ -0,0 +1,215 @@
+// audit/audit.go
+package audit
+
+import (
+	"encoding/json"
+	"log"
+	"strings"
+	"time"
+
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+
+	"github.com/mfuadfakhruzzaki/backend-api/config"
+	"github.com/mfuadfakhruzzaki/backend-api/models"
+	"github.com/mfuadfakhruzzaki/backend-api/utils"
+)
+
+// Nama aksi yang dicatat. Awalan sebelum titik mengelompokkan aksi sehingga admin bisa
+// memfilter mis. semua aksi "admin.".
+const (
+	ActionLogin             = "auth.login"
+	ActionLoginFailed       = "auth.login_failed"
+	ActionAccountLocked     = "auth.account_locked"
+	ActionIdentityLinked    = "identity.linked"
+	ActionIdentityUnlinked  = "identity.unlinked"
+	ActionPasswordChanged   = "user.password_changed"
+	ActionPasswordReset     = "user.password_reset"
+	ActionEmailChanged      = "user.email_changed"
+	ActionPackageSelected   = "user.package_selected"
+	ActionTwoFactorEnabled  = "user.two_factor_enabled"
+	ActionTwoFactorDisabled = "user.two_factor_disabled"
+	ActionTokenCreated      = "user.token_created"
+	ActionTokenDeleted      = "user.token_deleted"
+	ActionSessionRevoked    = "user.session_revoked"
+	ActionAdminResetMFA     = "admin.two_factor_reset"
+	ActionAdminUnlock       = "admin.lockout_cleared"
+	ActionAdminVerifyEmail  = "admin.email_verified"
+	ActionAdminVerifyPhone  = "admin.phone_verified"
+	ActionAdminSetStatus    = "admin.status_changed"
+	ActionAdminLogout       = "admin.sessions_revoked"
+	ActionAdminResetLink    = "admin.password_reset_sent"
+	ActionImpersonation     = "admin.impersonation_started"
+	ActionImpersonationCall = "admin.impersonation_request"
+)
+
+const (
+	// DefaultLimit dan MaxLimit membatasi jumlah catatan per halaman
+	DefaultLimit = 50
+	MaxLimit     = 200
+	// maxUserAgentLength sama dengan ukuran kolom UserAgent
+	maxUserAgentLength = 512
+)
+
+// Entry adalah satu kejadian yang akan dicatat
+type Entry struct {
+	Action string
+	// ActorID adalah pengguna yang melakukan aksi; nil untuk permintaan anonim
+	ActorID *uint
+	// UserID adalah akun yang terdampak; nil jika akun tidak diketahui
+	UserID    *uint
+	IP        string
+	UserAgent string
+	// Before dan After berisi data sebelum dan sesudah perubahan, Metadata keterangan lain.
+	// Ketiganya diubah ke JSON; nilai nil tidak disimpan.
+	Before   interface{}
+	After    interface{}
+	Metadata interface{}
+}
+
+// Record menambahkan catatan ke audit log. Kegagalan hanya ditulis ke log supaya permintaan
+// pengguna tidak ikut gagal.
+func Record(e Entry) {
+	entry := models.AuditLog{
+		Action:    e.Action,
+		ActorID:   e.ActorID,
+		UserID:    e.UserID,
+		IPAddress: e.IP,
+		UserAgent: utils.Truncate(e.UserAgent, maxUserAgentLength),
+		Before:    toJSON(e.Before),
+		After:     toJSON(e.After),
+		Metadata:  toJSON(e.Metadata),
+	}
+	if err := config.DB.Create(&entry).Error; err != nil {
+		log.Printf("Failed to write audit log entry %s: %v", e.Action, err)
+	}
+}
+
+// Filter memilih catatan audit untuk ditampilkan. Field kosong tidak dipakai.
+type Filter struct {
+	UserID  *uint
+	ActorID *uint
+	// Action cocok persis, atau dengan awalan jika diakhiri titik (mis. "admin.")
+	Action string
+	// ExcludeActions menyembunyikan aksi tertentu, mis. dari riwayat yang dilihat pengguna
+	ExcludeActions []string
+	IP             string
+	From           time.Time
+	To             time.Time
+	// BeforeID dipakai untuk halaman berikutnya: hanya catatan dengan ID lebih kecil
+	BeforeID uint
+	Limit    int
+}
+
+// Query mengembalikan catatan yang cocok dengan filter, terbaru lebih dulu
+func Query(f Filter) ([]models.AuditLog, error) {
+	query := config.DB.Model(&models.AuditLog{})
+	if f.UserID != nil {
+		query = query.Where("user_id = ?", *f.UserID)
+	}
+	if f.ActorID != nil {
+		query = query.Where("actor_id = ?", *f.ActorID)
+	}
+	if f.Action != "" {
+		if strings.HasSuffix(f.Action, ".") {
+			query = query.Where(`action LIKE ? ESCAPE '\'`, utils.EscapeLike(f.Action)+"%")
+		} else {
+			query = query.Where("action = ?", f.Action)
+		}
+	}
+	if len(f.ExcludeActions) > 0 {
+		query = query.Where("action NOT IN ?", f.ExcludeActions)
+	}
+	if f.IP != "" {
+		query = query.Where("ip_address = ?", f.IP)
+	}
+	if !f.From.IsZero() {
+		query = query.Where("created_at >= ?", f.From)
+	}
+	if !f.To.IsZero() {
+		query = query.Where("created_at < ?", f.To)
+	}
+	if f.BeforeID > 0 {
+		query = query.Where("id < ?", f.BeforeID)
+	}
+
+	limit := f.Limit
+	if limit <= 0 {
+		limit = DefaultLimit
+	}
+	if limit > MaxLimit {
+		limit = MaxLimit
+	}
+
+	var entries []models.AuditLog
+	err := query.Order("id DESC").Limit(limit).Find(&entries).Error
+	return entries, err
+}
+
+// personalKeys adalah kunci di Before, After dan Metadata yang berisi data pribadi
+var personalKeys = []string{"email", "phone_number", "identifier"}
+
+// scrubbedValue menggantikan data pribadi di catatan audit akun yang sudah dihapus
+const scrubbedValue = "[deleted]"
+
+// ScrubUser menghapus email, nomor telepon dan identifier login dari catatan audit milik
+// pengguna yang akunnya dihapus. Catatannya sendiri tetap disimpan. Hook append-only
+// dilewati karena ini satu-satunya perubahan yang diizinkan pada audit log.
+func ScrubUser(tx *gorm.DB, userID uint) error {
+	var entries []models.AuditLog
+	if err := tx.Where("user_id = ?", userID).Find(&entries).Error; err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		before, changedBefore := scrubJSON(entry.Before)
+		after, changedAfter := scrubJSON(entry.After)
+		metadata, changedMetadata := scrubJSON(entry.Metadata)
+		if !changedBefore && !changedAfter && !changedMetadata {
+			continue
+		}
+		err := tx.Model(&models.AuditLog{}).Where("id = ?", entry.ID).UpdateColumns(map[string]interface{}{
+			"before":   before,
+			"after":    after,
+			"metadata": metadata,
+		}).Error
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// scrubJSON mengganti nilai personalKeys di objek JSON; data yang bukan objek dibiarkan
+func scrubJSON(data datatypes.JSON) (datatypes.JSON, bool) {
+	if len(data) == 0 {
+		return data, false
+	}
+	var fields map[string]interface{}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return data, false
+	}
+
+	changed := false
+	for _, key := range personalKeys {
+		if value, ok := fields[key]; ok && value != scrubbedValue {
+			fields[key] = scrubbedValue
+			changed = true
+		}
+	}
+	if !changed {
+		return data, false
+	}
+	return toJSON(fields), true
+}
+
+func toJSON(v interface{}) datatypes.JSON {
+	if v == nil {
+		return nil
+	}
+	data, err := json.Marshal(v)
+	if err != nil || string(data) == "null" {
+		return nil
+	}
+	return datatypes.JSON(data)
+}
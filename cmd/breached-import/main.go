@@ -0,0 +1,138 @@
+// cmd/breached-import/main.go
+//
+// Menambahkan password bocor ke PASSWORD_BREACHED_DIR dalam format k-anonymity
+// (<AWALAN 5 KARAKTER>.txt berisi baris <SISA HASH>:<JUMLAH>).
+//
+//	go run ./cmd/breached-import -dir ./breached -in daftar.txt [-plain]
+//
+// Tanpa -plain setiap baris masukan adalah hash SHA-1 (hex), boleh diikuti ":<jumlah>"
+// seperti file Pwned Passwords. Dengan -plain setiap baris adalah password asli yang akan
+// di-hash terlebih dahulu. Untuk data Pwned Passwords lengkap, lebih cepat memakai
+// haveibeenpwned-downloader yang langsung menghasilkan format yang sama.
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/mfuadfakhruzzaki/backend-api/passwordpolicy"
+)
+
+func main() {
+	dir := flag.String("dir", "./breached", "folder data password bocor (PASSWORD_BREACHED_DIR)")
+	in := flag.String("in", "", "file masukan, satu hash atau password per baris")
+	plain := flag.Bool("plain", false, "masukan berisi password asli, bukan hash SHA-1")
+	flag.Parse()
+
+	if *in == "" {
+		log.Fatal("Missing -in")
+	}
+	if err := os.MkdirAll(*dir, 0o755); err != nil {
+		log.Fatalf("Error creating %s: %v", *dir, err)
+	}
+
+	entries, err := readInput(*in, *plain)
+	if err != nil {
+		log.Fatalf("Error reading %s: %v", *in, err)
+	}
+
+	// Kelompokkan per awalan supaya setiap file hanya ditulis sekali
+	byPrefix := map[string]map[string]int{}
+	for hash, count := range entries {
+		prefix := hash[:passwordpolicy.PrefixLength]
+		if byPrefix[prefix] == nil {
+			byPrefix[prefix] = map[string]int{}
+		}
+		byPrefix[prefix][hash[passwordpolicy.PrefixLength:]] = count
+	}
+
+	for prefix, suffixes := range byPrefix {
+		if err := mergePrefixFile(filepath.Join(*dir, prefix+".txt"), suffixes); err != nil {
+			log.Fatalf("Error writing prefix %s: %v", prefix, err)
+		}
+	}
+	fmt.Printf("Imported %d hashes into %d files in %s\n", len(entries), len(byPrefix), *dir)
+}
+
+// readInput mengembalikan hash SHA-1 huruf besar beserta jumlah kemunculannya
+func readInput(path string, plain bool) (map[string]int, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	entries := map[string]int{}
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimRight(scanner.Text(), "\r")
+		if plain {
+			if text != "" {
+				entries[passwordpolicy.HashPassword(text)]++
+			}
+			continue
+		}
+
+		hash, countText, hasCount := strings.Cut(strings.TrimSpace(text), ":")
+		if hash == "" {
+			continue
+		}
+		hash = strings.ToUpper(hash)
+		if len(hash) != 40 || strings.Trim(hash, "0123456789ABCDEF") != "" {
+			return nil, fmt.Errorf("line %d: %q is not a SHA-1 hash", line, hash)
+		}
+		count := 1
+		if hasCount {
+			if count, err = strconv.Atoi(countText); err != nil || count < 1 {
+				return nil, fmt.Errorf("line %d: invalid count %q", line, countText)
+			}
+		}
+		entries[hash] += count
+	}
+	return entries, scanner.Err()
+}
+
+// mergePrefixFile menggabungkan hash baru dengan isi file yang sudah ada, jumlah kemunculan
+// dijumlahkan, lalu menulis ulang file secara terurut
+func mergePrefixFile(path string, suffixes map[string]int) error {
+	if data, err := os.ReadFile(path); err == nil {
+		for _, line := range strings.Split(string(data), "\n") {
+			suffix, countText, _ := strings.Cut(strings.TrimSpace(line), ":")
+			if suffix == "" {
+				continue
+			}
+			count, err := strconv.Atoi(countText)
+			if err != nil || count < 1 {
+				count = 1
+			}
+			suffixes[strings.ToUpper(suffix)] += count
+		}
+	} else if !os.IsNotExist(err) {
+		return err
+	}
+
+	keys := make([]string, 0, len(suffixes))
+	for suffix := range suffixes {
+		keys = append(keys, suffix)
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	for _, suffix := range keys {
+		fmt.Fprintf(&b, "%s:%d\n", suffix, suffixes[suffix])
+	}
+
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, []byte(b.String()), 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
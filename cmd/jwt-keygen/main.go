@@ -0,0 +1,105 @@
+// cmd/jwt-keygen/main.go
+//
+// Membuat kunci penandatangan JWT baru di JWT_KEYS_DIR. Nama file (tanpa .pem) menjadi kid.
+//
+//	go run ./cmd/jwt-keygen -dir ./keys [-alg EdDSA|RS256] [-kid 2024-06-01]
+//
+// Untuk rotasi, buat kunci baru lalu arahkan JWT_SIGNING_KID ke kid tersebut. Kunci lama
+// tetap di folder (atau diganti dengan public key-nya saja lewat -public) sampai token lama kedaluwarsa.
+package main
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+func main() {
+	dir := flag.String("dir", "./keys", "folder kunci JWT (JWT_KEYS_DIR)")
+	alg := flag.String("alg", "EdDSA", "algoritma kunci: EdDSA atau RS256")
+	kid := flag.String("kid", time.Now().UTC().Format("2006-01-02T150405"), "key ID, dipakai sebagai nama file")
+	public := flag.String("public", "", "ganti private key dengan kid ini menjadi public key saja (untuk kunci yang sudah dipensiunkan)")
+	flag.Parse()
+
+	if *public != "" {
+		if err := retireKey(*dir, *public); err != nil {
+			log.Fatalf("Error retiring key: %v", err)
+		}
+		fmt.Printf("Key %s now only verifies tokens\n", *public)
+		return
+	}
+
+	if strings.ContainsAny(*kid, `/\`) {
+		log.Fatalf("Invalid kid %q", *kid)
+	}
+
+	var key crypto.Signer
+	var err error
+	switch strings.ToUpper(*alg) {
+	case "EDDSA", "ED25519":
+		_, key, err = ed25519.GenerateKey(rand.Reader)
+	case "RS256", "RSA":
+		key, err = rsa.GenerateKey(rand.Reader, 3072)
+	default:
+		log.Fatalf("Unsupported algorithm %q", *alg)
+	}
+	if err != nil {
+		log.Fatalf("Error generating key: %v", err)
+	}
+
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		log.Fatalf("Error encoding key: %v", err)
+	}
+	if err := os.MkdirAll(*dir, 0o700); err != nil {
+		log.Fatalf("Error creating %s: %v", *dir, err)
+	}
+
+	path := filepath.Join(*dir, *kid+".pem")
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+	if err != nil {
+		log.Fatalf("Error creating %s: %v", path, err)
+	}
+	defer file.Close()
+	if err := pem.Encode(file, &pem.Block{Type: "PRIVATE KEY", Bytes: der}); err != nil {
+		log.Fatalf("Error writing %s: %v", path, err)
+	}
+
+	fmt.Printf("Wrote %s\nSet JWT_KEYS_DIR=%s and JWT_SIGNING_KID=%s to sign with it\n", path, *dir, *kid)
+}
+
+// retireKey menimpa private key dengan public key-nya sehingga kunci hanya bisa memverifikasi
+func retireKey(dir, kid string) error {
+	path := filepath.Join(dir, kid+".pem")
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	block, _ := pem.Decode(data)
+	if block == nil || block.Type != "PRIVATE KEY" {
+		return fmt.Errorf("%s is not a PKCS#8 private key", path)
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return err
+	}
+	signer, ok := parsed.(crypto.Signer)
+	if !ok {
+		return fmt.Errorf("unsupported key type %T", parsed)
+	}
+	der, err := x509.MarshalPKIXPublicKey(signer.Public())
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}), 0o644)
+}
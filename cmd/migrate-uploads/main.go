@@ -0,0 +1,144 @@
+// cmd/migrate-uploads/main.go
+//
+// Memindahkan file upload yang ada di folder lokal ke backend storage yang dikonfigurasi
+// (STORAGE_DRIVER) dan mengubah path lama "/uploads/..." di database menjadi key storage.
+//
+//	go run ./cmd/migrate-uploads -source ./uploads [-dry-run] [-delete-source]
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"mime"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/mfuadfakhruzzaki/backend-api/config"
+	"github.com/mfuadfakhruzzaki/backend-api/models"
+	"github.com/mfuadfakhruzzaki/backend-api/storage"
+	"gorm.io/datatypes"
+)
+
+func main() {
+	source := flag.String("source", "./uploads", "folder lokal yang berisi file upload lama")
+	dryRun := flag.Bool("dry-run", false, "hanya tampilkan apa yang akan dimigrasi")
+	deleteSource := flag.Bool("delete-source", false, "hapus file lokal setelah berhasil disalin")
+	flag.Parse()
+
+	// Hanya konfigurasi database dan storage yang dibutuhkan di sini
+	cfg, err := config.Load()
+	if err == nil {
+		err = cfg.Database.Validate()
+	}
+	if err != nil {
+		log.Fatalf("Invalid configuration: %v", err)
+	}
+	config.App = cfg
+	ctx := context.Background()
+
+	target, err := storage.New(cfg.Storage)
+	if err != nil {
+		log.Fatalf("Error initializing target storage: %v", err)
+	}
+	src := storage.NewLocalStorage(*source, "/uploads", nil, nil)
+
+	// Menyalin file ke storage tujuan, kecuali tujuannya adalah folder yang sama
+	sameRoot := false
+	if local, ok := target.(*storage.LocalStorage); ok {
+		a, _ := filepath.Abs(local.Root)
+		b, _ := filepath.Abs(*source)
+		sameRoot = a == b
+	}
+
+	keys, err := src.List(ctx, storage.ProfilePicturePrefix)
+	if err != nil {
+		log.Fatalf("Error listing %s: %v", *source, err)
+	}
+	copied := 0
+	for _, key := range keys {
+		if sameRoot {
+			break
+		}
+		if *dryRun {
+			fmt.Printf("would copy %s\n", key)
+			continue
+		}
+		if err := copyObject(ctx, src, target, key); err != nil {
+			log.Fatalf("Error copying %s: %v", key, err)
+		}
+		if *deleteSource {
+			if err := src.Delete(ctx, key); err != nil {
+				log.Printf("Error deleting local file %s: %v", key, err)
+			}
+		}
+		copied++
+	}
+	fmt.Printf("Copied %d file(s)\n", copied)
+
+	// Mengubah path lama di database menjadi key storage
+	config.ConnectDatabase(cfg.Database)
+	var users []models.User
+	if err := config.DB.Where("profile_picture LIKE ?", "/uploads/%").Find(&users).Error; err != nil {
+		log.Fatalf("Error loading users: %v", err)
+	}
+	for _, user := range users {
+		updates := map[string]interface{}{"profile_picture": storage.KeyFromLegacyPath(user.ProfilePicture)}
+		if variants := migrateVariants(user.ProfilePictureVariants); variants != nil {
+			updates["profile_picture_variants"] = variants
+		}
+		if *dryRun {
+			fmt.Printf("would update user %d: %v\n", user.ID, updates)
+			continue
+		}
+		if err := config.DB.Model(&user).Updates(updates).Error; err != nil {
+			log.Fatalf("Error updating user %d: %v", user.ID, err)
+		}
+	}
+	fmt.Printf("Updated %d user(s)\n", len(users))
+}
+
+// copyObject menyalin satu objek dari storage lokal ke storage tujuan
+func copyObject(ctx context.Context, src *storage.LocalStorage, dst storage.Storage, key string) error {
+	reader, err := src.Get(ctx, key)
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+
+	file, ok := reader.(*os.File)
+	if !ok {
+		return fmt.Errorf("unexpected reader for %s", key)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		return err
+	}
+	contentType := mime.TypeByExtension(path.Ext(key))
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	return dst.Put(ctx, key, reader, info.Size(), contentType)
+}
+
+// migrateVariants mengubah URL lama di daftar thumbnail menjadi key storage
+func migrateVariants(raw datatypes.JSON) datatypes.JSON {
+	if len(raw) == 0 {
+		return nil
+	}
+	var variants map[string]string
+	if err := json.Unmarshal(raw, &variants); err != nil {
+		return nil
+	}
+	for size, value := range variants {
+		if strings.HasPrefix(value, "/uploads/") {
+			variants[size] = storage.KeyFromLegacyPath(value)
+		}
+	}
+	migrated, _ := json.Marshal(variants)
+	return migrated
+}
@@ -3,9 +3,7 @@ package config
 import (
 	"fmt"
 	"log"
-	"os"
 
-	"github.com/joho/godotenv"
 	"github.com/mfuadfakhruzzaki/backend-api/models"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
@@ -13,24 +11,11 @@ import (
 
 var DB *gorm.DB
 
-// ConnectDatabase menghubungkan ke database dan memuat environment variables
-func ConnectDatabase() {
-	// Memuat file .env
-	err := godotenv.Load()
-	if err != nil {
-		log.Println("Peringatan: Gagal memuat file .env, menggunakan variabel lingkungan")
-	}
-
-	// Mengambil variabel lingkungan untuk koneksi database
-	dbHost := os.Getenv("DB_HOST")
-	dbUser := os.Getenv("DB_USER")
-	dbPassword := os.Getenv("DB_PASSWORD")
-	dbName := os.Getenv("DB_NAME")
-	dbPort := os.Getenv("DB_PORT")
-
+// ConnectDatabase menghubungkan ke database dan menjalankan migrasi
+func ConnectDatabase(cfg DatabaseConfig) {
 	// Data Source Name (DSN) untuk PostgreSQL
-	dsn := fmt.Sprintf("host=%s user=%s password=%s dbname=%s port=%s sslmode=disable TimeZone=Asia/Shanghai",
-		dbHost, dbUser, dbPassword, dbName, dbPort)
+	dsn := fmt.Sprintf("host=%s user=%s password=%s dbname=%s port=%d sslmode=%s TimeZone=%s",
+		cfg.Host, cfg.User, cfg.Password, cfg.Name, cfg.Port, cfg.SSLMode, cfg.TimeZone)
 	database, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
 
 	// Jika gagal terhubung ke database, panic
@@ -46,11 +31,78 @@ func ConnectDatabase() {
 	Migrate()
 }
 
+// CloseDatabase menutup koneksi database saat aplikasi dimatikan
+func CloseDatabase() error {
+	if DB == nil {
+		return nil
+	}
+	sqlDB, err := DB.DB()
+	if err != nil {
+		return err
+	}
+	return sqlDB.Close()
+}
+
 // Migrate menjalankan migrasi skema database berdasarkan model yang ada
 func Migrate() {
-	err := DB.AutoMigrate(&models.User{})
+	markLegacyOAuth := DB.Migrator().HasTable(&models.User{}) && !DB.Migrator().HasColumn(&models.User{}, "LegacyOAuth")
+	err := DB.AutoMigrate(
+		&models.User{},
+		&models.Organization{},
+		&models.OrganizationMember{},
+		&models.OrganizationLine{},
+		&models.LineUsageRecord{},
+		&models.OAuthState{},
+		&models.UserIdentity{},
+		&models.RecoveryCode{},
+		&models.MFAChallenge{},
+		&models.WebAuthnCredential{},
+		&models.WebAuthnSession{},
+		&models.MagicLink{},
+		&models.LoginThrottle{},
+		&models.PasswordReset{},
+		&models.PersonalAccessToken{},
+		&models.Session{},
+		&models.AuditLog{},
+	)
 	if err != nil {
 		log.Fatalf("Gagal melakukan migrasi database: %v", err)
 	}
+	if markLegacyOAuth {
+		migrateLegacyOAuthAccounts()
+	}
+	migrateIdentifierIndexes()
 	fmt.Println("Migrasi database berhasil!")
 }
+
+// migrateIdentifierIndexes membuat email dan username unik tanpa memperhatikan huruf
+// besar/kecil, dan nomor telepon unik setelah diverifikasi. Jika data lama masih berisi
+// duplikat, index dilewati dengan peringatan sampai duplikatnya dibereskan.
+func migrateIdentifierIndexes() {
+	if err := DB.Exec("UPDATE users SET email = LOWER(email) WHERE email <> LOWER(email)").Error; err != nil {
+		log.Printf("Peringatan: gagal mengubah email ke huruf kecil, ada email yang hanya berbeda huruf besar/kecil: %v", err)
+	}
+
+	statements := []string{
+		"CREATE UNIQUE INDEX IF NOT EXISTS idx_users_email_lower ON users (LOWER(email))",
+		"CREATE UNIQUE INDEX IF NOT EXISTS idx_users_username_lower ON users (LOWER(username))",
+		"CREATE UNIQUE INDEX IF NOT EXISTS idx_users_phone_verified ON users (phone_number) WHERE phone_verified AND phone_number <> ''",
+	}
+	for _, statement := range statements {
+		if err := DB.Exec(statement).Error; err != nil {
+			log.Printf("Peringatan: gagal membuat index (%s): %v", statement, err)
+		}
+	}
+}
+
+// migrateLegacyOAuthAccounts menandai akun tanpa password, identitas OAuth, maupun passkey,
+// yaitu akun yang dibuat login Google/GitHub lama. Hanya dijalankan sekali, saat kolom
+// legacy_oauth ditambahkan, sehingga akun tanpa password yang dibuat setelahnya tidak ikut.
+func migrateLegacyOAuthAccounts() {
+	err := DB.Exec(`UPDATE users SET legacy_oauth = ? WHERE password = '' `+
+		`AND id NOT IN (SELECT user_id FROM user_identities) `+
+		`AND id NOT IN (SELECT user_id FROM web_authn_credentials)`, true).Error
+	if err != nil {
+		log.Printf("Peringatan: gagal menandai akun OAuth lama: %v", err)
+	}
+}
@@ -0,0 +1,554 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/url"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/joho/godotenv"
+	"gopkg.in/yaml.v3"
+)
+
+// App adalah konfigurasi aplikasi yang sedang berjalan, diisi di main setelah Load
+var App = Default()
+
+// Config adalah seluruh konfigurasi aplikasi. Nilai diambil berurutan dari Default, file
+// YAML (CONFIG_FILE, atau config.yaml jika ada), file .env dan environment variables; sumber
+// yang belakangan menimpa yang sebelumnya. Tag env berisi nama environment variable, tag
+// secret menandai nilai yang disembunyikan oleh Redacted.
+type Config struct {
+	Server    ServerConfig    `yaml:"server" json:"server"`
+	Database  DatabaseConfig  `yaml:"database" json:"database"`
+	JWT       JWTConfig       `yaml:"jwt" json:"jwt"`
+	Password  PasswordConfig  `yaml:"password" json:"password"`
+	SMTP      SMTPConfig      `yaml:"smtp" json:"smtp"`
+	Storage   StorageConfig   `yaml:"storage" json:"storage"`
+	OAuth     OAuthConfig     `yaml:"oauth" json:"oauth"`
+	WebAuthn  WebAuthnConfig  `yaml:"webauthn" json:"webauthn"`
+	RateLimit RateLimitConfig `yaml:"rate_limit" json:"rate_limit"`
+	Account   AccountConfig   `yaml:"account" json:"account"`
+}
+
+// ServerConfig mengatur server HTTP, lihat server.New
+type ServerConfig struct {
+	Host string `yaml:"host" json:"host" env:"SERVER_HOST"` // kosong berarti semua interface
+	Port int    `yaml:"port" json:"port" env:"SERVER_PORT"`
+	// TLSCertFile dan TLSKeyFile mengaktifkan HTTPS. File dibaca ulang otomatis jika berubah,
+	// sehingga sertifikat bisa diperpanjang tanpa restart.
+	TLSCertFile string `yaml:"tls_cert_file" json:"tls_cert_file" env:"SERVER_TLS_CERT_FILE"`
+	TLSKeyFile  string `yaml:"tls_key_file" json:"tls_key_file" env:"SERVER_TLS_KEY_FILE"`
+	// TrustedProxies adalah IP atau CIDR reverse proxy yang boleh mengisi X-Forwarded-For.
+	// Kosong berarti header itu diabaikan dan IP klien diambil dari koneksi, sehingga rate
+	// limit dan lockout per IP tidak bisa diakali dengan header palsu.
+	TrustedProxies []string `yaml:"trusted_proxies" json:"trusted_proxies" env:"SERVER_TRUSTED_PROXIES"`
+	// Timeout koneksi, mis. "30s"; 0 berarti tanpa batas
+	ReadTimeout       time.Duration `yaml:"read_timeout" json:"read_timeout" env:"SERVER_READ_TIMEOUT" swaggertype:"integer"`
+	ReadHeaderTimeout time.Duration `yaml:"read_header_timeout" json:"read_header_timeout" env:"SERVER_READ_HEADER_TIMEOUT" swaggertype:"integer"`
+	WriteTimeout      time.Duration `yaml:"write_timeout" json:"write_timeout" env:"SERVER_WRITE_TIMEOUT" swaggertype:"integer"`
+	IdleTimeout       time.Duration `yaml:"idle_timeout" json:"idle_timeout" env:"SERVER_IDLE_TIMEOUT" swaggertype:"integer"`
+	// ShutdownTimeout adalah waktu maksimum untuk menyelesaikan permintaan yang sedang berjalan
+	// dan menghentikan worker saat aplikasi dimatikan
+	ShutdownTimeout time.Duration `yaml:"shutdown_timeout" json:"shutdown_timeout" env:"SERVER_SHUTDOWN_TIMEOUT" swaggertype:"integer"`
+}
+
+// Addr mengembalikan alamat listen dalam format host:port
+func (s ServerConfig) Addr() string {
+	return net.JoinHostPort(s.Host, strconv.Itoa(s.Port))
+}
+
+// TLSEnabled menandai bahwa server memakai HTTPS
+func (s ServerConfig) TLSEnabled() bool {
+	return s.TLSCertFile != ""
+}
+
+// DatabaseConfig berisi koneksi PostgreSQL
+type DatabaseConfig struct {
+	Host     string `yaml:"host" json:"host" env:"DB_HOST"`
+	Port     int    `yaml:"port" json:"port" env:"DB_PORT"`
+	User     string `yaml:"user" json:"user" env:"DB_USER"`
+	Password string `yaml:"password" json:"password" env:"DB_PASSWORD" secret:"true"`
+	Name     string `yaml:"name" json:"name" env:"DB_NAME"`
+	SSLMode  string `yaml:"sslmode" json:"sslmode" env:"DB_SSLMODE"`
+	TimeZone string `yaml:"timezone" json:"timezone" env:"DB_TIMEZONE"`
+}
+
+// JWTConfig berisi lokasi kunci dan claim token akses, lihat utils.InitJWTKeys
+type JWTConfig struct {
+	KeysDir    string `yaml:"keys_dir" json:"keys_dir" env:"JWT_KEYS_DIR"`
+	SigningKID string `yaml:"signing_kid" json:"signing_kid" env:"JWT_SIGNING_KID"`
+	Issuer     string `yaml:"issuer" json:"issuer" env:"JWT_ISSUER"`
+	Audience   string `yaml:"audience" json:"audience" env:"JWT_AUDIENCE"`
+}
+
+// PasswordConfig berisi parameter hashing (lihat utils.InitPasswordHashing) dan aturan
+// password (lihat passwordpolicy.Init)
+type PasswordConfig struct {
+	Argon2MemoryKiB     uint32 `yaml:"argon2_memory_kib" json:"argon2_memory_kib" env:"PASSWORD_ARGON2_MEMORY_KIB"`
+	Argon2Iterations    uint32 `yaml:"argon2_iterations" json:"argon2_iterations" env:"PASSWORD_ARGON2_ITERATIONS"`
+	Argon2Parallelism   uint8  `yaml:"argon2_parallelism" json:"argon2_parallelism" env:"PASSWORD_ARGON2_PARALLELISM"`
+	MinLength           int    `yaml:"min_length" json:"min_length" env:"PASSWORD_MIN_LENGTH"`
+	MaxLength           int    `yaml:"max_length" json:"max_length" env:"PASSWORD_MAX_LENGTH"`
+	MinCharacterClasses int    `yaml:"min_character_classes" json:"min_character_classes" env:"PASSWORD_MIN_CHARACTER_CLASSES"`
+	BreachedDir         string `yaml:"breached_dir" json:"breached_dir" env:"PASSWORD_BREACHED_DIR"`
+	BreachedMinCount    int    `yaml:"breached_min_count" json:"breached_min_count" env:"PASSWORD_BREACHED_MIN_COUNT"`
+}
+
+// SMTPConfig berisi server pengirim email
+type SMTPConfig struct {
+	Host     string `yaml:"host" json:"host" env:"SMTP_HOST"`
+	Port     int    `yaml:"port" json:"port" env:"SMTP_PORT"`
+	Sender   string `yaml:"sender" json:"sender" env:"SMTP_SENDER"`
+	Password string `yaml:"password" json:"password" env:"SMTP_PASSWORD" secret:"true"`
+}
+
+// StorageConfig memilih backend file upload, lihat storage.Init
+type StorageConfig struct {
+	Driver    string `yaml:"driver" json:"driver" env:"STORAGE_DRIVER"` // "local" atau "s3"
+	LocalRoot string `yaml:"local_root" json:"local_root" env:"STORAGE_LOCAL_ROOT"`
+	// SigningKey adalah kunci HMAC untuk URL bertanda tangan milik storage lokal
+	SigningKey string `yaml:"signing_key" json:"signing_key" env:"STORAGE_SIGNING_KEY" secret:"true"`
+	// PublicPrefixes adalah prefix key yang boleh diakses tanpa tanda tangan; kosong berarti
+	// hanya foto profil
+	PublicPrefixes []string `yaml:"public_prefixes" json:"public_prefixes" env:"STORAGE_PUBLIC_PREFIXES"`
+	S3             S3Config `yaml:"s3" json:"s3"`
+}
+
+// S3Config berisi koneksi ke storage yang kompatibel dengan S3
+type S3Config struct {
+	Endpoint  string `yaml:"endpoint" json:"endpoint" env:"STORAGE_S3_ENDPOINT"`
+	Region    string `yaml:"region" json:"region" env:"STORAGE_S3_REGION"`
+	Bucket    string `yaml:"bucket" json:"bucket" env:"STORAGE_S3_BUCKET"`
+	AccessKey string `yaml:"access_key" json:"access_key" env:"STORAGE_S3_ACCESS_KEY"`
+	SecretKey string `yaml:"secret_key" json:"secret_key" env:"STORAGE_S3_SECRET_KEY" secret:"true"`
+	UseSSL    bool   `yaml:"use_ssl" json:"use_ssl" env:"STORAGE_S3_USE_SSL"`
+	PublicURL string `yaml:"public_url" json:"public_url" env:"STORAGE_S3_PUBLIC_URL"`
+}
+
+// OAuthConfig berisi penyedia login OAuth2/OIDC, lihat oauth.Init
+type OAuthConfig struct {
+	Providers       []string `yaml:"providers" json:"providers" env:"OAUTH_PROVIDERS"`
+	RedirectBaseURL string   `yaml:"redirect_base_url" json:"redirect_base_url" env:"OAUTH_REDIRECT_BASE_URL"`
+	// RedirectAllowlist adalah URL frontend yang boleh menjadi tujuan redirect setelah login
+	RedirectAllowlist []string `yaml:"redirect_allowlist" json:"redirect_allowlist" env:"OAUTH_REDIRECT_ALLOWLIST"`
+	// Clients berisi pengaturan per penyedia. Environment variable-nya diawali
+	// OAUTH_<NAMA>_, mis. OAUTH_GOOGLE_CLIENT_ID.
+	Clients map[string]OAuthClientConfig `yaml:"clients" json:"clients"`
+}
+
+// OAuthClientConfig berisi pengaturan satu penyedia OAuth. Nilai kosong memakai preset
+// penyedia yang dikenal (google, github).
+type OAuthClientConfig struct {
+	ClientID     string `yaml:"client_id" json:"client_id" env:"CLIENT_ID"`
+	ClientSecret string `yaml:"client_secret" json:"client_secret" env:"CLIENT_SECRET" secret:"true"`
+	// Issuer dipakai untuk OIDC (discovery dan JWKS), AuthURL dan TokenURL untuk OAuth2 biasa
+	Issuer             string   `yaml:"issuer" json:"issuer" env:"ISSUER"`
+	AuthURL            string   `yaml:"auth_url" json:"auth_url" env:"AUTH_URL"`
+	TokenURL           string   `yaml:"token_url" json:"token_url" env:"TOKEN_URL"`
+	UserInfoURL        string   `yaml:"userinfo_url" json:"userinfo_url" env:"USERINFO_URL"`
+	EmailsURL          string   `yaml:"emails_url" json:"emails_url" env:"EMAILS_URL"`
+	Scopes             []string `yaml:"scopes" json:"scopes" env:"SCOPES"`
+	ClaimSubject       string   `yaml:"claim_subject" json:"claim_subject" env:"CLAIM_SUBJECT"`
+	ClaimEmail         string   `yaml:"claim_email" json:"claim_email" env:"CLAIM_EMAIL"`
+	ClaimEmailVerified string   `yaml:"claim_email_verified" json:"claim_email_verified" env:"CLAIM_EMAIL_VERIFIED"`
+	ClaimName          string   `yaml:"claim_name" json:"claim_name" env:"CLAIM_NAME"`
+	ClaimPicture       string   `yaml:"claim_picture" json:"claim_picture" env:"CLAIM_PICTURE"`
+}
+
+// WebAuthnConfig berisi relying party untuk login dengan passkey, lihat passkey.Init
+type WebAuthnConfig struct {
+	RPID      string   `yaml:"rp_id" json:"rp_id" env:"WEBAUTHN_RP_ID"` // domain tanpa skema dan port
+	RPOrigins []string `yaml:"rp_origins" json:"rp_origins" env:"WEBAUTHN_RP_ORIGINS"`
+	RPName    string   `yaml:"rp_name" json:"rp_name" env:"WEBAUTHN_RP_NAME"`
+}
+
+// RateLimitConfig memilih store rate limit dan batas per grup route, lihat ratelimit.Init
+type RateLimitConfig struct {
+	Store    string `yaml:"store" json:"store" env:"RATE_LIMIT_STORE"` // "memory" atau "redis"
+	RedisURL string `yaml:"redis_url" json:"redis_url" env:"RATE_LIMIT_REDIS_URL" secret:"true"`
+	// Policies menimpa batas bawaan per grup route, mis. register: "3/1h" atau global: "off".
+	// Environment variable-nya RATE_LIMIT_<NAMA>.
+	Policies map[string]string `yaml:"policies" json:"policies"`
+}
+
+// AccountConfig berisi pengaturan akun pengguna
+type AccountConfig struct {
+	// AdminEmails diberi role admin saat aplikasi dijalankan, lihat seeds.SeedAdmins
+	AdminEmails       []string `yaml:"admin_emails" json:"admin_emails" env:"ADMIN_EMAILS"`
+	DeletionGraceDays int      `yaml:"deletion_grace_days" json:"deletion_grace_days" env:"ACCOUNT_DELETION_GRACE_DAYS"`
+	// MagicLinkURL dan PasswordResetURL adalah halaman frontend yang menerima token di
+	// parameter "token". Jika kosong, email hanya berisi token atau kode.
+	MagicLinkURL     string `yaml:"magic_link_url" json:"magic_link_url" env:"MAGIC_LINK_URL"`
+	PasswordResetURL string `yaml:"password_reset_url" json:"password_reset_url" env:"PASSWORD_RESET_URL"`
+}
+
+// Default mengembalikan konfigurasi bawaan
+func Default() *Config {
+	return &Config{
+		Server: ServerConfig{
+			Port: 8080, ReadTimeout: 30 * time.Second, ReadHeaderTimeout: 10 * time.Second,
+			WriteTimeout: 60 * time.Second, IdleTimeout: 120 * time.Second, ShutdownTimeout: 30 * time.Second,
+		},
+		Database: DatabaseConfig{Port: 5432, SSLMode: "disable", TimeZone: "Asia/Shanghai"},
+		JWT:      JWTConfig{Issuer: "backend-api", Audience: "backend-api"},
+		// Argon2id mengikuti rekomendasi minimum OWASP (19 MiB, 2 iterasi, 1 thread)
+		Password: PasswordConfig{
+			Argon2MemoryKiB: 19 * 1024, Argon2Iterations: 2, Argon2Parallelism: 1,
+			MinLength: 10, MaxLength: 128, MinCharacterClasses: 2, BreachedMinCount: 1,
+		},
+		SMTP:    SMTPConfig{Port: 587},
+		Storage: StorageConfig{Driver: "local", LocalRoot: "./uploads", S3: S3Config{UseSSL: true}},
+		OAuth: OAuthConfig{
+			Providers:       []string{"google", "github"},
+			RedirectBaseURL: "http://localhost:8080",
+			Clients:         map[string]OAuthClientConfig{},
+		},
+		WebAuthn:  WebAuthnConfig{RPID: "localhost", RPOrigins: []string{"http://localhost:8080"}, RPName: "Data Quota Tracker"},
+		RateLimit: RateLimitConfig{Store: "memory", Policies: map[string]string{}},
+		Account:   AccountConfig{DeletionGraceDays: 30},
+	}
+}
+
+// Load membaca konfigurasi dari file YAML, file .env dan environment variables. Load hanya
+// gagal jika ada nilai yang tidak bisa dibaca; Validate memeriksa isi konfigurasinya.
+func Load() (*Config, error) {
+	cfg := Default()
+
+	// File .env tidak menimpa environment variables yang sudah ada
+	if err := godotenv.Load(); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf(".env: %w", err)
+	}
+
+	path, required := os.LookupEnv("CONFIG_FILE")
+	if !required {
+		path = "config.yaml"
+	}
+	if err := loadYAML(cfg, path, required); err != nil {
+		return nil, err
+	}
+
+	if err := loadEnv(reflect.ValueOf(cfg).Elem(), ""); err != nil {
+		return nil, err
+	}
+	if err := loadDynamicEnv(cfg); err != nil {
+		return nil, err
+	}
+
+	cfg.normalize()
+	return cfg, nil
+}
+
+func loadYAML(cfg *Config, path string, required bool) error {
+	file, err := os.Open(path)
+	if err != nil {
+		if !required && errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+	defer file.Close()
+
+	// Key yang tidak dikenal ditolak supaya salah ketik tidak diam-diam diabaikan
+	decoder := yaml.NewDecoder(file)
+	decoder.KnownFields(true)
+	if err := decoder.Decode(cfg); err != nil && err != io.EOF {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	log.Printf("Konfigurasi dibaca dari %s", path)
+	return nil
+}
+
+// loadEnv mengisi field yang punya tag env dari environment variable prefix+tag
+func loadEnv(v reflect.Value, prefix string) error {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field, value := t.Field(i), v.Field(i)
+		if field.Type.Kind() == reflect.Struct {
+			if err := loadEnv(value, prefix); err != nil {
+				return err
+			}
+			continue
+		}
+		name := field.Tag.Get("env")
+		if name == "" {
+			continue
+		}
+		name = prefix + name
+		raw, ok := os.LookupEnv(name)
+		if !ok || strings.TrimSpace(raw) == "" {
+			continue
+		}
+		if err := setValue(value, strings.TrimSpace(raw)); err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+func setValue(v reflect.Value, raw string) error {
+	if v.Type() == reflect.TypeOf(time.Duration(0)) {
+		d, err := time.ParseDuration(raw)
+		if err != nil {
+			return fmt.Errorf("invalid duration %q, expected e.g. 30s or 2m", raw)
+		}
+		v.SetInt(int64(d))
+		return nil
+	}
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(raw)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return fmt.Errorf("invalid boolean %q", raw)
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int64:
+		n, err := strconv.ParseInt(raw, 10, v.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("invalid number %q", raw)
+		}
+		v.SetInt(n)
+	case reflect.Uint8, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(raw, 10, v.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("invalid number %q, expected 0 to %d", raw, uint64(1)<<v.Type().Bits()-1)
+		}
+		v.SetUint(n)
+	case reflect.Slice:
+		// Daftar dipisahkan koma atau spasi
+		items := strings.FieldsFunc(raw, func(r rune) bool { return r == ',' || r == ' ' })
+		v.Set(reflect.ValueOf(items))
+	default:
+		return fmt.Errorf("unsupported type %s", v.Type())
+	}
+	return nil
+}
+
+// loadDynamicEnv membaca environment variables yang namanya mengandung nama penyedia OAuth
+// atau grup rate limit
+func loadDynamicEnv(cfg *Config) error {
+	if cfg.OAuth.Clients == nil {
+		cfg.OAuth.Clients = map[string]OAuthClientConfig{}
+	}
+	for _, name := range cfg.OAuth.Providers {
+		name = strings.ToLower(name)
+		client := cfg.OAuth.Clients[name]
+		if err := loadEnv(reflect.ValueOf(&client).Elem(), "OAUTH_"+strings.ToUpper(name)+"_"); err != nil {
+			return err
+		}
+		if client.ClientID == "" {
+			// Nama variabel lama, mis. GOOGLE_CLIENT_ID dan GITHUB_CLIENT_SECRET
+			client.ClientID = os.Getenv(strings.ToUpper(name) + "_CLIENT_ID")
+			client.ClientSecret = os.Getenv(strings.ToUpper(name) + "_CLIENT_SECRET")
+		}
+		cfg.OAuth.Clients[name] = client
+	}
+
+	if cfg.RateLimit.Policies == nil {
+		cfg.RateLimit.Policies = map[string]string{}
+	}
+	for _, entry := range os.Environ() {
+		key, value, _ := strings.Cut(entry, "=")
+		name, ok := strings.CutPrefix(key, "RATE_LIMIT_")
+		if !ok || key == "RATE_LIMIT_STORE" || key == "RATE_LIMIT_REDIS_URL" || strings.TrimSpace(value) == "" {
+			continue
+		}
+		cfg.RateLimit.Policies[strings.ToLower(name)] = strings.TrimSpace(value)
+	}
+	return nil
+}
+
+func (c *Config) normalize() {
+	c.Storage.Driver = strings.ToLower(c.Storage.Driver)
+	c.RateLimit.Store = strings.ToLower(c.RateLimit.Store)
+	c.OAuth.RedirectBaseURL = strings.TrimSuffix(c.OAuth.RedirectBaseURL, "/")
+	for i, name := range c.OAuth.Providers {
+		c.OAuth.Providers[i] = strings.ToLower(strings.TrimSpace(name))
+	}
+	for i, email := range c.Account.AdminEmails {
+		c.Account.AdminEmails[i] = strings.ToLower(strings.TrimSpace(email))
+	}
+}
+
+// Validate memeriksa seluruh konfigurasi dan mengembalikan semua kesalahan sekaligus
+func (c *Config) Validate() error {
+	return errors.Join(
+		c.Server.Validate(),
+		c.Database.Validate(),
+		c.JWT.Validate(),
+		c.SMTP.Validate(),
+		c.Storage.Validate(),
+		c.OAuth.Validate(),
+		c.RateLimit.Validate(),
+		c.Account.Validate(),
+	)
+}
+
+// Validate memeriksa alamat, TLS dan timeout server
+func (s ServerConfig) Validate() error {
+	var errs []error
+	if s.Port < 1 || s.Port > 65535 {
+		errs = append(errs, errors.New("SERVER_PORT must be between 1 and 65535"))
+	}
+	for _, proxy := range s.TrustedProxies {
+		if _, _, err := net.ParseCIDR(proxy); err != nil && net.ParseIP(proxy) == nil {
+			errs = append(errs, fmt.Errorf("SERVER_TRUSTED_PROXIES entry %q must be an IP address or CIDR", proxy))
+		}
+	}
+	if (s.TLSCertFile == "") != (s.TLSKeyFile == "") {
+		errs = append(errs, errors.New("SERVER_TLS_CERT_FILE and SERVER_TLS_KEY_FILE must be set together"))
+	}
+	for _, timeout := range []struct {
+		name  string
+		value time.Duration
+	}{
+		{"SERVER_READ_TIMEOUT", s.ReadTimeout},
+		{"SERVER_READ_HEADER_TIMEOUT", s.ReadHeaderTimeout},
+		{"SERVER_WRITE_TIMEOUT", s.WriteTimeout},
+		{"SERVER_IDLE_TIMEOUT", s.IdleTimeout},
+	} {
+		if timeout.value < 0 {
+			errs = append(errs, fmt.Errorf("%s must not be negative", timeout.name))
+		}
+	}
+	if s.ShutdownTimeout <= 0 {
+		errs = append(errs, errors.New("SERVER_SHUTDOWN_TIMEOUT must be positive"))
+	}
+	return errors.Join(errs...)
+}
+
+// Validate memeriksa koneksi database
+func (d DatabaseConfig) Validate() error {
+	errs := requireSet("%s is not set", "DB_HOST", d.Host, "DB_USER", d.User, "DB_NAME", d.Name)
+	if d.Port < 1 || d.Port > 65535 {
+		errs = append(errs, errors.New("DB_PORT must be between 1 and 65535"))
+	}
+	return errors.Join(errs...)
+}
+
+// Validate memeriksa bahwa lokasi kunci JWT diisi
+func (j JWTConfig) Validate() error {
+	if j.KeysDir == "" {
+		return errors.New("JWT_KEYS_DIR is not set; generate a key with `go run ./cmd/jwt-keygen -dir ./keys`")
+	}
+	return nil
+}
+
+// Validate memeriksa server SMTP. Email dibutuhkan untuk verifikasi akun, sehingga aplikasi
+// tidak dijalankan tanpa SMTP.
+func (s SMTPConfig) Validate() error {
+	errs := requireSet("%s is not set", "SMTP_HOST", s.Host, "SMTP_SENDER", s.Sender, "SMTP_PASSWORD", s.Password)
+	if s.Port < 1 || s.Port > 65535 {
+		errs = append(errs, errors.New("SMTP_PORT must be between 1 and 65535"))
+	}
+	return errors.Join(errs...)
+}
+
+// Validate memeriksa backend storage yang dipilih
+func (s StorageConfig) Validate() error {
+	switch s.Driver {
+	case "local":
+		if s.LocalRoot == "" {
+			return errors.New("STORAGE_LOCAL_ROOT is not set")
+		}
+	case "s3":
+		return errors.Join(requireSet("%s is required for the s3 storage driver",
+			"STORAGE_S3_ENDPOINT", s.S3.Endpoint,
+			"STORAGE_S3_BUCKET", s.S3.Bucket,
+			"STORAGE_S3_ACCESS_KEY", s.S3.AccessKey,
+			"STORAGE_S3_SECRET_KEY", s.S3.SecretKey)...)
+	default:
+		return fmt.Errorf("unknown STORAGE_DRIVER %q", s.Driver)
+	}
+	return nil
+}
+
+// Validate memeriksa URL redirect OAuth
+func (o OAuthConfig) Validate() error {
+	var errs []error
+	if u, err := url.Parse(o.RedirectBaseURL); err != nil || u.Host == "" || (u.Scheme != "http" && u.Scheme != "https") {
+		errs = append(errs, fmt.Errorf("OAUTH_REDIRECT_BASE_URL %q must be an absolute http(s) URL", o.RedirectBaseURL))
+	}
+	for _, entry := range o.RedirectAllowlist {
+		if u, err := url.Parse(entry); err != nil || u.Host == "" {
+			errs = append(errs, fmt.Errorf("OAUTH_REDIRECT_ALLOWLIST entry %q must be an absolute URL", entry))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Validate memeriksa store rate limit yang dipilih
+func (r RateLimitConfig) Validate() error {
+	switch r.Store {
+	case "memory":
+	case "redis":
+		if r.RedisURL == "" {
+			return errors.New("RATE_LIMIT_REDIS_URL is required for the redis rate limit store")
+		}
+	default:
+		return fmt.Errorf("unknown RATE_LIMIT_STORE %q", r.Store)
+	}
+	return nil
+}
+
+// Validate memeriksa pengaturan akun
+func (a AccountConfig) Validate() error {
+	if a.DeletionGraceDays < 0 {
+		return errors.New("ACCOUNT_DELETION_GRACE_DAYS must not be negative")
+	}
+	return nil
+}
+
+// requireSet mengembalikan satu error untuk setiap pasangan nama dan nilai yang nilainya kosong
+func requireSet(format string, pairs ...string) []error {
+	var errs []error
+	for i := 0; i+1 < len(pairs); i += 2 {
+		if pairs[i+1] == "" {
+			errs = append(errs, fmt.Errorf(format, pairs[i]))
+		}
+	}
+	return errs
+}
+
+// redactedValue menggantikan rahasia yang diisi pada hasil Redacted
+const redactedValue = "[REDACTED]"
+
+// Redacted mengembalikan salinan konfigurasi dengan semua nilai bertag secret diganti
+// [REDACTED]. Rahasia yang kosong tetap kosong supaya terlihat belum diisi.
+func (c *Config) Redacted() Config {
+	out := *c
+	redact(reflect.ValueOf(&out).Elem())
+	return out
+}
+
+func redact(v reflect.Value) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field, value := t.Field(i), v.Field(i)
+		switch {
+		case field.Type.Kind() == reflect.Struct:
+			redact(value)
+		case field.Type.Kind() == reflect.Map && field.Type.Elem().Kind() == reflect.Struct && !value.IsNil():
+			// Map disalin supaya konfigurasi aslinya tidak ikut berubah
+			copied := reflect.MakeMapWithSize(field.Type, value.Len())
+			iter := value.MapRange()
+			for iter.Next() {
+				elem := reflect.New(field.Type.Elem()).Elem()
+				elem.Set(iter.Value())
+				redact(elem)
+				copied.SetMapIndex(iter.Key(), elem)
+			}
+			value.Set(copied)
+		case field.Tag.Get("secret") == "true" && value.Kind() == reflect.String && value.String() != "":
+			value.SetString(redactedValue)
+		}
+	}
+}
@@ -0,0 +1,292 @@
+package controllers
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/mfuadfakhruzzaki/backend-api/config"
+	"github.com/mfuadfakhruzzaki/backend-api/models"
+	"github.com/mfuadfakhruzzaki/backend-api/storage"
+	"github.com/mfuadfakhruzzaki/backend-api/utils"
+)
+
+const (
+	// exportPrefix is the private storage prefix for exports delivered as links
+	exportPrefix = "exports/"
+	// exportLinkTTL is how long a signed export download link stays valid
+	exportLinkTTL = 15 * time.Minute
+)
+
+// AccountDeletionRequest represents the body for scheduling an account deletion
+type AccountDeletionRequest struct {
+	Password string `json:"password"`
+}
+
+// DataExport is the archive of all data held for a user
+type DataExport struct {
+	ExportedAt     time.Time                   `json:"exported_at"`
+	Profile        models.User                 `json:"profile"`
+	Package        *models.Package             `json:"package,omitempty"`
+	Organizations  []models.OrganizationMember `json:"organizations"`
+	OAuth          OAuthExport                 `json:"oauth"`
+	ProfilePicture *ProfilePictureExport       `json:"profile_picture,omitempty"`
+}
+
+// OAuthExport contains the data that was derived from an OAuth provider
+type OAuthExport struct {
+	// Identities are the linked provider accounts: provider, subject, email and link time
+	Identities        []models.UserIdentity `json:"identities"`
+	ProfilePictureURL string                `json:"profile_picture_url,omitempty"`
+}
+
+// ProfilePictureExport describes the stored profile picture
+type ProfilePictureExport struct {
+	Path         string `json:"path"`
+	ArchiveEntry string `json:"archive_entry,omitempty"`
+}
+
+// ExportUserData returns all data held for the current user
+// @Summary Export personal data
+// @Description Export all data held for the current user, including profile, selected package, organization memberships, linked OAuth provider accounts (provider, subject, email and link time) and other OAuth-derived data, and the profile picture. Use format=zip to receive a ZIP archive containing data.json and the picture file. With delivery=link the archive is stored privately and a signed, time-limited download URL is returned instead.
+// @Tags User
+// @Produce json
+// @Produce application/zip
+// @Param format query string false "json (default) or zip"
+// @Param delivery query string false "inline (default) or link"
+// @Success 200 {object} DataExport "Exported data"
+// @Failure 400 {object} ErrorResponse "Unsupported format"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 500 {object} ErrorResponse "Database error"
+// @Router /users/export [get]
+func ExportUserData(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	format := c.DefaultQuery("format", "json")
+	if format != "json" && format != "zip" {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Unsupported format, use json or zip"})
+		return
+	}
+	delivery := c.DefaultQuery("delivery", "inline")
+	if delivery != "inline" && delivery != "link" {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Unsupported delivery, use inline or link"})
+		return
+	}
+
+	ctx := c.Request.Context()
+	export, pictureKey, err := buildDataExport(user)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Database error"})
+		return
+	}
+
+	filename := fmt.Sprintf("user_%d_export_%s", user.ID, export.ExportedAt.Format("20060102"))
+	if delivery == "link" {
+		var buf bytes.Buffer
+		contentType := "application/json"
+		if format == "zip" {
+			contentType = "application/zip"
+			err = writeExportArchive(ctx, &buf, export, pictureKey)
+		} else {
+			err = json.NewEncoder(&buf).Encode(export)
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Error building export"})
+			return
+		}
+
+		key := fmt.Sprintf("%s%s_%d.%s", exportPrefix, filename, export.ExportedAt.Unix(), format)
+		if err := storage.Default.Put(ctx, key, &buf, int64(buf.Len()), contentType); err != nil {
+			c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Error storing export"})
+			return
+		}
+		url, err := storage.Default.SignedURL(ctx, key, exportLinkTTL)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Error creating download link"})
+			return
+		}
+
+		c.JSON(http.StatusOK, SuccessResponse{
+			Message: "Export ready for download",
+			Data:    gin.H{"url": url, "expires_at": time.Now().Add(exportLinkTTL)},
+		})
+		return
+	}
+
+	if format == "json" {
+		c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.json"`, filename))
+		c.JSON(http.StatusOK, export)
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.zip"`, filename))
+	c.Header("Content-Type", "application/zip")
+	c.Status(http.StatusOK)
+	if err := writeExportArchive(ctx, c.Writer, export, pictureKey); err != nil {
+		// Header sudah terkirim, jadi kesalahan hanya bisa dicatat
+		fmt.Printf("Error writing export archive for user %d: %v\n", user.ID, err)
+	}
+}
+
+// buildDataExport collects the user's data and returns the storage key of the profile picture, if any
+func buildDataExport(user *models.User) (*DataExport, string, error) {
+	export := &DataExport{ExportedAt: time.Now().UTC(), Profile: *user}
+	export.Profile.Password = ""
+
+	if user.PackageID != nil {
+		var pkg models.Package
+		if err := config.DB.First(&pkg, *user.PackageID).Error; err == nil {
+			export.Package = &pkg
+		}
+	}
+
+	if err := config.DB.Where("user_id = ?", user.ID).Find(&export.Organizations).Error; err != nil {
+		return nil, "", err
+	}
+	if err := config.DB.Where("user_id = ?", user.ID).Order("linked_at").Find(&export.OAuth.Identities).Error; err != nil {
+		return nil, "", err
+	}
+
+	var pictureKey string
+	switch {
+	case storage.IsExternalURL(user.ProfilePicture):
+		export.OAuth.ProfilePictureURL = user.ProfilePicture
+	case user.ProfilePicture != "":
+		pictureKey = storage.KeyFromLegacyPath(user.ProfilePicture)
+		export.ProfilePicture = &ProfilePictureExport{
+			Path:         pictureKey,
+			ArchiveEntry: "profile_picture/" + path.Base(pictureKey),
+		}
+	}
+
+	return export, pictureKey, nil
+}
+
+// writeExportArchive writes data.json and the profile picture into a ZIP archive
+func writeExportArchive(ctx context.Context, w io.Writer, export *DataExport, pictureKey string) error {
+	archive := zip.NewWriter(w)
+
+	data, err := archive.Create("data.json")
+	if err != nil {
+		return err
+	}
+	encoder := json.NewEncoder(data)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(export); err != nil {
+		return err
+	}
+
+	if pictureKey != "" {
+		file, err := storage.Default.Get(ctx, pictureKey)
+		if err != nil && err != storage.ErrNotFound {
+			return err
+		}
+		if err == nil {
+			defer file.Close()
+			entry, err := archive.Create(export.ProfilePicture.ArchiveEntry)
+			if err != nil {
+				return err
+			}
+			if _, err := io.Copy(entry, file); err != nil {
+				return err
+			}
+		}
+	}
+
+	return archive.Close()
+}
+
+// RequestAccountDeletion schedules the current user's account for deletion
+// @Summary Request account deletion
+// @Description Schedule the account for deletion after a grace period (ACCOUNT_DELETION_GRACE_DAYS, default 30 days). After the grace period the personal data is anonymized and the profile picture is removed. Accounts with a password must confirm it.
+// @Tags User
+// @Accept json
+// @Produce json
+// @Param confirmation body AccountDeletionRequest false "Password confirmation"
+// @Success 202 {object} SuccessResponse "Deletion scheduled"
+// @Failure 401 {object} ErrorResponse "Invalid password"
+// @Failure 409 {object} ErrorResponse "Deletion already scheduled"
+// @Failure 500 {object} ErrorResponse "Database error"
+// @Router /users/deletion [post]
+func RequestAccountDeletion(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	var input AccountDeletionRequest
+	// Body bersifat opsional untuk akun OAuth yang tidak memiliki password
+	_ = c.ShouldBindJSON(&input)
+
+	if user.Password != "" && !utils.CheckPasswordHash(input.Password, user.Password) {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{Error: "Invalid password"})
+		return
+	}
+	if user.DeletionScheduledAt != nil {
+		c.JSON(http.StatusConflict, ErrorResponse{Error: "Account deletion is already scheduled"})
+		return
+	}
+
+	now := time.Now()
+	scheduledAt := now.AddDate(0, 0, config.App.Account.DeletionGraceDays)
+	err := config.DB.Model(user).Updates(map[string]interface{}{
+		"deletion_requested_at": now,
+		"deletion_scheduled_at": scheduledAt,
+	}).Error
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Database error"})
+		return
+	}
+
+	if err := utils.SendAccountDeletionScheduled(user.Email, scheduledAt); err != nil {
+		fmt.Printf("Failed to send deletion notice to %s: %v\n", user.Email, err)
+	}
+
+	c.JSON(http.StatusAccepted, SuccessResponse{
+		Message: "Account deletion scheduled",
+		Data:    gin.H{"deletion_scheduled_at": scheduledAt},
+	})
+}
+
+// CancelAccountDeletion cancels a pending account deletion
+// @Summary Cancel account deletion
+// @Description Cancel a scheduled account deletion during the grace period
+// @Tags User
+// @Produce json
+// @Success 200 {object} SuccessResponse "Deletion cancelled"
+// @Failure 404 {object} ErrorResponse "No deletion scheduled"
+// @Failure 500 {object} ErrorResponse "Database error"
+// @Router /users/deletion [delete]
+func CancelAccountDeletion(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	if user.DeletionScheduledAt == nil {
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: "No account deletion is scheduled"})
+		return
+	}
+
+	err := config.DB.Model(user).Updates(map[string]interface{}{
+		"deletion_requested_at": nil,
+		"deletion_scheduled_at": nil,
+	}).Error
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Database error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{Message: "Account deletion cancelled"})
+}
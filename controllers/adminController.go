@@ -0,0 +1,575 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"github.com/mfuadfakhruzzaki/backend-api/audit"
+	"github.com/mfuadfakhruzzaki/backend-api/config"
+	"github.com/mfuadfakhruzzaki/backend-api/lockout"
+	"github.com/mfuadfakhruzzaki/backend-api/mfa"
+	"github.com/mfuadfakhruzzaki/backend-api/middleware"
+	"github.com/mfuadfakhruzzaki/backend-api/models"
+	"github.com/mfuadfakhruzzaki/backend-api/session"
+	"github.com/mfuadfakhruzzaki/backend-api/utils"
+)
+
+// loadUserParam loads the user named by the ":id" path parameter
+func loadUserParam(c *gin.Context) (*models.User, bool) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return nil, false
+	}
+
+	var user models.User
+	if err := config.DB.First(&user, id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, ErrorResponse{Error: "User not found"})
+		} else {
+			c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Database error"})
+		}
+		return nil, false
+	}
+	return &user, true
+}
+
+// AdminResetTwoFactor turns off two-factor authentication for a user who lost their device
+// @Summary Reset a user's two-factor authentication
+// @Description Admin only. Disables two-factor authentication and deletes the recovery codes of a user who lost access to their authenticator, after their identity was checked by support. The user is notified by email and can enrol again after logging in.
+// @Tags Admin
+// @Produce json
+// @Param id path int true "User ID"
+// @Success 200 {object} SuccessResponse "Two-factor authentication reset"
+// @Failure 400 {object} ErrorResponse "Two-factor authentication is not enabled"
+// @Failure 403 {object} ErrorResponse "Admin access required"
+// @Failure 404 {object} ErrorResponse "User not found"
+// @Failure 500 {object} ErrorResponse "Database error"
+// @Router /admin/users/{id}/2fa [delete]
+func AdminResetTwoFactor(c *gin.Context) {
+	user, ok := loadUserParam(c)
+	if !ok {
+		return
+	}
+	if !user.TOTPEnabled && user.TOTPSecret == "" {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Two-factor authentication is not enabled"})
+		return
+	}
+
+	if err := config.DB.Transaction(func(tx *gorm.DB) error { return mfa.Disable(tx, user.ID) }); err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Database error"})
+		return
+	}
+	recordAudit(c, audit.Entry{Action: audit.ActionAdminResetMFA, UserID: &user.ID, Before: gin.H{"totp_enabled": user.TOTPEnabled}, After: gin.H{"totp_enabled": false}})
+
+	if err := utils.SendTwoFactorResetNotice(user.Email); err != nil {
+		fmt.Printf("Failed to notify %s about two-factor reset: %v\n", user.Email, err)
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{Message: "Two-factor authentication reset"})
+}
+
+// AdminUnlockUser lifts a temporary password-login lockout
+// @Summary Unlock a user's password login
+// @Description Admin only. Clears the failed-login counter of a user whose password login was locked after too many failed attempts, so they can log in again right away. Per-IP limits are not affected.
+// @Tags Admin
+// @Produce json
+// @Param id path int true "User ID"
+// @Success 200 {object} SuccessResponse "Account unlocked"
+// @Failure 403 {object} ErrorResponse "Admin access required"
+// @Failure 404 {object} ErrorResponse "User not found"
+// @Failure 500 {object} ErrorResponse "Database error"
+// @Router /admin/users/{id}/lockout [delete]
+func AdminUnlockUser(c *gin.Context) {
+	user, ok := loadUserParam(c)
+	if !ok {
+		return
+	}
+
+	if err := lockout.Reset(lockout.AccountKey(user.Email)); err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Database error"})
+		return
+	}
+	recordAudit(c, audit.Entry{Action: audit.ActionAdminUnlock, UserID: &user.ID})
+
+	c.JSON(http.StatusOK, SuccessResponse{Message: "Account unlocked"})
+}
+
+const (
+	// adminUserSearchLimit and adminUserSearchMaxLimit bound one page of user search results
+	adminUserSearchLimit    = 50
+	adminUserSearchMaxLimit = 200
+	// signupIdentityWindow is how close to account creation an identity must have been linked
+	// for the account to count as created through that provider
+	signupIdentityWindow = time.Minute
+)
+
+// AdminUserSummary is a user as listed in the admin user search
+type AdminUserSummary struct {
+	ID             uint       `json:"id"`
+	CreatedAt      time.Time  `json:"created_at"`
+	Email          string     `json:"email"`
+	Username       string     `json:"username"`
+	PhoneNumber    string     `json:"phone_number"`
+	PhoneVerified  bool       `json:"phone_verified"`
+	EmailVerified  bool       `json:"email_verified"`
+	Role           string     `json:"role"`
+	Status         string     `json:"status"`
+	SuspendedUntil *time.Time `json:"suspended_until,omitempty"`
+	PackageID      *uint      `json:"package_id,omitempty"`
+}
+
+// AdminUserPage is one page of user search results, newest accounts first
+type AdminUserPage struct {
+	Users []AdminUserSummary `json:"users"`
+	// NextBefore is passed as "before" to fetch the next page; it is omitted on the last page
+	NextBefore uint `json:"next_before,omitempty"`
+}
+
+// AdminUserDetail is everything support needs to know about a user
+type AdminUserDetail struct {
+	AdminUserSummary
+	StatusReason string          `json:"status_reason,omitempty"`
+	Package      *models.Package `json:"package,omitempty"`
+	// RegisteredWith is "password", or the OAuth provider the account was created with
+	RegisteredWith      string                `json:"registered_with"`
+	HasPassword         bool                  `json:"has_password"`
+	TwoFactorEnabled    bool                  `json:"two_factor_enabled"`
+	Passkeys            int64                 `json:"passkeys"`
+	Identities          []models.UserIdentity `json:"identities"`
+	ActiveSessions      int64                 `json:"active_sessions"`
+	DeletionScheduledAt *time.Time            `json:"deletion_scheduled_at,omitempty"`
+}
+
+// AdminSetStatusRequest represents the body for suspending, banning or reactivating a user
+type AdminSetStatusRequest struct {
+	Status string `json:"status" binding:"required" example:"suspended" enums:"active,suspended,banned"`
+	// Reason is shown to other admins only
+	Reason string `json:"reason" binding:"max=500"`
+	// SuspendedUntil ends a suspension automatically; without it the suspension lasts until reactivated
+	SuspendedUntil *time.Time `json:"suspended_until"`
+}
+
+func adminUserSummary(user *models.User) AdminUserSummary {
+	return AdminUserSummary{
+		ID:             user.ID,
+		CreatedAt:      user.CreatedAt,
+		Email:          user.Email,
+		Username:       user.Username,
+		PhoneNumber:    user.PhoneNumber,
+		PhoneVerified:  user.PhoneVerified,
+		EmailVerified:  user.EmailVerified,
+		Role:           user.Role,
+		Status:         user.Status,
+		SuspendedUntil: user.SuspendedUntil,
+		PackageID:      user.PackageID,
+	}
+}
+
+// AdminSearchUsers finds users by email, username or phone number
+// @Summary Search users
+// @Description Admin only. Finds users whose email or username contains q (ignoring case), or whose phone number matches q in any common format. Without q all users are listed. Pass next_before from the response as "before" to get the next page.
+// @Tags Admin
+// @Produce json
+// @Param q query string false "Part of an email or username, or a phone number"
+// @Param status query string false "Only users with this status" Enums(active, suspended, banned)
+// @Param before query int false "Only users with a smaller ID, for paging"
+// @Param limit query int false "Users per page (default 50, at most 200)"
+// @Success 200 {object} AdminUserPage "Matching users"
+// @Failure 400 {object} ErrorResponse "Invalid filter"
+// @Failure 403 {object} ErrorResponse "Admin access required"
+// @Failure 500 {object} ErrorResponse "Database error"
+// @Router /admin/users [get]
+func AdminSearchUsers(c *gin.Context) {
+	query := config.DB.Model(&models.User{})
+
+	if q := strings.TrimSpace(c.Query("q")); q != "" {
+		pattern := "%" + utils.EscapeLike(strings.ToLower(q)) + "%"
+		condition := config.DB.Where(`LOWER(email) LIKE ? ESCAPE '\'`, pattern).
+			Or(`LOWER(username) LIKE ? ESCAPE '\'`, pattern)
+		if phone, err := utils.NormalizePhoneNumber(q); err == nil {
+			condition = condition.Or("phone_number = ?", phone)
+		}
+		query = query.Where(condition)
+	}
+	if status := c.Query("status"); status != "" {
+		if status != models.StatusActive && status != models.StatusSuspended && status != models.StatusBanned {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid status"})
+			return
+		}
+		query = query.Where("status = ?", status)
+	}
+	before, ok := optionalIDQuery(c, "before")
+	if !ok {
+		return
+	}
+	if before != nil {
+		query = query.Where("id < ?", *before)
+	}
+	limit := adminUserSearchLimit
+	if value := c.Query("limit"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 1 {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid limit"})
+			return
+		}
+		limit = min(n, adminUserSearchMaxLimit)
+	}
+
+	var users []models.User
+	if err := query.Order("id DESC").Limit(limit).Find(&users).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Database error"})
+		return
+	}
+
+	page := AdminUserPage{Users: make([]AdminUserSummary, 0, len(users))}
+	for i := range users {
+		page.Users = append(page.Users, adminUserSummary(&users[i]))
+	}
+	if len(users) == limit {
+		page.NextBefore = users[len(users)-1].ID
+	}
+	c.JSON(http.StatusOK, page)
+}
+
+// AdminGetUser shows the account details support needs to help a user
+// @Summary Get a user
+// @Description Admin only. Shows a user with their selected package, email and phone verification, how the account was created (password or OAuth provider), linked identities, two-factor and passkey state, active sessions and account status.
+// @Tags Admin
+// @Produce json
+// @Param id path int true "User ID"
+// @Success 200 {object} AdminUserDetail "User details"
+// @Failure 403 {object} ErrorResponse "Admin access required"
+// @Failure 404 {object} ErrorResponse "User not found"
+// @Failure 500 {object} ErrorResponse "Database error"
+// @Router /admin/users/{id} [get]
+func AdminGetUser(c *gin.Context) {
+	user, ok := loadUserParam(c)
+	if !ok {
+		return
+	}
+
+	detail := AdminUserDetail{
+		AdminUserSummary:    adminUserSummary(user),
+		StatusReason:        user.StatusReason,
+		RegisteredWith:      "password",
+		HasPassword:         user.Password != "",
+		TwoFactorEnabled:    user.TOTPEnabled,
+		DeletionScheduledAt: user.DeletionScheduledAt,
+	}
+
+	if user.PackageID != nil {
+		var pkg models.Package
+		if err := config.DB.First(&pkg, *user.PackageID).Error; err == nil {
+			detail.Package = &pkg
+		}
+	}
+	if err := config.DB.Where("user_id = ?", user.ID).Order("linked_at").Find(&detail.Identities).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Database error"})
+		return
+	}
+	// Accounts created through OAuth get their first identity at the same time
+	if len(detail.Identities) > 0 && detail.Identities[0].LinkedAt.Sub(user.CreatedAt) < signupIdentityWindow {
+		detail.RegisteredWith = detail.Identities[0].Provider
+	}
+	config.DB.Model(&models.WebAuthnCredential{}).Where("user_id = ?", user.ID).Count(&detail.Passkeys)
+	config.DB.Model(&models.Session{}).Where("user_id = ? AND revoked_at IS NULL AND expires_at > ?", user.ID, time.Now()).
+		Count(&detail.ActiveSessions)
+
+	c.JSON(http.StatusOK, detail)
+}
+
+// AdminVerifyEmail marks a user's email address as verified
+// @Summary Verify a user's email
+// @Description Admin only. Marks the email address of a user as verified, for users who cannot receive the verification email, after support confirmed they own the address.
+// @Tags Admin
+// @Produce json
+// @Param id path int true "User ID"
+// @Success 200 {object} SuccessResponse "Email verified"
+// @Failure 400 {object} ErrorResponse "Email is already verified"
+// @Failure 403 {object} ErrorResponse "Admin access required"
+// @Failure 404 {object} ErrorResponse "User not found"
+// @Failure 500 {object} ErrorResponse "Database error"
+// @Router /admin/users/{id}/verify-email [post]
+func AdminVerifyEmail(c *gin.Context) {
+	user, ok := loadUserParam(c)
+	if !ok {
+		return
+	}
+	if user.EmailVerified {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Email is already verified"})
+		return
+	}
+
+	if err := config.DB.Model(user).Updates(map[string]interface{}{"email_verified": true, "verification_code": ""}).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Database error"})
+		return
+	}
+	recordAudit(c, audit.Entry{
+		Action: audit.ActionAdminVerifyEmail,
+		UserID: &user.ID,
+		Before: gin.H{"email_verified": false},
+		After:  gin.H{"email_verified": true, "email": user.Email},
+	})
+
+	c.JSON(http.StatusOK, SuccessResponse{Message: "Email verified"})
+}
+
+// AdminVerifyPhone marks a user's phone number as verified
+// @Summary Verify a user's phone number
+// @Description Admin only. Marks the phone number of a user as verified after support confirmed they own it, so it can be used to log in. The number is stored in normalized international form (e.g. +62812...). A phone number can only be verified on one account at a time.
+// @Tags Admin
+// @Produce json
+// @Param id path int true "User ID"
+// @Success 200 {object} SuccessResponse "Phone number verified"
+// @Failure 400 {object} ErrorResponse "User has no valid phone number or it is already verified"
+// @Failure 403 {object} ErrorResponse "Admin access required"
+// @Failure 404 {object} ErrorResponse "User not found"
+// @Failure 409 {object} ErrorResponse "Phone number is verified on another account"
+// @Failure 500 {object} ErrorResponse "Database error"
+// @Router /admin/users/{id}/verify-phone [post]
+func AdminVerifyPhone(c *gin.Context) {
+	user, ok := loadUserParam(c)
+	if !ok {
+		return
+	}
+	if user.PhoneNumber == "" {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "User has no phone number"})
+		return
+	}
+	if user.PhoneVerified {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Phone number is already verified"})
+		return
+	}
+	// Numbers saved before normalization was introduced are stored as typed; login looks
+	// up the normalized form, so that is what gets verified
+	phone, err := utils.NormalizePhoneNumber(user.PhoneNumber)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "User's phone number is not a valid phone number"})
+		return
+	}
+
+	var taken int64
+	if err := config.DB.Model(&models.User{}).
+		Where("phone_number = ? AND phone_verified = ? AND id <> ?", phone, true, user.ID).
+		Count(&taken).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Database error"})
+		return
+	}
+	if taken > 0 {
+		c.JSON(http.StatusConflict, ErrorResponse{Error: "Phone number is verified on another account"})
+		return
+	}
+
+	if err := config.DB.Model(user).Updates(map[string]interface{}{
+		"phone_number":   phone,
+		"phone_verified": true,
+	}).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Database error"})
+		return
+	}
+	recordAudit(c, audit.Entry{
+		Action: audit.ActionAdminVerifyPhone,
+		UserID: &user.ID,
+		Before: gin.H{"phone_verified": false},
+		After:  gin.H{"phone_verified": true, "phone_number": phone},
+	})
+
+	c.JSON(http.StatusOK, SuccessResponse{Message: "Phone number verified"})
+}
+
+// AdminSetUserStatus suspends, bans or reactivates a user
+// @Summary Suspend, ban or reactivate a user
+// @Description Admin only. Suspended and banned users cannot log in, and requests with their existing tokens are rejected with 403. A suspension can end automatically at suspended_until. Suspending or banning also logs the user out of all sessions. Admins cannot change their own status.
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param id path int true "User ID"
+// @Param request body AdminSetStatusRequest true "New status"
+// @Success 200 {object} SuccessResponse{data=AdminUserSummary} "Status changed"
+// @Failure 400 {object} ErrorResponse "Invalid status or suspension end"
+// @Failure 403 {object} ErrorResponse "Admin access required, or changing your own status"
+// @Failure 404 {object} ErrorResponse "User not found"
+// @Failure 500 {object} ErrorResponse "Database error"
+// @Router /admin/users/{id}/status [put]
+func AdminSetUserStatus(c *gin.Context) {
+	user, ok := loadUserParam(c)
+	if !ok {
+		return
+	}
+
+	var input AdminSetStatusRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid request payload"})
+		return
+	}
+	switch input.Status {
+	case models.StatusActive, models.StatusBanned:
+		input.SuspendedUntil = nil
+	case models.StatusSuspended:
+		if input.SuspendedUntil != nil && !input.SuspendedUntil.After(time.Now()) {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: "suspended_until must be in the future"})
+			return
+		}
+	default:
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Status must be active, suspended or banned"})
+		return
+	}
+	if adminID, _ := c.Get(string(middleware.UserIDContextKey)); adminID == user.ID {
+		c.JSON(http.StatusForbidden, ErrorResponse{Error: "You cannot change the status of your own account"})
+		return
+	}
+
+	before := gin.H{"status": user.Status, "suspended_until": user.SuspendedUntil}
+	err := config.DB.Model(user).Updates(map[string]interface{}{
+		"status":          input.Status,
+		"status_reason":   strings.TrimSpace(input.Reason),
+		"suspended_until": input.SuspendedUntil,
+	}).Error
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Database error"})
+		return
+	}
+	user.Status, user.SuspendedUntil = input.Status, input.SuspendedUntil
+
+	// Tokens are rejected by JWTMiddleware anyway; revoking the sessions also keeps them
+	// from working again when the account is reactivated
+	var revoked int64
+	if input.Status != models.StatusActive {
+		revoked, _ = session.RevokeAll(user.ID, "")
+	}
+	recordAudit(c, audit.Entry{
+		Action:   audit.ActionAdminSetStatus,
+		UserID:   &user.ID,
+		Before:   before,
+		After:    gin.H{"status": user.Status, "suspended_until": user.SuspendedUntil},
+		Metadata: gin.H{"reason": strings.TrimSpace(input.Reason), "sessions_revoked": revoked},
+	})
+
+	c.JSON(http.StatusOK, SuccessResponse{Message: "Status changed", Data: adminUserSummary(user)})
+}
+
+// AdminLogoutUser logs a user out of all sessions
+// @Summary Log a user out everywhere
+// @Description Admin only. Revokes all sessions of a user, so every device has to log in again. Personal access tokens are not affected; suspend the account to stop them as well.
+// @Tags Admin
+// @Produce json
+// @Param id path int true "User ID"
+// @Success 200 {object} SuccessResponse "Sessions revoked, data.revoked holds the count"
+// @Failure 403 {object} ErrorResponse "Admin access required"
+// @Failure 404 {object} ErrorResponse "User not found"
+// @Failure 500 {object} ErrorResponse "Database error"
+// @Router /admin/users/{id}/logout [post]
+func AdminLogoutUser(c *gin.Context) {
+	user, ok := loadUserParam(c)
+	if !ok {
+		return
+	}
+
+	count, err := session.RevokeAll(user.ID, "")
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Database error"})
+		return
+	}
+	recordAudit(c, audit.Entry{Action: audit.ActionAdminLogout, UserID: &user.ID, Metadata: gin.H{"sessions_revoked": count}})
+
+	c.JSON(http.StatusOK, SuccessResponse{Message: "User logged out of all sessions", Data: gin.H{"revoked": count}})
+}
+
+// AdminSendPasswordReset emails a password reset link to a user
+// @Summary Send a password reset link
+// @Description Admin only. Emails the user the same single-use password reset link as /auth/password/forgot, replacing any earlier link. The admin never sees the link.
+// @Tags Admin
+// @Produce json
+// @Param id path int true "User ID"
+// @Success 200 {object} SuccessResponse "Reset link sent"
+// @Failure 403 {object} ErrorResponse "Admin access required"
+// @Failure 404 {object} ErrorResponse "User not found"
+// @Failure 500 {object} ErrorResponse "Error creating or sending the reset link"
+// @Router /admin/users/{id}/password-reset [post]
+func AdminSendPasswordReset(c *gin.Context) {
+	user, ok := loadUserParam(c)
+	if !ok {
+		return
+	}
+
+	if err := sendPasswordReset(user); err != nil {
+		respondPasswordResetError(c, err)
+		return
+	}
+	recordAudit(c, audit.Entry{Action: audit.ActionAdminResetLink, UserID: &user.ID})
+
+	c.JSON(http.StatusOK, SuccessResponse{Message: "Password reset link sent to " + user.Email})
+}
+
+// ImpersonateRequest represents the body for starting to impersonate a user
+type ImpersonateRequest struct {
+	// Reason is recorded in the audit log, e.g. the support ticket
+	Reason string `json:"reason" binding:"required,max=500" example:"Ticket #1234: package not shown"`
+}
+
+// ImpersonationResponse holds a token that acts as another user
+type ImpersonationResponse struct {
+	Token     string    `json:"token"`
+	UserID    uint      `json:"user_id"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// AdminImpersonateUser issues a short-lived read-only token that acts as a user
+// @Summary Impersonate a user
+// @Description Admin only. Returns a token that sees the API as the user does, for example to check their profile or package. The token expires after 15 minutes, only allows GET requests, cannot export the user's data, and stops working if the admin loses the admin role. The token carries the admin in its "act" claim, and the start and every request made with it are written to the audit log with the admin as actor. Admins cannot be impersonated.
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param id path int true "User ID"
+// @Param request body ImpersonateRequest true "Reason for impersonating"
+// @Success 200 {object} SuccessResponse{data=ImpersonationResponse} "Impersonation token"
+// @Failure 400 {object} ErrorResponse "Reason is required"
+// @Failure 403 {object} ErrorResponse "Admin access required, or the user is an admin"
+// @Failure 404 {object} ErrorResponse "User not found"
+// @Failure 409 {object} ErrorResponse "The account is suspended or banned"
+// @Failure 500 {object} ErrorResponse "Error generating token"
+// @Router /admin/users/{id}/impersonate [post]
+func AdminImpersonateUser(c *gin.Context) {
+	user, ok := loadUserParam(c)
+	if !ok {
+		return
+	}
+
+	var input ImpersonateRequest
+	if err := c.ShouldBindJSON(&input); err != nil || strings.TrimSpace(input.Reason) == "" {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "A reason is required"})
+		return
+	}
+	if user.Role == models.RoleAdmin {
+		c.JSON(http.StatusForbidden, ErrorResponse{Error: "Admins cannot be impersonated"})
+		return
+	}
+	if user.Blocked(time.Now()) {
+		c.JSON(http.StatusConflict, ErrorResponse{Error: "Suspended or banned accounts cannot be impersonated"})
+		return
+	}
+
+	value, _ := c.Get(string(middleware.UserIDContextKey))
+	adminID, _ := value.(uint)
+	token, expiresAt, err := utils.GenerateImpersonationJWT(user.ID, user.Email, adminID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Error generating token"})
+		return
+	}
+	recordAudit(c, audit.Entry{
+		Action:   audit.ActionImpersonation,
+		UserID:   &user.ID,
+		Metadata: gin.H{"reason": strings.TrimSpace(input.Reason), "expires_at": expiresAt},
+	})
+
+	c.JSON(http.StatusOK, SuccessResponse{
+		Message: "Impersonation token issued. It is read-only and expires in 15 minutes.",
+		Data:    ImpersonationResponse{Token: token, UserID: user.ID, ExpiresAt: expiresAt},
+	})
+}
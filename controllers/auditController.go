@@ -0,0 +1,167 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/mfuadfakhruzzaki/backend-api/audit"
+	"github.com/mfuadfakhruzzaki/backend-api/models"
+)
+
+// recentActivityLimit is the number of entries shown on the user's activity page
+const recentActivityLimit = 50
+
+// AuditLogPage is one page of audit log entries, newest first
+type AuditLogPage struct {
+	Entries []models.AuditLog `json:"entries"`
+	// NextBefore is passed as "before" to fetch the next page; it is omitted on the last page
+	NextBefore uint `json:"next_before,omitempty"`
+}
+
+// ActivityEntry is a security event as shown to the account owner
+type ActivityEntry struct {
+	ID        uint      `json:"id"`
+	Action    string    `json:"action"`
+	CreatedAt time.Time `json:"created_at"`
+	IPAddress string    `json:"ip_address"`
+	UserAgent string    `json:"user_agent"`
+	// Method is the login method for login events, such as password or google
+	Method string `json:"method,omitempty"`
+	// ByAdmin is set when an administrator acted on the account
+	ByAdmin bool `json:"by_admin,omitempty"`
+}
+
+// AdminGetAuditLogs searches the security audit log
+// @Summary Search the audit log
+// @Description Admin only. Lists security events (logins, failed logins, linked identities, password and email changes, package selections, admin actions, ...) newest first. Filters are combined. An action ending with a dot, such as "admin.", matches every action with that prefix. Pass next_before from the response as "before" to get the next page.
+// @Tags Admin
+// @Produce json
+// @Param user_id query int false "Affected user"
+// @Param actor_id query int false "User who performed the action"
+// @Param action query string false "Action, or action prefix ending with a dot"
+// @Param ip query string false "Client IP address"
+// @Param from query string false "Only entries at or after this time (RFC 3339)"
+// @Param to query string false "Only entries before this time (RFC 3339)"
+// @Param before query int false "Only entries with a smaller ID, for paging"
+// @Param limit query int false "Entries per page (default 50, at most 200)"
+// @Success 200 {object} AuditLogPage "Audit log entries"
+// @Failure 400 {object} ErrorResponse "Invalid filter"
+// @Failure 403 {object} ErrorResponse "Admin access required"
+// @Failure 500 {object} ErrorResponse "Database error"
+// @Router /admin/audit-logs [get]
+func AdminGetAuditLogs(c *gin.Context) {
+	filter := audit.Filter{Action: c.Query("action"), IP: c.Query("ip")}
+
+	var ok bool
+	if filter.UserID, ok = optionalIDQuery(c, "user_id"); !ok {
+		return
+	}
+	if filter.ActorID, ok = optionalIDQuery(c, "actor_id"); !ok {
+		return
+	}
+	before, ok := optionalIDQuery(c, "before")
+	if !ok {
+		return
+	}
+	if before != nil {
+		filter.BeforeID = *before
+	}
+	for name, target := range map[string]*time.Time{"from": &filter.From, "to": &filter.To} {
+		if value := c.Query(name); value != "" {
+			t, err := time.Parse(time.RFC3339, value)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid " + name + ", expected an RFC 3339 time"})
+				return
+			}
+			*target = t
+		}
+	}
+	if value := c.Query("limit"); value != "" {
+		limit, err := strconv.Atoi(value)
+		if err != nil || limit < 1 {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid limit"})
+			return
+		}
+		filter.Limit = limit
+	}
+
+	entries, err := audit.Query(filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Database error"})
+		return
+	}
+
+	page := AuditLogPage{Entries: entries}
+	limit := filter.Limit
+	if limit == 0 {
+		limit = audit.DefaultLimit
+	}
+	if len(entries) > 0 && len(entries) >= limit {
+		page.NextBefore = entries[len(entries)-1].ID
+	}
+	c.JSON(http.StatusOK, page)
+}
+
+// GetRecentActivity lists recent security events on the current user's account
+// @Summary Recent account activity
+// @Description List the latest security events on the current user's account, such as logins, failed login attempts, password and email changes and actions taken by an administrator (including support viewing the account as the user), so the user can spot activity that was not theirs.
+// @Tags User
+// @Produce json
+// @Success 200 {array} ActivityEntry "Recent activity, newest first"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 500 {object} ErrorResponse "Database error"
+// @Router /users/activity [get]
+func GetRecentActivity(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	// Impersonation shows up once when it starts, not for every request the admin made
+	entries, err := audit.Query(audit.Filter{
+		UserID:         &user.ID,
+		ExcludeActions: []string{audit.ActionImpersonationCall},
+		Limit:          recentActivityLimit,
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Database error"})
+		return
+	}
+
+	activity := make([]ActivityEntry, 0, len(entries))
+	for _, e := range entries {
+		var metadata struct {
+			Method string `json:"method"`
+		}
+		json.Unmarshal(e.Metadata, &metadata)
+		activity = append(activity, ActivityEntry{
+			ID:        e.ID,
+			Action:    e.Action,
+			CreatedAt: e.CreatedAt,
+			IPAddress: e.IPAddress,
+			UserAgent: e.UserAgent,
+			Method:    metadata.Method,
+			ByAdmin:   e.ActorID != nil && *e.ActorID != user.ID,
+		})
+	}
+	c.JSON(http.StatusOK, activity)
+}
+
+// optionalIDQuery reads an optional positive numeric query parameter
+func optionalIDQuery(c *gin.Context, name string) (*uint, bool) {
+	value := c.Query(name)
+	if value == "" {
+		return nil, true
+	}
+	id, err := strconv.ParseUint(value, 10, 64)
+	if err != nil || id == 0 {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid " + name})
+		return nil, false
+	}
+	result := uint(id)
+	return &result, true
+}
@@ -1,13 +1,19 @@
 package controllers
 
 import (
+	"fmt"
+	"math"
 	"net/http"
+	"strconv"
 	"strings"
+	"time"
 
+	"github.com/mfuadfakhruzzaki/backend-api/audit"
 	"github.com/mfuadfakhruzzaki/backend-api/config"
+	"github.com/mfuadfakhruzzaki/backend-api/jobs"
+	"github.com/mfuadfakhruzzaki/backend-api/lockout"
 	"github.com/mfuadfakhruzzaki/backend-api/models"
 	"github.com/mfuadfakhruzzaki/backend-api/utils"
-	"gorm.io/gorm"
 
 	"github.com/gin-gonic/gin"
 )
@@ -28,7 +34,10 @@ type VerificationRequest struct {
 
 // LoginCredentials represents the structure of the login request body
 type LoginCredentials struct {
-	Email    string `json:"email" binding:"required,email"`
+	// Identifier is an email address, username or verified phone number
+	Identifier string `json:"identifier" example:"user@example.com"`
+	// Email is accepted instead of identifier for older clients
+	Email    string `json:"email,omitempty"`
 	Password string `json:"password" binding:"required"`
 }
 
@@ -45,13 +54,13 @@ type ErrorResponse struct {
 
 // Register handles user registration
 // @Summary Register a new user
-// @Description This endpoint allows users to register by providing email, username, password, and phone number. A verification email will be sent after registration.
+// @Description This endpoint allows users to register by providing email, username, password, and phone number. A verification email will be sent after registration. Emails are stored in lower case. Usernames are 3-32 letters, digits, dots, dashes and underscores with at least one letter, and are unique regardless of case. Phone numbers are stored in E.164 format (local numbers starting with 0 are taken as Indonesian) and cannot be used to log in until an admin has verified them.
 // @Tags Auth
 // @Accept  json
 // @Produce  json
 // @Param   user  body  RegisterRequest  true  "User registration data"
 // @Success 201 {object} SuccessResponse "Registration successful"
-// @Failure 400 {object} ErrorResponse "Invalid request payload or password is empty"
+// @Failure 400 {object} PasswordPolicyError "Invalid request payload, or the password does not meet the password policy"
 // @Failure 409 {object} ErrorResponse "Email or username already exists"
 // @Failure 500 {object} ErrorResponse "Error creating user or sending verification email"
 // @Router  /auth/register [post]
@@ -67,9 +76,43 @@ func Register(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Password cannot be empty"})
 		return
 	}
+	userInput.Email = utils.NormalizeEmail(userInput.Email)
+	userInput.Username = utils.NormalizeUsername(userInput.Username)
+	if err := utils.ValidateUsername(userInput.Username); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid username: " + err.Error()})
+		return
+	}
+	if userInput.PhoneNumber != "" {
+		phone, err := utils.NormalizePhoneNumber(userInput.PhoneNumber)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid phone number"})
+			return
+		}
+		userInput.PhoneNumber = phone
+	}
+	if !checkPasswordPolicy(c, userInput.Password, userInput.Email, userInput.Username) {
+		return
+	}
+
+	// The unique indexes ignore case too, this gives a clear error before hashing the password
+	var taken int64
+	if err := config.DB.Model(&models.User{}).
+		Where("LOWER(email) = ? OR LOWER(username) = ?", userInput.Email, strings.ToLower(userInput.Username)).
+		Count(&taken).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Database error"})
+		return
+	}
+	if taken > 0 {
+		c.JSON(http.StatusConflict, ErrorResponse{Error: "Email or username already exists"})
+		return
+	}
 
 	// Hash password
 	hashedPassword, err := utils.HashPassword(userInput.Password)
+	if err == utils.ErrPasswordTooLong {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Password is too long"})
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Error hashing password"})
 		return
@@ -140,7 +183,7 @@ func VerifyEmail(c *gin.Context) {
 
 	var user models.User
 	// Find user by email
-	if err := config.DB.Where("email = ?", input.Email).First(&user).Error; err != nil {
+	if err := config.DB.Where("LOWER(email) = ?", utils.NormalizeEmail(input.Email)).First(&user).Error; err != nil {
 		c.JSON(http.StatusNotFound, ErrorResponse{Error: "User not found"})
 		return
 	}
@@ -166,15 +209,16 @@ func VerifyEmail(c *gin.Context) {
 
 // Login handles user authentication
 // @Summary User login
-// @Description This endpoint allows users to log in by providing email and password. A JWT token will be returned upon successful login.
+// @Description This endpoint allows users to log in with an identifier and password. The identifier may be an email address or username (both matched regardless of case) or a phone number verified by an admin; the older "email" field is still accepted. A JWT token will be returned upon successful login, or an MFA challenge token (mfa_required) if the account has two-factor authentication enabled. Unknown emails and wrong passwords get the same response. Repeated failures per account and per IP address, including wrong two-factor codes, slow down further attempts and then lock login temporarily; the account owner is notified by email when their account is locked.
 // @Tags Auth
 // @Accept  json
 // @Produce  json
-// @Param   credentials  body  LoginCredentials  true  "User credentials (email and password)"
-// @Success 200 {object} SuccessResponse "JWT token"
+// @Param   credentials  body  LoginCredentials  true  "User credentials (identifier and password)"
+// @Success 200 {object} SuccessResponse{data=LoginResult} "JWT token or MFA challenge"
 // @Failure 400 {object} ErrorResponse "Invalid request payload"
-// @Failure 401 {object} ErrorResponse "Unauthorized, invalid credentials or email not verified"
-// @Failure 404 {object} ErrorResponse "User not found"
+// @Failure 401 {object} ErrorResponse "Invalid credentials, or email not verified"
+// @Failure 403 {object} ErrorResponse "Account suspended or banned"
+// @Failure 429 {object} ErrorResponse "Too many failed attempts, see the Retry-After header"
 // @Failure 500 {object} ErrorResponse "Error generating token or database error"
 // @Router  /auth/login [post]
 func Login(c *gin.Context) {
@@ -185,36 +229,108 @@ func Login(c *gin.Context) {
 		return
 	}
 
-	var user models.User
-	result := config.DB.Where("email = ?", credentials.Email).First(&user)
-	if result.Error != nil {
-		if result.Error == gorm.ErrRecordNotFound {
-			c.JSON(http.StatusNotFound, ErrorResponse{Error: "User not found"})
-		} else {
-			c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Database error"})
-		}
+	identifier := strings.TrimSpace(credentials.Identifier)
+	if identifier == "" {
+		identifier = strings.TrimSpace(credentials.Email)
+	}
+	if identifier == "" {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid request payload"})
 		return
 	}
 
-	// Check if email is verified
-	if !user.EmailVerified {
-		c.JSON(http.StatusUnauthorized, ErrorResponse{Error: "Email not verified. Please verify your email first."})
+	user, err := findUserByIdentifier(identifier)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Database error"})
+		return
+	}
+
+	// Failures count against the account whichever identifier was used
+	_, normalized := utils.ClassifyIdentifier(identifier)
+	accountKey := lockout.AccountKey(normalized)
+	if user != nil {
+		accountKey = lockout.AccountKey(user.Email)
+	}
+	ipKey := lockout.IPKey(c.ClientIP())
+	wait, err := lockout.Wait(map[string]lockout.Policy{accountKey: lockout.AccountPolicy, ipKey: lockout.IPPolicy})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Database error"})
+		return
+	}
+	if wait > 0 {
+		c.Header("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+		c.JSON(http.StatusTooManyRequests, ErrorResponse{Error: "Too many failed login attempts. Please try again later."})
 		return
 	}
 
+	if user == nil {
+		utils.DummyPasswordCheck(credentials.Password)
+		failLogin(c, nil, normalized, accountKey, ipKey)
+		return
+	}
 	if !utils.CheckPasswordHash(credentials.Password, user.Password) {
-		c.JSON(http.StatusUnauthorized, ErrorResponse{Error: "Invalid password"})
+		failLogin(c, user, normalized, accountKey, ipKey)
 		return
 	}
+	// The account counter is reset by issueAccessToken once every factor has been checked,
+	// so failed two-factor codes after a correct password keep counting
+	upgradePasswordHash(user, credentials.Password)
 
-	tokenString, err := utils.GenerateJWT(user.Email)
+	// Check if email is verified. This is only revealed to someone who knows the password.
+	if !user.EmailVerified {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{Error: "Email not verified. Please verify your email first."})
+		return
+	}
+
+	loginResult, err := completeLogin(c, user, "password")
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Error generating token"})
+		respondLoginError(c, user, err)
 		return
 	}
 
+	message := "Login successful"
+	if loginResult.MFARequired {
+		message = "Two-factor authentication required"
+	}
 	c.JSON(http.StatusOK, SuccessResponse{
-		Message: "Login successful",
-		Data:    gin.H{"token": tokenString},
+		Message: message,
+		Data:    loginResult,
 	})
 }
+
+// failLogin counts a failed password login against the account and the client IP and
+// answers with the same error whether or not the account exists
+func failLogin(c *gin.Context, user *models.User, identifier, accountKey, ipKey string) {
+	recordLoginFailure(c, user, accountKey, ipKey, gin.H{"method": "password", "identifier": identifier})
+	c.JSON(http.StatusUnauthorized, ErrorResponse{Error: "Invalid credentials"})
+}
+
+// recordLoginFailure counts a failed login step against the account and the client IP, writes
+// it to the audit log and notifies the owner when this failure locked the account
+func recordLoginFailure(c *gin.Context, user *models.User, accountKey, ipKey string, metadata gin.H) {
+	locked, err := lockout.RecordFailure(accountKey, lockout.AccountPolicy)
+	if err != nil {
+		fmt.Printf("Failed to record login failure for %s: %v\n", accountKey, err)
+	}
+	if _, err := lockout.RecordFailure(ipKey, lockout.IPPolicy); err != nil {
+		fmt.Printf("Failed to record login failure for %s: %v\n", ipKey, err)
+	}
+
+	entry := audit.Entry{Action: audit.ActionLoginFailed, Metadata: metadata}
+	if user != nil {
+		entry.UserID = &user.ID
+	}
+	recordAudit(c, entry)
+
+	if locked && user != nil {
+		lockedUntil := time.Now().Add(lockout.AccountPolicy.LockDuration)
+		recordAudit(c, audit.Entry{Action: audit.ActionAccountLocked, UserID: &user.ID, Metadata: gin.H{"locked_until": lockedUntil}})
+
+		// Sent in the background so the response time does not reveal that the account exists
+		email := user.Email
+		jobs.Go(func() {
+			if err := utils.SendAccountLockedNotice(email, lockedUntil); err != nil {
+				fmt.Printf("Failed to send lockout notice to %s: %v\n", email, err)
+			}
+		})
+	}
+}
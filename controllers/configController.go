@@ -0,0 +1,21 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/mfuadfakhruzzaki/backend-api/config"
+)
+
+// AdminGetConfig shows the configuration the server is running with
+// @Summary Show the running configuration
+// @Description Admin only. Returns the configuration loaded at startup from defaults, the YAML config file, .env and environment variables, to check what a deployment actually uses. Passwords, client secrets, signing keys and the Redis URL are replaced by "[REDACTED]"; secrets that are not set stay empty. Durations are in nanoseconds.
+// @Tags Admin
+// @Produce json
+// @Success 200 {object} config.Config "Running configuration with secrets redacted"
+// @Failure 403 {object} ErrorResponse "Admin access required"
+// @Router /admin/config [get]
+func AdminGetConfig(c *gin.Context) {
+	c.JSON(http.StatusOK, config.App.Redacted())
+}
@@ -0,0 +1,305 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"github.com/mfuadfakhruzzaki/backend-api/audit"
+	"github.com/mfuadfakhruzzaki/backend-api/config"
+	"github.com/mfuadfakhruzzaki/backend-api/lockout"
+	"github.com/mfuadfakhruzzaki/backend-api/mfa"
+	"github.com/mfuadfakhruzzaki/backend-api/middleware"
+	"github.com/mfuadfakhruzzaki/backend-api/models"
+	"github.com/mfuadfakhruzzaki/backend-api/passwordpolicy"
+	"github.com/mfuadfakhruzzaki/backend-api/session"
+	"github.com/mfuadfakhruzzaki/backend-api/storage"
+	"github.com/mfuadfakhruzzaki/backend-api/utils"
+)
+
+// currentUser loads the authenticated user from the database using the identity
+// placed in the Gin context by JWTMiddleware. It writes the error response itself
+// and returns false when the request cannot continue.
+func currentUser(c *gin.Context) (*models.User, bool) {
+	value, exists := c.Get(string(middleware.UserIDContextKey))
+	if !exists {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{Error: "Unauthorized: User ID not found in context"})
+		return nil, false
+	}
+
+	userID, ok := value.(uint)
+	if !ok || userID == 0 {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{Error: "Unauthorized: Invalid user ID in context"})
+		return nil, false
+	}
+
+	var user models.User
+	if err := config.DB.First(&user, userID).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, ErrorResponse{Error: "User not found"})
+		} else {
+			c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Database error"})
+		}
+		return nil, false
+	}
+
+	return &user, true
+}
+
+// findUserByIdentifier looks up a user by email, username or verified phone number, ignoring
+// case for email and username. It returns nil without an error when no user matches.
+func findUserByIdentifier(identifier string) (*models.User, error) {
+	kind, normalized := utils.ClassifyIdentifier(identifier)
+
+	var users []models.User
+	var err error
+	switch kind {
+	case utils.IdentifierEmail:
+		err = config.DB.Where("LOWER(email) = ?", normalized).Limit(1).Find(&users).Error
+	case utils.IdentifierPhone:
+		err = config.DB.Where("phone_number = ? AND phone_verified = ?", normalized, true).Limit(1).Find(&users).Error
+		if err == nil && len(users) == 0 {
+			// Older accounts may have a username made of digits
+			err = config.DB.Where("LOWER(username) = ?", strings.ToLower(strings.TrimSpace(identifier))).Limit(1).Find(&users).Error
+		}
+	default:
+		err = config.DB.Where("LOWER(username) = ?", strings.ToLower(normalized)).Limit(1).Find(&users).Error
+	}
+	if err != nil || len(users) == 0 {
+		return nil, err
+	}
+	return &users[0], nil
+}
+
+// currentSessionID returns the session of the JWT used for this request, or "" for
+// personal access tokens and tokens issued before sessions were recorded
+func currentSessionID(c *gin.Context) string {
+	if claims, ok := c.Get(string(middleware.ClaimsContextKey)); ok {
+		if claims, ok := claims.(*utils.Claims); ok {
+			return claims.SessionID
+		}
+	}
+	return ""
+}
+
+// recordAudit appends entry to the audit log with the IP address and user agent of the
+// request. The actor defaults to the admin behind an impersonation token, or else the
+// authenticated user, if any.
+func recordAudit(c *gin.Context, entry audit.Entry) {
+	if entry.ActorID == nil {
+		if value, ok := c.Get(string(middleware.ImpersonatorContextKey)); ok {
+			if adminID, ok := value.(uint); ok {
+				entry.ActorID = &adminID
+			}
+		}
+	}
+	if entry.ActorID == nil {
+		if value, ok := c.Get(string(middleware.UserIDContextKey)); ok {
+			if userID, ok := value.(uint); ok && userID != 0 {
+				entry.ActorID = &userID
+			}
+		}
+	}
+	entry.IP = c.ClientIP()
+	entry.UserAgent = c.Request.UserAgent()
+	audit.Record(entry)
+}
+
+// parseIDParam reads a positive numeric path parameter such as ":id".
+func parseIDParam(c *gin.Context, name string) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param(name), 10, 64)
+	if err != nil || id == 0 {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid " + name})
+		return 0, false
+	}
+	return uint(id), true
+}
+
+// presentUser replaces the stored profile picture keys with URLs from the storage backend
+func presentUser(ctx context.Context, user *models.User) {
+	user.ProfilePicture = storage.ResolveURL(ctx, user.ProfilePicture)
+
+	if len(user.ProfilePictureVariants) == 0 {
+		return
+	}
+	var variants map[string]string
+	if err := json.Unmarshal(user.ProfilePictureVariants, &variants); err != nil {
+		return
+	}
+	for size, key := range variants {
+		variants[size] = storage.ResolveURL(ctx, key)
+	}
+	if resolved, err := json.Marshal(variants); err == nil {
+		user.ProfilePictureVariants = resolved
+	}
+}
+
+// userProfilePictureKeys lists the stored profile picture files of a user
+func userProfilePictureKeys(ctx context.Context, userID uint) ([]string, error) {
+	return storage.ProfilePictureKeys(ctx, storage.Default, userID)
+}
+
+// passwordlessLoginMethods counts the linked OAuth identities and passkeys of a user,
+// so that a user without a password cannot remove their last way to log in
+func passwordlessLoginMethods(userID uint) int64 {
+	var identities, passkeys int64
+	config.DB.Model(&models.UserIdentity{}).Where("user_id = ?", userID).Count(&identities)
+	config.DB.Model(&models.WebAuthnCredential{}).Where("user_id = ?", userID).Count(&passkeys)
+	return identities + passkeys
+}
+
+// upgradePasswordHash replaces a bcrypt hash, or an Argon2id hash with outdated parameters,
+// after the password was checked successfully. Failures are only logged, the old hash keeps working.
+func upgradePasswordHash(user *models.User, password string) {
+	if !utils.PasswordNeedsRehash(user.Password) {
+		return
+	}
+	hashed, err := utils.HashPassword(password)
+	if err != nil {
+		fmt.Printf("Failed to rehash password for user %d: %v\n", user.ID, err)
+		return
+	}
+	// Only replace the hash that was checked, in case the password was changed meanwhile
+	result := config.DB.Model(&models.User{}).Where("id = ? AND password = ?", user.ID, user.Password).Update("password", hashed)
+	if result.Error != nil {
+		fmt.Printf("Failed to store rehashed password for user %d: %v\n", user.ID, result.Error)
+		return
+	}
+	if result.RowsAffected == 1 {
+		user.Password = hashed
+	}
+}
+
+// LoginResult is returned after a successful first factor. Either Token is set, or the
+// account has two-factor authentication and MFAToken must be exchanged at /auth/2fa/verify.
+type LoginResult struct {
+	Token        string     `json:"token,omitempty"`
+	MFARequired  bool       `json:"mfa_required,omitempty"`
+	MFAToken     string     `json:"mfa_token,omitempty"`
+	MFAExpiresAt *time.Time `json:"mfa_expires_at,omitempty"`
+}
+
+// fragment encodes the result for redirects back to the frontend
+func (r *LoginResult) fragment() url.Values {
+	if r.MFARequired {
+		return url.Values{"mfa_required": {"true"}, "mfa_token": {r.MFAToken}}
+	}
+	return url.Values{"token": {r.Token}}
+}
+
+// errAccountBlocked is returned by completeLogin and issueAccessToken for accounts that an
+// admin suspended or banned
+var errAccountBlocked = errors.New("account is suspended or banned")
+
+// completeLogin is called once a user has passed the first factor (password, OAuth, ...).
+// Users with two-factor authentication get an MFA challenge instead of an access token.
+func completeLogin(c *gin.Context, user *models.User, method string) (*LoginResult, error) {
+	if user.Blocked(time.Now()) {
+		return nil, errAccountBlocked
+	}
+	if user.TOTPEnabled {
+		token, expiresAt, err := mfa.NewChallenge(user.ID, method)
+		if err != nil {
+			return nil, err
+		}
+		return &LoginResult{MFARequired: true, MFAToken: token, MFAExpiresAt: &expiresAt}, nil
+	}
+	return issueAccessToken(c, user, method)
+}
+
+// issueAccessToken records a session for the device the request came from and issues its
+// access token, after all required factors have been checked
+func issueAccessToken(c *gin.Context, user *models.User, method string) (*LoginResult, error) {
+	if user.Blocked(time.Now()) {
+		return nil, errAccountBlocked
+	}
+	s, err := session.Create(user.ID, method, session.Device{
+		Name:      c.GetHeader("X-Device-Name"),
+		UserAgent: c.Request.UserAgent(),
+		IP:        c.ClientIP(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := utils.GenerateJWT(user.ID, user.Email, s.PublicID)
+	if err != nil {
+		return nil, err
+	}
+	// Failed attempts before this login no longer count towards a lockout
+	if err := lockout.Reset(lockout.AccountKey(user.Email)); err != nil {
+		fmt.Printf("Failed to reset login failures for %s: %v\n", user.Email, err)
+	}
+	recordAudit(c, audit.Entry{
+		Action:   audit.ActionLogin,
+		ActorID:  &user.ID,
+		UserID:   &user.ID,
+		Metadata: gin.H{"method": method, "session_id": s.ID, "device": s.DeviceName},
+	})
+	return &LoginResult{Token: token}, nil
+}
+
+// respondLoginError answers a failed completeLogin or issueAccessToken call
+func respondLoginError(c *gin.Context, user *models.User, err error) {
+	if err == errAccountBlocked {
+		c.JSON(http.StatusForbidden, ErrorResponse{Error: user.BlockedMessage()})
+		return
+	}
+	c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Error generating token"})
+}
+
+// frontendLink builds a link to the frontend page at base, with the token in the "token"
+// query parameter. It returns "" when base is not configured, in which case emails only
+// contain the token or code.
+func frontendLink(base, token string) string {
+	if base == "" {
+		return ""
+	}
+	u, err := url.Parse(base)
+	if err != nil {
+		return ""
+	}
+	query := u.Query()
+	query.Set("token", token)
+	u.RawQuery = query.Encode()
+	return u.String()
+}
+
+// PasswordPolicyViolation explains one rule a new password breaks
+type PasswordPolicyViolation struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// PasswordPolicyError is returned when a new password does not meet the password policy.
+// Messages follow the Accept-Language header (English or Indonesian).
+type PasswordPolicyError struct {
+	Error      string                    `json:"error"`
+	Violations []PasswordPolicyViolation `json:"violations"`
+}
+
+// checkPasswordPolicy responds with 400 and returns false if password breaks the password policy
+func checkPasswordPolicy(c *gin.Context, password, email, username string) bool {
+	violations := passwordpolicy.Check(password, email, username)
+	if len(violations) == 0 {
+		return true
+	}
+
+	lang := passwordpolicy.Language(c.GetHeader("Accept-Language"))
+	response := PasswordPolicyError{Error: passwordpolicy.Summary(lang)}
+	for _, v := range violations {
+		response.Violations = append(response.Violations, PasswordPolicyViolation{Code: v.Code, Message: v.Message(lang)})
+	}
+	c.Header("Content-Language", lang)
+	c.JSON(http.StatusBadRequest, response)
+	return false
+}
@@ -0,0 +1,127 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/mfuadfakhruzzaki/backend-api/audit"
+	"github.com/mfuadfakhruzzaki/backend-api/config"
+	"github.com/mfuadfakhruzzaki/backend-api/models"
+	"github.com/mfuadfakhruzzaki/backend-api/oauth"
+)
+
+// GetIdentities lists the OAuth identities linked to the current user
+// @Summary List linked identities
+// @Description List the OAuth provider accounts linked to the current user
+// @Tags Identities
+// @Produce json
+// @Success 200 {array} models.UserIdentity "Linked identities"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 500 {object} ErrorResponse "Database error"
+// @Router /users/identities [get]
+func GetIdentities(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	var identities []models.UserIdentity
+	if err := config.DB.Where("user_id = ?", user.ID).Order("linked_at").Find(&identities).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Database error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, identities)
+}
+
+// LinkIdentity starts linking an OAuth provider account to the current user
+// @Summary Start linking an identity
+// @Description Start the OAuth flow that links a provider account to the logged-in user. Returns the provider authorization URL and sets the state cookie, so the request must be sent with credentials and the browser must then be sent to the returned URL.
+// @Tags Identities
+// @Produce json
+// @Param provider path string true "Provider name, see /auth/providers"
+// @Param redirect_uri query string false "Where to send the browser after linking; must be in OAUTH_REDIRECT_ALLOWLIST"
+// @Success 200 {object} SuccessResponse "Authorization URL"
+// @Failure 400 {object} ErrorResponse "Redirect target not allowed"
+// @Failure 404 {object} ErrorResponse "Unknown provider"
+// @Failure 502 {object} ErrorResponse "Provider discovery failed"
+// @Router /users/identities/{provider}/link [post]
+func LinkIdentity(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	provider, ok := oauth.Get(c.Param("provider"))
+	if !ok {
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: "Unknown provider"})
+		return
+	}
+
+	state, err := oauth.NewState(provider.Name, c.Query("redirect_uri"), &user.ID)
+	if err == oauth.ErrRedirectNotAllowed {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Redirect target is not allowed"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Error starting OAuth flow"})
+		return
+	}
+
+	authURL, err := provider.AuthCodeURL(c.Request.Context(), state.State, state.CodeVerifier, state.Nonce)
+	if err != nil {
+		c.JSON(http.StatusBadGateway, ErrorResponse{Error: "OAuth provider is unavailable"})
+		return
+	}
+
+	setOAuthStateCookie(c, state.State)
+	c.JSON(http.StatusOK, SuccessResponse{
+		Message: "Continue linking at the provider",
+		Data:    gin.H{"authorization_url": authURL},
+	})
+}
+
+// UnlinkIdentity removes a linked OAuth identity from the current user
+// @Summary Unlink an identity
+// @Description Remove a linked provider account. The last identity of an account without a password cannot be removed, because the user would no longer be able to log in.
+// @Tags Identities
+// @Produce json
+// @Param id path int true "Identity ID"
+// @Success 200 {object} SuccessResponse "Identity unlinked"
+// @Failure 404 {object} ErrorResponse "Identity not found"
+// @Failure 409 {object} ErrorResponse "Cannot remove the only login method"
+// @Router /users/identities/{id} [delete]
+func UnlinkIdentity(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+	identityID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var identity models.UserIdentity
+	if err := config.DB.Where("id = ? AND user_id = ?", identityID, user.ID).First(&identity).Error; err != nil {
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: "Identity not found"})
+		return
+	}
+
+	if user.Password == "" && passwordlessLoginMethods(user.ID) <= 1 {
+		c.JSON(http.StatusConflict, ErrorResponse{Error: "Set a password or add a passkey before removing your only linked identity"})
+		return
+	}
+
+	if err := config.DB.Delete(&identity).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Error unlinking identity"})
+		return
+	}
+	recordAudit(c, audit.Entry{
+		Action: audit.ActionIdentityUnlinked,
+		UserID: &user.ID,
+		Before: gin.H{"provider": identity.Provider, "email": identity.Email},
+	})
+
+	c.JSON(http.StatusOK, SuccessResponse{Message: "Identity unlinked"})
+}
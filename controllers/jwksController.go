@@ -0,0 +1,21 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/mfuadfakhruzzaki/backend-api/utils"
+)
+
+// JWKS publishes the public keys used to verify access tokens
+// @Summary JSON Web Key Set
+// @Description Returns the public keys (RS256/EdDSA) that verify tokens issued by this service. The "kid" header of a token selects the key. Keys being rotated out stay listed until their tokens expire.
+// @Tags Auth
+// @Produce  json
+// @Success 200 {object} utils.JSONWebKeySet "Active verification keys"
+// @Router  /.well-known/jwks.json [get]
+func JWKS(c *gin.Context) {
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(http.StatusOK, utils.JWKS())
+}
@@ -0,0 +1,195 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/mfuadfakhruzzaki/backend-api/config"
+	"github.com/mfuadfakhruzzaki/backend-api/jobs"
+	"github.com/mfuadfakhruzzaki/backend-api/models"
+	"github.com/mfuadfakhruzzaki/backend-api/utils"
+)
+
+const (
+	// magicLinkTTL is how long a login link and its code stay valid
+	magicLinkTTL = 15 * time.Minute
+	// magicLinkResendInterval stops repeated requests from flooding a mailbox
+	magicLinkResendInterval = time.Minute
+	// magicLinkMaxAttempts is the number of wrong codes allowed before the link is discarded
+	magicLinkMaxAttempts = 5
+)
+
+// MagicLinkRequest represents the request body for requesting a login link
+type MagicLinkRequest struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+// MagicLinkVerifyRequest exchanges either the token from the link, or the email and code, for a login
+type MagicLinkVerifyRequest struct {
+	Token string `json:"token"`
+	Email string `json:"email"`
+	Code  string `json:"code"`
+}
+
+// RequestMagicLink emails a single-use login link and code
+// @Summary Request a login link
+// @Description Emails a single-use login link and a 6-digit code that log the user in without a password. The link points at MAGIC_LINK_URL with the token in the "token" query parameter; the frontend posts it to /auth/magic-link/verify. The response is the same whether or not an account exists for the email, and the email is sent in the background.
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param request body MagicLinkRequest true "Email address"
+// @Success 200 {object} SuccessResponse "Login link sent if the account exists"
+// @Failure 400 {object} ErrorResponse "Invalid request payload"
+// @Router /auth/magic-link [post]
+func RequestMagicLink(c *gin.Context) {
+	var input MagicLinkRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid request payload"})
+		return
+	}
+
+	response := SuccessResponse{Message: "If an account exists for this email, a login link has been sent."}
+
+	var user models.User
+	if err := config.DB.Where("LOWER(email) = ? AND deleted_at IS NULL", utils.NormalizeEmail(input.Email)).First(&user).Error; err == nil {
+		// Creating and sending the link happens in the background, so neither the status
+		// code nor the response time tells whether the account exists
+		jobs.Go(func() { sendMagicLink(&user) })
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// sendMagicLink creates a new login link for the user and emails it. Failures are only
+// logged, since the request has already been answered.
+func sendMagicLink(user *models.User) {
+	// A link sent moments ago is still on its way; do not send another one
+	var recent int64
+	config.DB.Model(&models.MagicLink{}).
+		Where("user_id = ? AND created_at > ?", user.ID, time.Now().Add(-magicLinkResendInterval)).
+		Count(&recent)
+	if recent > 0 {
+		return
+	}
+
+	token, err := utils.RandomToken(32)
+	if err != nil {
+		fmt.Printf("Failed to create login link for user %d: %v\n", user.ID, err)
+		return
+	}
+	code, err := utils.RandomDigits(6)
+	if err != nil {
+		fmt.Printf("Failed to create login link for user %d: %v\n", user.ID, err)
+		return
+	}
+
+	// Only the newest link of a user is valid
+	config.DB.Where("user_id = ? OR expires_at < ?", user.ID, time.Now()).Delete(&models.MagicLink{})
+
+	link := models.MagicLink{
+		UserID:    user.ID,
+		TokenHash: utils.HashToken(token),
+		CodeHash:  utils.HashToken(fmt.Sprintf("%d:%s", user.ID, code)),
+		ExpiresAt: time.Now().Add(magicLinkTTL),
+	}
+	if err := config.DB.Create(&link).Error; err != nil {
+		fmt.Printf("Failed to create login link for user %d: %v\n", user.ID, err)
+		return
+	}
+
+	if err := utils.SendMagicLink(user.Email, frontendLink(config.App.Account.MagicLinkURL, token), code, magicLinkTTL); err != nil {
+		fmt.Printf("Failed to send login link to %s: %v\n", user.Email, err)
+	}
+}
+
+// VerifyMagicLink exchanges a login link or code for a token
+// @Summary Log in with a link or code
+// @Description Exchanges the token from a login link, or the email together with the 6-digit code, for a JWT. Both can be used only once. The first use also marks the email as verified. Accounts with two-factor authentication get an MFA challenge instead of a token.
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param request body MagicLinkVerifyRequest true "Token, or email and code"
+// @Success 200 {object} SuccessResponse{data=LoginResult} "JWT token or MFA challenge"
+// @Failure 400 {object} ErrorResponse "Invalid request payload"
+// @Failure 401 {object} ErrorResponse "Invalid or expired link or code"
+// @Failure 403 {object} ErrorResponse "Account suspended or banned"
+// @Failure 500 {object} ErrorResponse "Error generating token"
+// @Router /auth/magic-link/verify [post]
+func VerifyMagicLink(c *gin.Context) {
+	var input MagicLinkVerifyRequest
+	if err := c.ShouldBindJSON(&input); err != nil || (input.Token == "" && (input.Email == "" || input.Code == "")) {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Provide either a token, or an email and code"})
+		return
+	}
+
+	invalid := ErrorResponse{Error: "Invalid or expired login link. Please request a new one."}
+
+	var userID uint
+	if input.Token != "" {
+		userID = consumeMagicLink("token_hash = ?", utils.HashToken(strings.TrimSpace(input.Token)))
+	} else {
+		var user models.User
+		if err := config.DB.Select("id").Where("LOWER(email) = ?", utils.NormalizeEmail(input.Email)).First(&user).Error; err == nil {
+			codeHash := utils.HashToken(fmt.Sprintf("%d:%s", user.ID, strings.TrimSpace(input.Code)))
+			userID = consumeMagicLink("user_id = ? AND code_hash = ?", user.ID, codeHash)
+			if userID == 0 {
+				// Each wrong code uses up an attempt; the link is discarded after too many
+				config.DB.Model(&models.MagicLink{}).Where("user_id = ?", user.ID).
+					Update("attempts", gorm.Expr("attempts + 1"))
+				config.DB.Where("user_id = ? AND attempts >= ?", user.ID, magicLinkMaxAttempts).Delete(&models.MagicLink{})
+			}
+		}
+	}
+	if userID == 0 {
+		c.JSON(http.StatusUnauthorized, invalid)
+		return
+	}
+
+	var user models.User
+	if err := config.DB.First(&user, userID).Error; err != nil {
+		c.JSON(http.StatusUnauthorized, invalid)
+		return
+	}
+
+	// Receiving the email proves ownership of the address
+	if !user.EmailVerified {
+		user.EmailVerified = true
+		user.VerificationCode = ""
+		if err := config.DB.Model(&user).Updates(map[string]interface{}{"email_verified": true, "verification_code": ""}).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to verify email"})
+			return
+		}
+	}
+
+	result, err := completeLogin(c, &user, "magic_link")
+	if err != nil {
+		respondLoginError(c, &user, err)
+		return
+	}
+
+	message := "Login successful"
+	if result.MFARequired {
+		message = "Two-factor authentication required"
+	}
+	c.JSON(http.StatusOK, SuccessResponse{Message: message, Data: result})
+}
+
+// consumeMagicLink deletes the matching unexpired link and returns its user ID, or 0.
+// Deleting with RETURNING makes the link single-use even under concurrent requests.
+func consumeMagicLink(query string, args ...interface{}) uint {
+	var links []models.MagicLink
+	err := config.DB.Clauses(clause.Returning{}).
+		Where(query, args...).
+		Where("expires_at > ? AND attempts < ?", time.Now(), magicLinkMaxAttempts).
+		Delete(&links).Error
+	if err != nil || len(links) != 1 {
+		return 0
+	}
+	return links[0].UserID
+}
@@ -0,0 +1,321 @@
+package controllers
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"github.com/mfuadfakhruzzaki/backend-api/audit"
+	"github.com/mfuadfakhruzzaki/backend-api/config"
+	"github.com/mfuadfakhruzzaki/backend-api/lockout"
+	"github.com/mfuadfakhruzzaki/backend-api/mfa"
+	"github.com/mfuadfakhruzzaki/backend-api/models"
+	"github.com/mfuadfakhruzzaki/backend-api/utils"
+)
+
+// TwoFactorSetupResponse contains what an authenticator app needs to add the account
+type TwoFactorSetupResponse struct {
+	Secret     string `json:"secret"`
+	OTPAuthURL string `json:"otpauth_url"`
+	QRCode     string `json:"qr_code"` // PNG data URI
+}
+
+// TwoFactorCodeRequest carries a TOTP code or a recovery code
+type TwoFactorCodeRequest struct {
+	Code string `json:"code" binding:"required"`
+}
+
+// DisableTwoFactorRequest represents the request body for turning two-factor authentication off
+type DisableTwoFactorRequest struct {
+	Password string `json:"password"`
+	Code     string `json:"code" binding:"required"`
+}
+
+// TwoFactorLoginRequest completes a login that returned mfa_required
+type TwoFactorLoginRequest struct {
+	MFAToken string `json:"mfa_token" binding:"required"`
+	Code     string `json:"code" binding:"required"`
+}
+
+// GetTwoFactorStatus shows whether two-factor authentication is enabled
+// @Summary Two-factor authentication status
+// @Description Shows whether TOTP two-factor authentication is enabled and how many unused recovery codes are left
+// @Tags Two-Factor
+// @Produce json
+// @Success 200 {object} map[string]interface{} "Status"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Router /users/2fa [get]
+func GetTwoFactorStatus(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	status := gin.H{"enabled": user.TOTPEnabled}
+	if user.TOTPEnabled {
+		status["recovery_codes_remaining"] = mfa.RemainingRecoveryCodes(user.ID)
+	}
+	c.JSON(http.StatusOK, status)
+}
+
+// SetupTwoFactor generates a new TOTP secret for the current user
+// @Summary Start two-factor enrolment
+// @Description Generates a TOTP secret and returns it with an otpauth:// provisioning URI and QR code. Two-factor authentication is only enabled after a first code is confirmed at /users/2fa/enable. Calling this again before confirming replaces the secret.
+// @Tags Two-Factor
+// @Produce json
+// @Success 200 {object} TwoFactorSetupResponse "Secret, provisioning URI and QR code"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 409 {object} ErrorResponse "Two-factor authentication is already enabled"
+// @Failure 500 {object} ErrorResponse "Error generating secret"
+// @Router /users/2fa/setup [post]
+func SetupTwoFactor(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+	if user.TOTPEnabled {
+		c.JSON(http.StatusConflict, ErrorResponse{Error: "Two-factor authentication is already enabled"})
+		return
+	}
+
+	key, err := mfa.NewKey(user.Email)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Error generating secret"})
+		return
+	}
+	qrCode, err := mfa.QRCodeDataURI(key)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Error generating QR code"})
+		return
+	}
+
+	if err := config.DB.Model(user).Updates(map[string]interface{}{"totp_secret": key.Secret(), "totp_last_step": 0}).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Database error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, TwoFactorSetupResponse{
+		Secret:     key.Secret(),
+		OTPAuthURL: key.URL(),
+		QRCode:     qrCode,
+	})
+}
+
+// EnableTwoFactor confirms the secret from SetupTwoFactor with a first code
+// @Summary Confirm two-factor enrolment
+// @Description Enables two-factor authentication after checking a code from the authenticator app, and returns one-time recovery codes. The recovery codes are only shown once.
+// @Tags Two-Factor
+// @Accept json
+// @Produce json
+// @Param code body TwoFactorCodeRequest true "Code from the authenticator app"
+// @Success 200 {object} map[string]interface{} "Two-factor authentication enabled, with recovery codes"
+// @Failure 400 {object} ErrorResponse "Invalid code or setup not started"
+// @Failure 409 {object} ErrorResponse "Two-factor authentication is already enabled"
+// @Failure 500 {object} ErrorResponse "Database error"
+// @Router /users/2fa/enable [post]
+func EnableTwoFactor(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	var input TwoFactorCodeRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid request payload"})
+		return
+	}
+	if user.TOTPEnabled {
+		c.JSON(http.StatusConflict, ErrorResponse{Error: "Two-factor authentication is already enabled"})
+		return
+	}
+	if user.TOTPSecret == "" {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Start two-factor setup first"})
+		return
+	}
+	if err := mfa.UseTOTP(user, input.Code); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid two-factor code"})
+		return
+	}
+
+	var codes []string
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		var err error
+		if codes, err = mfa.GenerateRecoveryCodes(tx, user.ID); err != nil {
+			return err
+		}
+		return tx.Model(user).Update("totp_enabled", true).Error
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Database error"})
+		return
+	}
+	recordAudit(c, audit.Entry{Action: audit.ActionTwoFactorEnabled, UserID: &user.ID})
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":        "Two-factor authentication enabled. Store the recovery codes somewhere safe.",
+		"recovery_codes": codes,
+	})
+}
+
+// DisableTwoFactor turns two-factor authentication off for the current user
+// @Summary Disable two-factor authentication
+// @Description Disables two-factor authentication. Requires a current TOTP or recovery code, and the password if the account has one.
+// @Tags Two-Factor
+// @Accept json
+// @Produce json
+// @Param request body DisableTwoFactorRequest true "Password and code"
+// @Success 200 {object} SuccessResponse "Two-factor authentication disabled"
+// @Failure 400 {object} ErrorResponse "Two-factor authentication is not enabled"
+// @Failure 401 {object} ErrorResponse "Invalid password or code"
+// @Failure 500 {object} ErrorResponse "Database error"
+// @Router /users/2fa/disable [post]
+func DisableTwoFactor(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	var input DisableTwoFactorRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid request payload"})
+		return
+	}
+	if !user.TOTPEnabled {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Two-factor authentication is not enabled"})
+		return
+	}
+	if user.Password != "" && !utils.CheckPasswordHash(input.Password, user.Password) {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{Error: "Password is incorrect"})
+		return
+	}
+	if _, err := mfa.Verify(user, input.Code); err != nil {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{Error: "Invalid two-factor code"})
+		return
+	}
+
+	if err := config.DB.Transaction(func(tx *gorm.DB) error { return mfa.Disable(tx, user.ID) }); err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Database error"})
+		return
+	}
+	recordAudit(c, audit.Entry{Action: audit.ActionTwoFactorDisabled, UserID: &user.ID})
+
+	c.JSON(http.StatusOK, SuccessResponse{Message: "Two-factor authentication disabled"})
+}
+
+// RegenerateRecoveryCodes replaces all recovery codes of the current user
+// @Summary Regenerate recovery codes
+// @Description Invalidates the existing recovery codes and returns a new set. Requires a current TOTP code.
+// @Tags Two-Factor
+// @Accept json
+// @Produce json
+// @Param code body TwoFactorCodeRequest true "Code from the authenticator app"
+// @Success 200 {object} map[string]interface{} "New recovery codes"
+// @Failure 400 {object} ErrorResponse "Two-factor authentication is not enabled"
+// @Failure 401 {object} ErrorResponse "Invalid code"
+// @Failure 500 {object} ErrorResponse "Database error"
+// @Router /users/2fa/recovery-codes [post]
+func RegenerateRecoveryCodes(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	var input TwoFactorCodeRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid request payload"})
+		return
+	}
+	if !user.TOTPEnabled {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Two-factor authentication is not enabled"})
+		return
+	}
+	if err := mfa.UseTOTP(user, input.Code); err != nil {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{Error: "Invalid two-factor code"})
+		return
+	}
+
+	codes, err := mfa.GenerateRecoveryCodes(config.DB, user.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Database error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"recovery_codes": codes})
+}
+
+// VerifyTwoFactorLogin exchanges an MFA challenge and a code for an access token
+// @Summary Complete two-factor login
+// @Description Completes a login that returned mfa_required by sending the mfa_token together with a TOTP code or a recovery code. Each challenge allows a limited number of attempts and expires after a few minutes. Wrong codes count towards the same account lockout as wrong passwords.
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param request body TwoFactorLoginRequest true "MFA challenge token and code"
+// @Success 200 {object} SuccessResponse{data=LoginResult} "JWT token"
+// @Failure 400 {object} ErrorResponse "Invalid request payload"
+// @Failure 401 {object} ErrorResponse "Invalid code or expired challenge"
+// @Failure 403 {object} ErrorResponse "Account suspended or banned"
+// @Failure 429 {object} ErrorResponse "Too many failed attempts, see the Retry-After header"
+// @Failure 500 {object} ErrorResponse "Error generating token"
+// @Router /auth/2fa/verify [post]
+func VerifyTwoFactorLogin(c *gin.Context) {
+	var input TwoFactorLoginRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid request payload"})
+		return
+	}
+
+	challenge, err := mfa.AttemptChallenge(strings.TrimSpace(input.MFAToken))
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{Error: "Invalid or expired two-factor challenge. Please log in again."})
+		return
+	}
+
+	var user models.User
+	if err := config.DB.First(&user, challenge.UserID).Error; err != nil || !user.TOTPEnabled {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{Error: "Invalid or expired two-factor challenge. Please log in again."})
+		return
+	}
+
+	// Wrong codes count against the same lockout as wrong passwords, so a new challenge per
+	// login does not give unlimited guesses at the code
+	accountKey, ipKey := lockout.AccountKey(user.Email), lockout.IPKey(c.ClientIP())
+	wait, err := lockout.Wait(map[string]lockout.Policy{accountKey: lockout.AccountPolicy, ipKey: lockout.IPPolicy})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Database error"})
+		return
+	}
+	if wait > 0 {
+		c.Header("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+		c.JSON(http.StatusTooManyRequests, ErrorResponse{Error: "Too many failed login attempts. Please try again later."})
+		return
+	}
+
+	if _, err := mfa.Verify(&user, input.Code); err != nil {
+		if err == mfa.ErrInvalidCode {
+			recordLoginFailure(c, &user, accountKey, ipKey, gin.H{"method": challenge.Method, "factor": "totp"})
+			c.JSON(http.StatusUnauthorized, ErrorResponse{Error: "Invalid two-factor code"})
+		} else {
+			c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Database error"})
+		}
+		return
+	}
+	if err := mfa.CompleteChallenge(challenge); err != nil {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{Error: "Invalid or expired two-factor challenge. Please log in again."})
+		return
+	}
+
+	result, err := issueAccessToken(c, &user, challenge.Method)
+	if err != nil {
+		respondLoginError(c, &user, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{
+		Message: "Login successful",
+		Data:    result,
+	})
+}
@@ -1,10 +1,14 @@
 package controllers
 
 import (
-	"encoding/json"
+	"fmt"
 	"net/http"
+	"net/url"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/mfuadfakhruzzaki/backend-api/audit"
 	"github.com/mfuadfakhruzzaki/backend-api/config"
 	"github.com/mfuadfakhruzzaki/backend-api/models"
 	"github.com/mfuadfakhruzzaki/backend-api/oauth"
@@ -12,183 +16,323 @@ import (
 	"gorm.io/gorm"
 )
 
-// GoogleUser represents the structure of the user data returned by Google
-type GoogleUser struct {
-	ID            string `json:"id"`
-	Email         string `json:"email"`
-	VerifiedEmail bool   `json:"verified_email"`
-	Name          string `json:"name"`
-	GivenName     string `json:"given_name"`
-	FamilyName    string `json:"family_name"`
-	Picture       string `json:"picture"`
-	Locale        string `json:"locale"`
-}
-
-// GithubUser represents the structure of the user data returned by GitHub
-type GithubUser struct {
-	ID        int    `json:"id"`
-	Login     string `json:"login"`
-	Name      string `json:"name"`
-	Email     string `json:"email"`
-	AvatarURL string `json:"avatar_url"`
+// OAuthProviders lists the configured OAuth/OIDC providers
+// @Summary List OAuth providers
+// @Description List the names of the configured OAuth2/OIDC providers that can be used with /auth/{provider}/login
+// @Tags OAuth
+// @Produce json
+// @Success 200 {object} map[string]interface{} "Provider names"
+// @Router /auth/providers [get]
+func OAuthProviders(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"providers": oauth.Names()})
 }
 
-// GoogleLogin initiates the OAuth flow with Google
-// @Summary Initiate Google OAuth
-// @Description Redirects the user to the Google OAuth login page
+// OAuthLogin initiates the OAuth flow with the given provider
+// @Summary Initiate OAuth login
+// @Description Redirects the user to the login page of a configured OAuth2/OIDC provider (for example google or github)
 // @Tags OAuth
-// @Success 302 "Redirects to Google OAuth login"
-// @Router /auth/google/login [get]
-func GoogleLogin(c *gin.Context) {
-	url := oauth.GoogleOauthConfig.AuthCodeURL(oauth.OauthStateString)
-	c.Redirect(http.StatusTemporaryRedirect, url)
+// @Param provider path string true "Provider name"
+// @Param redirect_uri query string false "Where to send the browser after login; must be in OAUTH_REDIRECT_ALLOWLIST"
+// @Success 302 "Redirects to the provider login page"
+// @Failure 400 {object} map[string]interface{} "Redirect target not allowed"
+// @Failure 404 {object} map[string]interface{} "Unknown provider"
+// @Failure 502 {object} map[string]interface{} "Provider discovery failed"
+// @Router /auth/{provider}/login [get]
+func OAuthLogin(c *gin.Context) {
+	provider, ok := oauth.Get(c.Param("provider"))
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Unknown OAuth provider"})
+		return
+	}
+
+	state, err := oauth.NewState(provider.Name, c.Query("redirect_uri"), nil)
+	if err == oauth.ErrRedirectNotAllowed {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Redirect target is not allowed"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error starting OAuth login"})
+		return
+	}
+
+	authURL, err := provider.AuthCodeURL(c.Request.Context(), state.State, state.CodeVerifier, state.Nonce)
+	if err != nil {
+		c.JSON(http.StatusBadGateway, gin.H{"error": "OAuth provider is unavailable"})
+		return
+	}
+
+	setOAuthStateCookie(c, state.State)
+	c.Redirect(http.StatusTemporaryRedirect, authURL)
 }
 
-// GoogleCallback handles the callback from Google after user authentication
-// @Summary Handle Google OAuth callback
-// @Description This endpoint handles the callback from Google after the user has authenticated. It logs in the user or creates a new user account if the user does not already exist.
+// OAuthCallback handles the callback from a provider after user authentication
+// @Summary Handle OAuth callback
+// @Description Handles the callback from the provider. For OIDC providers the ID token signature is validated against the provider JWKS. Logs in the user linked to the provider identity, or creates a new account when the verified email is not registered yet. Existing accounts must link the provider explicitly, except accounts created by the old Google and GitHub login, which are linked once by their verified email. When the flow was started from the link endpoint, the identity is linked to that user instead.
 // @Tags OAuth
+// @Param provider path string true "Provider name"
 // @Param state query string true "OAuth State"
 // @Param code query string true "OAuth Code"
 // @Success 200 {object} map[string]interface{} "JWT Token"
-// @Failure 302 {string} string "Redirects to home or error page"
+// @Success 302 {string} string "Redirects to the allow-listed redirect_uri with the token in the URL fragment"
 // @Failure 400 {object} map[string]interface{} "Invalid OAuth state or code"
+// @Failure 403 {object} map[string]interface{} "Provider email not verified, or account suspended or banned"
+// @Failure 404 {object} map[string]interface{} "Unknown provider"
+// @Failure 409 {object} map[string]interface{} "Email belongs to an existing account, or identity linked to another user"
 // @Failure 500 {object} map[string]interface{} "Internal server error"
-// @Router /auth/google/callback [get]
-func GoogleCallback(c *gin.Context) {
-	state := c.Query("state")
-	if state != oauth.OauthStateString {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid OAuth state"})
+// @Router /auth/{provider}/callback [get]
+func OAuthCallback(c *gin.Context) {
+	provider, ok := oauth.Get(c.Param("provider"))
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Unknown OAuth provider"})
 		return
 	}
 
-	code := c.Query("code")
-	content, err := oauth.GetUserDataFromGoogle(code)
-	if err != nil {
-		// Redirect to home or an error page
-		c.Redirect(http.StatusTemporaryRedirect, "/")
+	state, ok := consumeOAuthState(c, provider.Name)
+	if !ok {
 		return
 	}
 
-	// Parse JSON data from Google
-	var googleUser GoogleUser
-	err = json.Unmarshal(content, &googleUser)
+	profile, err := provider.Exchange(c.Request.Context(), c.Query("code"), state.CodeVerifier, state.Nonce)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error parsing user data"})
+		fmt.Printf("OAuth exchange with %s failed: %v\n", provider.Name, err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Error exchanging OAuth code"})
+		return
+	}
+
+	completeOAuthLogin(c, state, profile)
+}
+
+// completeOAuthLogin links the identity to the user who started a link flow, or logs in the
+// user the identity is already linked to, or creates a new account for a verified email that
+// does not belong to an existing account. An identity is only merged into an existing account
+// by email for accounts left over from the old Google and GitHub login (see
+// models.User.LegacyOAuth); otherwise the owner must link it from a logged-in session.
+func completeOAuthLogin(c *gin.Context, state *models.OAuthState, profile *oauth.Profile) {
+	if profile.Subject == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Provider did not return a user ID"})
+		return
+	}
+
+	var identity models.UserIdentity
+	err := config.DB.Where("provider = ? AND subject = ?", profile.Provider, profile.Subject).First(&identity).Error
+	if err != nil && err != gorm.ErrRecordNotFound {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+	linked := err == nil
+
+	// Linking from a logged-in session
+	if state.LinkUserID != nil {
+		if linked && identity.UserID != *state.LinkUserID {
+			c.JSON(http.StatusConflict, gin.H{"error": "This " + profile.Provider + " account is already linked to another user"})
+			return
+		}
+		if !linked {
+			identity = models.UserIdentity{
+				UserID:   *state.LinkUserID,
+				Provider: profile.Provider,
+				Subject:  profile.Subject,
+				Email:    profile.Email,
+				LinkedAt: time.Now(),
+			}
+			if err := config.DB.Create(&identity).Error; err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Error linking identity"})
+				return
+			}
+			recordAudit(c, audit.Entry{
+				Action:  audit.ActionIdentityLinked,
+				ActorID: state.LinkUserID,
+				UserID:  state.LinkUserID,
+				After:   gin.H{"provider": identity.Provider, "email": identity.Email},
+			})
+		}
+		respondWithOAuthResult(c, state, gin.H{"message": "Identity linked", "identity": identity}, url.Values{"linked": {profile.Provider}})
 		return
 	}
 
-	// Check if the user already exists in the database
 	var user models.User
-	result := config.DB.Where("email = ?", googleUser.Email).First(&user)
-	if result.Error == gorm.ErrRecordNotFound {
-		// If user doesn't exist, create a new user
+	if linked {
+		if err := config.DB.First(&user, identity.UserID).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+			return
+		}
+	} else {
+		if profile.Email == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Email not available from " + profile.Provider})
+			return
+		}
+		if !profile.EmailVerified {
+			c.JSON(http.StatusForbidden, gin.H{"error": "The email address of this " + profile.Provider + " account is not verified"})
+			return
+		}
+
+		var existing models.User
+		err := config.DB.Where("LOWER(email) = ?", utils.NormalizeEmail(profile.Email)).First(&existing).Error
+		if err != nil && err != gorm.ErrRecordNotFound {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+			return
+		}
+		if err == nil {
+			// Accounts created by the old Google and GitHub login before identities were
+			// recorded have no other login method. They are linked once by the verified
+			// provider email; every other account must link the identity explicitly.
+			if !existing.LegacyOAuth || (profile.Provider != "google" && profile.Provider != "github") {
+				c.JSON(http.StatusConflict, gin.H{"error": "An account with this email already exists. Log in and link your " + profile.Provider + " account from your profile."})
+				return
+			}
+
+			identity = models.UserIdentity{
+				UserID:   existing.ID,
+				Provider: profile.Provider,
+				Subject:  profile.Subject,
+				Email:    profile.Email,
+				LinkedAt: time.Now(),
+			}
+			err := config.DB.Transaction(func(tx *gorm.DB) error {
+				if err := tx.Create(&identity).Error; err != nil {
+					return err
+				}
+				return tx.Model(&existing).Update("legacy_oauth", false).Error
+			})
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Error linking identity"})
+				return
+			}
+			recordAudit(c, audit.Entry{
+				Action:  audit.ActionIdentityLinked,
+				ActorID: &existing.ID,
+				UserID:  &existing.ID,
+				After:   gin.H{"provider": identity.Provider, "email": identity.Email, "legacy": true},
+			})
+			user = existing
+		}
+	}
+
+	if user.ID == 0 {
+		// If user doesn't exist, create a new user together with the identity
 		user = models.User{
-			Email:          googleUser.Email,
-			Username:       googleUser.Name,
-			Password:       "", // Password can be empty or set a default value since OAuth is used
-			ProfilePicture: googleUser.Picture,
+			Email:          utils.NormalizeEmail(profile.Email),
+			Username:       uniqueUsername(profile.Name, profile.Email),
+			Password:       "", // OAuth accounts have no password until the user sets one
+			ProfilePicture: profile.Picture,
+			EmailVerified:  true,
 		}
-		if err := config.DB.Create(&user).Error; err != nil {
+		err := config.DB.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Create(&user).Error; err != nil {
+				return err
+			}
+			return tx.Create(&models.UserIdentity{
+				UserID:   user.ID,
+				Provider: profile.Provider,
+				Subject:  profile.Subject,
+				Email:    profile.Email,
+				LinkedAt: time.Now(),
+			}).Error
+		})
+		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error creating user"})
 			return
 		}
-	} else if result.Error != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
-		return
 	}
 
-	// Generate JWT token
-	tokenString, err := utils.GenerateJWT(user.Email)
+	// Generate JWT token, or an MFA challenge for accounts with two-factor authentication
+	result, err := completeLogin(c, &user, profile.Provider)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error generating token"})
+		respondLoginError(c, &user, err)
 		return
 	}
 
 	// Return the token to the user
-	c.JSON(http.StatusOK, gin.H{"token": tokenString})
+	respondWithOAuthResult(c, state, result, result.fragment())
 }
 
-// GithubLogin initiates the OAuth flow with GitHub
-// @Summary Initiate GitHub OAuth
-// @Description Redirects the user to the GitHub OAuth login page
-// @Tags OAuth
-// @Success 302 "Redirects to GitHub OAuth login"
-// @Router /auth/github/login [get]
-func GithubLogin(c *gin.Context) {
-	url := oauth.GithubOauthConfig.AuthCodeURL(oauth.OauthStateString)
-	c.Redirect(http.StatusTemporaryRedirect, url)
-}
-
-// GithubCallback handles the callback from GitHub after user authentication
-// @Summary Handle GitHub OAuth callback
-// @Description This endpoint handles the callback from GitHub after the user has authenticated. It logs in the user or creates a new user account if the user does not already exist.
-// @Tags OAuth
-// @Param state query string true "OAuth State"
-// @Param code query string true "OAuth Code"
-// @Success 200 {object} map[string]interface{} "JWT Token"
-// @Failure 302 {string} string "Redirects to home or error page"
-// @Failure 400 {object} map[string]interface{} "Invalid OAuth state or code"
-// @Failure 500 {object} map[string]interface{} "Internal server error"
-// @Router /auth/github/callback [get]
-func GithubCallback(c *gin.Context) {
-	state := c.Query("state")
-	if state != oauth.OauthStateString {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid OAuth state"})
-		return
+// uniqueUsername derives an unused, valid username from the provider name or email
+func uniqueUsername(name, email string) string {
+	base := usernameFrom(name)
+	if utils.ValidateUsername(base) != nil {
+		local, _, _ := strings.Cut(email, "@")
+		base = usernameFrom(local)
+	}
+	if utils.ValidateUsername(base) != nil {
+		base = "user"
 	}
 
-	code := c.Query("code")
-	content, err := oauth.GetUserDataFromGithub(code)
-	if err != nil {
-		// Redirect to home or an error page
-		c.Redirect(http.StatusTemporaryRedirect, "/")
-		return
+	candidate := base
+	for i := 0; i < 5; i++ {
+		var count int64
+		config.DB.Model(&models.User{}).Where("LOWER(username) = ?", strings.ToLower(candidate)).Count(&count)
+		if count == 0 {
+			return candidate
+		}
+		code, _ := utils.RandomDigits(4)
+		candidate = base + code
 	}
+	return candidate
+}
 
-	// Parse JSON data from GitHub
-	var githubUser GithubUser
-	err = json.Unmarshal(content, &githubUser)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error parsing user data"})
-		return
+// usernameFrom turns a display name into username characters, e.g. "Budi Santoso" into "Budi.Santoso"
+func usernameFrom(name string) string {
+	var b strings.Builder
+	for _, r := range strings.Join(strings.Fields(name), ".") {
+		if (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') || r == '.' || r == '-' || r == '_' {
+			b.WriteRune(r)
+		}
+	}
+	username := b.String()
+	// Leave room for the digits added when the name is taken
+	if len(username) > 28 {
+		username = username[:28]
 	}
+	return username
+}
 
-	// Ensure email is available
-	email := githubUser.Email
-	if email == "" {
-		// Attempt to fetch email via another API call if necessary
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Email not available from GitHub"})
-		return
+// setOAuthStateCookie binds the OAuth state to the browser with a short-lived cookie
+func setOAuthStateCookie(c *gin.Context, state string) {
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(oauth.StateCookieName, state, int(oauth.StateTTL.Seconds()), "/auth/", "", isSecureRequest(c), true)
+}
+
+// consumeOAuthState validates the callback state against the browser cookie and consumes it
+func consumeOAuthState(c *gin.Context, provider string) (*models.OAuthState, bool) {
+	cookieState, _ := c.Cookie(oauth.StateCookieName)
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(oauth.StateCookieName, "", -1, "/auth/", "", isSecureRequest(c), true)
+
+	if providerError := c.Query("error"); providerError != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "OAuth login failed: " + providerError})
+		return nil, false
 	}
 
-	// Check if the user already exists in the database
-	var user models.User
-	result := config.DB.Where("email = ?", email).First(&user)
-	if result.Error == gorm.ErrRecordNotFound {
-		// If user doesn't exist, create a new user
-		user = models.User{
-			Email:          email,
-			Username:       githubUser.Login,
-			Password:       "", // Password can be empty or set a default value since OAuth is used
-			ProfilePicture: githubUser.AvatarURL,
-		}
-		if err := config.DB.Create(&user).Error; err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error creating user"})
-			return
+	state, err := oauth.ConsumeState(provider, c.Query("state"), cookieState)
+	if err != nil {
+		if err == oauth.ErrInvalidState {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid OAuth state"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
 		}
-	} else if result.Error != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return nil, false
+	}
+	return state, true
+}
+
+// respondWithOAuthResult returns the result as JSON, or redirects to the allow-listed
+// target from the login request with the result in the URL fragment
+func respondWithOAuthResult(c *gin.Context, state *models.OAuthState, body interface{}, fragment url.Values) {
+	if state.RedirectTo == "" {
+		c.JSON(http.StatusOK, body)
 		return
 	}
 
-	// Generate JWT token
-	tokenString, err := utils.GenerateJWT(user.Email)
+	target, err := url.Parse(state.RedirectTo)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error generating token"})
+		c.JSON(http.StatusOK, body)
 		return
 	}
+	target.Fragment = fragment.Encode()
+	c.Redirect(http.StatusFound, target.String())
+}
 
-	// Return the token to the user
-	c.JSON(http.StatusOK, gin.H{"token": tokenString})
+// isSecureRequest reports whether the request reached us over HTTPS
+func isSecureRequest(c *gin.Context) bool {
+	return c.Request.TLS != nil || c.GetHeader("X-Forwarded-Proto") == "https"
 }
@@ -0,0 +1,745 @@
+package controllers
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/mfuadfakhruzzaki/backend-api/config"
+	"github.com/mfuadfakhruzzaki/backend-api/models"
+	"github.com/mfuadfakhruzzaki/backend-api/utils"
+)
+
+// maxImportRows limits the size of a single CSV import
+const maxImportRows = 5000
+
+// errSpendLimitExceeded is returned when a charge would push a line over its monthly limit
+var errSpendLimitExceeded = errors.New("monthly spend limit exceeded")
+
+// CreateOrganizationRequest represents the body for creating an organization
+type CreateOrganizationRequest struct {
+	Name         string `json:"name" binding:"required"`
+	BillingEmail string `json:"billing_email" binding:"omitempty,email"`
+}
+
+// AddOrganizationMemberRequest represents the body for adding a member to an organization
+type AddOrganizationMemberRequest struct {
+	Email string `json:"email" binding:"required,email"`
+	Role  string `json:"role" binding:"omitempty,oneof=admin member"`
+}
+
+// AssignPackageRequest represents the body for assigning a package to several lines
+type AssignPackageRequest struct {
+	LineIDs   []uint `json:"line_ids" binding:"required,min=1"`
+	PackageID uint   `json:"package_id" binding:"required"`
+}
+
+// SpendLimitRequest represents the body for updating a line's monthly spend limit
+type SpendLimitRequest struct {
+	MonthlySpendLimit float64 `json:"monthly_spend_limit" binding:"gte=0"`
+}
+
+// RecordUsageRequest represents the body for recording data usage on a line
+type RecordUsageRequest struct {
+	DataUsedMB  float64 `json:"data_used_mb" binding:"gte=0"`
+	Amount      float64 `json:"amount" binding:"gte=0"`
+	Period      string  `json:"period"`
+	Description string  `json:"description"`
+}
+
+// LineOperationResult describes the outcome of a bulk operation for a single row or line
+type LineOperationResult struct {
+	Row         int    `json:"row,omitempty"`
+	LineID      uint   `json:"line_id,omitempty"`
+	PhoneNumber string `json:"phone_number,omitempty"`
+	Status      string `json:"status"`
+	Error       string `json:"error,omitempty"`
+}
+
+// LineReport is a single line's entry in the organization report
+type LineReport struct {
+	LineID            uint    `json:"line_id"`
+	EmployeeName      string  `json:"employee_name"`
+	EmployeeEmail     string  `json:"employee_email"`
+	PhoneNumber       string  `json:"phone_number"`
+	PackageID         *uint   `json:"package_id,omitempty"`
+	MonthlySpendLimit float64 `json:"monthly_spend_limit"`
+	TotalSpend        float64 `json:"total_spend"`
+	DataUsedMB        float64 `json:"data_used_mb"`
+	OverLimit         bool    `json:"over_limit"`
+}
+
+// OrganizationReport is the consolidated usage and billing report for an organization
+type OrganizationReport struct {
+	OrganizationID uint         `json:"organization_id"`
+	Name           string       `json:"name"`
+	Period         string       `json:"period"`
+	Lines          []LineReport `json:"lines"`
+	TotalLines     int          `json:"total_lines"`
+	TotalSpend     float64      `json:"total_spend"`
+	TotalDataMB    float64      `json:"total_data_used_mb"`
+}
+
+// CreateOrganization creates a corporate account with the caller as its first admin
+// @Summary Create organization
+// @Description Create a corporate account. The current user becomes an admin of the organization.
+// @Tags Organizations
+// @Accept json
+// @Produce json
+// @Param organization body CreateOrganizationRequest true "Organization data"
+// @Success 201 {object} SuccessResponse "Organization created"
+// @Failure 400 {object} ErrorResponse "Invalid request payload"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 500 {object} ErrorResponse "Database error"
+// @Router /organizations [post]
+func CreateOrganization(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	var input CreateOrganizationRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid request payload"})
+		return
+	}
+
+	org := models.Organization{
+		Name:         strings.TrimSpace(input.Name),
+		BillingEmail: input.BillingEmail,
+	}
+	if org.BillingEmail == "" {
+		org.BillingEmail = user.Email
+	}
+
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&org).Error; err != nil {
+			return err
+		}
+		return tx.Create(&models.OrganizationMember{
+			OrganizationID: org.ID,
+			UserID:         user.ID,
+			Role:           models.OrganizationRoleAdmin,
+		}).Error
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Error creating organization"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, SuccessResponse{Message: "Organization created", Data: org})
+}
+
+// GetOrganizations lists the organizations the current user belongs to
+// @Summary List organizations
+// @Description List the organizations the current user is a member of
+// @Tags Organizations
+// @Produce json
+// @Success 200 {array} models.Organization "Organizations"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 500 {object} ErrorResponse "Database error"
+// @Router /organizations [get]
+func GetOrganizations(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	var orgs []models.Organization
+	err := config.DB.
+		Joins("JOIN organization_members ON organization_members.organization_id = organizations.id").
+		Where("organization_members.user_id = ? AND organizations.deleted_at IS NULL", user.ID).
+		Find(&orgs).Error
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Database error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, orgs)
+}
+
+// AddOrganizationMember adds an existing user to the organization
+// @Summary Add organization member
+// @Description Add an existing user to the organization as admin or member. Only organization admins may do this.
+// @Tags Organizations
+// @Accept json
+// @Produce json
+// @Param id path int true "Organization ID"
+// @Param member body AddOrganizationMemberRequest true "Member data"
+// @Success 201 {object} SuccessResponse "Member added"
+// @Failure 400 {object} ErrorResponse "Invalid request payload"
+// @Failure 403 {object} ErrorResponse "Not an organization admin"
+// @Failure 404 {object} ErrorResponse "Organization or user not found"
+// @Failure 409 {object} ErrorResponse "User is already a member"
+// @Router /organizations/{id}/members [post]
+func AddOrganizationMember(c *gin.Context) {
+	org, ok := loadOrganization(c, true)
+	if !ok {
+		return
+	}
+
+	var input AddOrganizationMemberRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid request payload"})
+		return
+	}
+	if input.Role == "" {
+		input.Role = models.OrganizationRoleMember
+	}
+
+	var member models.User
+	if err := config.DB.Where("LOWER(email) = ?", utils.NormalizeEmail(input.Email)).First(&member).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, ErrorResponse{Error: "User not found"})
+		} else {
+			c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Database error"})
+		}
+		return
+	}
+
+	membership := models.OrganizationMember{
+		OrganizationID: org.ID,
+		UserID:         member.ID,
+		Role:           input.Role,
+	}
+	if err := config.DB.Create(&membership).Error; err != nil {
+		if strings.Contains(err.Error(), "duplicate key value") {
+			c.JSON(http.StatusConflict, ErrorResponse{Error: "User is already a member"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Error adding member"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, SuccessResponse{Message: "Member added", Data: membership})
+}
+
+// GetOrganizationLines lists the lines managed by an organization
+// @Summary List organization lines
+// @Description List the employee lines managed by the organization
+// @Tags Organizations
+// @Produce json
+// @Param id path int true "Organization ID"
+// @Success 200 {array} models.OrganizationLine "Lines"
+// @Failure 403 {object} ErrorResponse "Not an organization member"
+// @Failure 404 {object} ErrorResponse "Organization not found"
+// @Router /organizations/{id}/lines [get]
+func GetOrganizationLines(c *gin.Context) {
+	org, ok := loadOrganization(c, false)
+	if !ok {
+		return
+	}
+
+	var lines []models.OrganizationLine
+	if err := config.DB.Preload("Package").Where("organization_id = ? AND deleted_at IS NULL", org.ID).Order("id").Find(&lines).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Database error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, lines)
+}
+
+// ImportOrganizationLines bulk-creates or updates employee lines from a CSV file
+// @Summary Import employee lines from CSV
+// @Description Upload a CSV with a header row containing phone_number and optionally employee_name, employee_email, package_id and monthly_spend_limit. Existing lines of the organization are updated by phone number; missing or empty columns keep their current value, and a monthly_spend_limit of 0 removes the limit. Each row is reported individually.
+// @Tags Organizations
+// @Accept multipart/form-data
+// @Produce json
+// @Param id path int true "Organization ID"
+// @Param file formData file true "CSV file"
+// @Success 200 {object} SuccessResponse "Per-row import results"
+// @Failure 400 {object} ErrorResponse "Invalid CSV file"
+// @Failure 403 {object} ErrorResponse "Not an organization admin"
+// @Router /organizations/{id}/lines/import [post]
+func ImportOrganizationLines(c *gin.Context) {
+	org, ok := loadOrganization(c, true)
+	if !ok {
+		return
+	}
+
+	file, _, err := c.Request.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Error retrieving file"})
+		return
+	}
+	defer file.Close()
+
+	reader := csv.NewReader(file)
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "CSV file is empty or malformed"})
+		return
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	if _, ok := columns["phone_number"]; !ok {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "CSV header must contain a phone_number column"})
+		return
+	}
+	field := func(record []string, name string) string {
+		if i, ok := columns[name]; ok && i < len(record) {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+
+	var results []LineOperationResult
+	imported := 0
+	for row := 2; ; row++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if row-1 > maxImportRows {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: fmt.Sprintf("CSV file exceeds %d rows", maxImportRows)})
+			return
+		}
+		if err != nil {
+			results = append(results, LineOperationResult{Row: row, Status: "failed", Error: "Malformed row"})
+			continue
+		}
+
+		result := importLine(org, row, func(name string) string { return field(record, name) })
+		if result.Status != "failed" {
+			imported++
+		}
+		results = append(results, result)
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{
+		Message: fmt.Sprintf("%d of %d lines imported", imported, len(results)),
+		Data:    results,
+	})
+}
+
+// importLine creates or updates a single line from a CSV row
+func importLine(org *models.Organization, row int, field func(string) string) LineOperationResult {
+	result := LineOperationResult{Row: row, PhoneNumber: field("phone_number")}
+	if result.PhoneNumber == "" {
+		result.Status, result.Error = "failed", "phone_number is required"
+		return result
+	}
+
+	var limit *float64
+	if raw := field("monthly_spend_limit"); raw != "" {
+		parsed, err := strconv.ParseFloat(raw, 64)
+		if err != nil || parsed < 0 {
+			result.Status, result.Error = "failed", "Invalid monthly_spend_limit"
+			return result
+		}
+		limit = &parsed
+	}
+
+	var pkg *models.Package
+	if raw := field("package_id"); raw != "" {
+		id, err := strconv.ParseUint(raw, 10, 64)
+		if err != nil {
+			result.Status, result.Error = "failed", "Invalid package_id"
+			return result
+		}
+		pkg = &models.Package{}
+		if err := config.DB.First(pkg, id).Error; err != nil {
+			result.Status, result.Error = "failed", "Package not found"
+			return result
+		}
+	}
+
+	var line models.OrganizationLine
+	err := config.DB.Where("phone_number = ?", result.PhoneNumber).First(&line).Error
+	switch {
+	case err == gorm.ErrRecordNotFound:
+		line = models.OrganizationLine{OrganizationID: org.ID, PhoneNumber: result.PhoneNumber}
+		result.Status = "created"
+	case err != nil:
+		result.Status, result.Error = "failed", "Database error"
+		return result
+	case line.OrganizationID != org.ID || line.DeletedAt != nil:
+		result.Status, result.Error = "failed", "Phone number is managed by another organization"
+		return result
+	default:
+		result.Status = "updated"
+	}
+
+	// Missing or empty columns keep the current value, so a partial re-import does not
+	// blank employee details or lift spend limits; a limit is removed with an explicit 0
+	if name := field("employee_name"); name != "" {
+		line.EmployeeName = name
+	}
+	if email := field("employee_email"); email != "" {
+		line.EmployeeEmail = email
+	}
+	if limit != nil {
+		line.MonthlySpendLimit = *limit
+	}
+	if err := config.DB.Save(&line).Error; err != nil {
+		result.Status, result.Error = "failed", "Error saving line"
+		return result
+	}
+	result.LineID = line.ID
+
+	if pkg != nil {
+		if err := assignPackageToLine(&line, pkg); err != nil {
+			result.Error = "Line saved but package not assigned: " + err.Error()
+		}
+	}
+
+	return result
+}
+
+// AssignPackageToLines assigns a package to several lines at once
+// @Summary Assign package to lines
+// @Description Assign a package to multiple lines of the organization. Each assignment is charged to the line and rejected if it would exceed the line's monthly spend limit.
+// @Tags Organizations
+// @Accept json
+// @Produce json
+// @Param id path int true "Organization ID"
+// @Param assignment body AssignPackageRequest true "Lines and package"
+// @Success 200 {object} SuccessResponse "Per-line results"
+// @Failure 400 {object} ErrorResponse "Invalid request payload"
+// @Failure 403 {object} ErrorResponse "Not an organization admin"
+// @Failure 404 {object} ErrorResponse "Package not found"
+// @Router /organizations/{id}/lines/package [post]
+func AssignPackageToLines(c *gin.Context) {
+	org, ok := loadOrganization(c, true)
+	if !ok {
+		return
+	}
+
+	var input AssignPackageRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid request payload"})
+		return
+	}
+
+	var pkg models.Package
+	if err := config.DB.First(&pkg, input.PackageID).Error; err != nil {
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: "Package not found"})
+		return
+	}
+
+	results := make([]LineOperationResult, 0, len(input.LineIDs))
+	assigned := 0
+	for _, lineID := range input.LineIDs {
+		result := LineOperationResult{LineID: lineID, Status: "assigned"}
+
+		var line models.OrganizationLine
+		if err := config.DB.Where("id = ? AND organization_id = ? AND deleted_at IS NULL", lineID, org.ID).First(&line).Error; err != nil {
+			result.Status, result.Error = "failed", "Line not found"
+		} else if err := assignPackageToLine(&line, &pkg); err != nil {
+			result.Status, result.Error = "failed", err.Error()
+		} else {
+			assigned++
+		}
+		result.PhoneNumber = line.PhoneNumber
+		results = append(results, result)
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{
+		Message: fmt.Sprintf("Package assigned to %d of %d lines", assigned, len(results)),
+		Data:    results,
+	})
+}
+
+// assignPackageToLine charges the package price to the line and sets it as the active package
+func assignPackageToLine(line *models.OrganizationLine, pkg *models.Package) error {
+	period := currentPeriod()
+
+	return config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := checkSpendLimit(tx, line.ID, period, pkg.Price); err != nil {
+			return err
+		}
+
+		line.PackageID = &pkg.ID
+		if err := tx.Model(line).Update("package_id", pkg.ID).Error; err != nil {
+			return err
+		}
+
+		return tx.Create(&models.LineUsageRecord{
+			OrganizationID: line.OrganizationID,
+			LineID:         line.ID,
+			Period:         period,
+			Kind:           models.UsageKindPackage,
+			PackageID:      &pkg.ID,
+			Amount:         pkg.Price,
+			Description:    pkg.Name,
+		}).Error
+	})
+}
+
+// UpdateLineSpendLimit sets the monthly spend limit of a single line
+// @Summary Update line spend limit
+// @Description Set the monthly spend limit for an employee line. Use 0 to remove the limit.
+// @Tags Organizations
+// @Accept json
+// @Produce json
+// @Param id path int true "Organization ID"
+// @Param lineId path int true "Line ID"
+// @Param limit body SpendLimitRequest true "Monthly spend limit"
+// @Success 200 {object} SuccessResponse "Spend limit updated"
+// @Failure 400 {object} ErrorResponse "Invalid request payload"
+// @Failure 403 {object} ErrorResponse "Not an organization admin"
+// @Failure 404 {object} ErrorResponse "Line not found"
+// @Router /organizations/{id}/lines/{lineId}/spend-limit [put]
+func UpdateLineSpendLimit(c *gin.Context) {
+	org, ok := loadOrganization(c, true)
+	if !ok {
+		return
+	}
+	line, ok := loadOrganizationLine(c, org)
+	if !ok {
+		return
+	}
+
+	var input SpendLimitRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid request payload"})
+		return
+	}
+
+	if err := config.DB.Model(line).Update("monthly_spend_limit", input.MonthlySpendLimit).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Error updating spend limit"})
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{Message: "Spend limit updated", Data: line})
+}
+
+// RecordLineUsage records data usage (and any overage charge) for a line
+// @Summary Record line usage
+// @Description Record data usage for an employee line. An optional amount is added to the line's spend for the period and is rejected if it would exceed the line's monthly spend limit.
+// @Tags Organizations
+// @Accept json
+// @Produce json
+// @Param id path int true "Organization ID"
+// @Param lineId path int true "Line ID"
+// @Param usage body RecordUsageRequest true "Usage data"
+// @Success 201 {object} SuccessResponse "Usage recorded"
+// @Failure 400 {object} ErrorResponse "Invalid request payload"
+// @Failure 403 {object} ErrorResponse "Not an organization admin"
+// @Failure 404 {object} ErrorResponse "Line not found"
+// @Failure 409 {object} ErrorResponse "Monthly spend limit exceeded"
+// @Router /organizations/{id}/lines/{lineId}/usage [post]
+func RecordLineUsage(c *gin.Context) {
+	org, ok := loadOrganization(c, true)
+	if !ok {
+		return
+	}
+	line, ok := loadOrganizationLine(c, org)
+	if !ok {
+		return
+	}
+
+	var input RecordUsageRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid request payload"})
+		return
+	}
+	if input.Period == "" {
+		input.Period = currentPeriod()
+	} else if _, err := time.Parse("2006-01", input.Period); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Period must use the YYYY-MM format"})
+		return
+	}
+
+	record := models.LineUsageRecord{
+		OrganizationID: org.ID,
+		LineID:         line.ID,
+		Period:         input.Period,
+		Kind:           models.UsageKindData,
+		DataUsedMB:     input.DataUsedMB,
+		Amount:         input.Amount,
+		Description:    input.Description,
+	}
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := checkSpendLimit(tx, line.ID, record.Period, record.Amount); err != nil {
+			return err
+		}
+		return tx.Create(&record).Error
+	})
+	if errors.Is(err, errSpendLimitExceeded) {
+		c.JSON(http.StatusConflict, ErrorResponse{Error: "Charge would exceed the line's monthly spend limit"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Error recording usage"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, SuccessResponse{Message: "Usage recorded", Data: record})
+}
+
+// GetOrganizationReport returns the consolidated usage and billing report for a period
+// @Summary Organization usage and billing report
+// @Description Consolidated usage and spend per line for the given month, with totals for the organization
+// @Tags Organizations
+// @Produce json
+// @Param id path int true "Organization ID"
+// @Param period query string false "Billing period in YYYY-MM format, defaults to the current month"
+// @Success 200 {object} OrganizationReport "Report"
+// @Failure 400 {object} ErrorResponse "Invalid period"
+// @Failure 403 {object} ErrorResponse "Not an organization member"
+// @Failure 404 {object} ErrorResponse "Organization not found"
+// @Router /organizations/{id}/report [get]
+func GetOrganizationReport(c *gin.Context) {
+	org, ok := loadOrganization(c, false)
+	if !ok {
+		return
+	}
+
+	period := c.DefaultQuery("period", currentPeriod())
+	if _, err := time.Parse("2006-01", period); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Period must use the YYYY-MM format"})
+		return
+	}
+
+	var lines []models.OrganizationLine
+	if err := config.DB.Where("organization_id = ? AND deleted_at IS NULL", org.ID).Order("id").Find(&lines).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Database error"})
+		return
+	}
+
+	type usageTotal struct {
+		LineID     uint
+		Amount     float64
+		DataUsedMB float64
+	}
+	var totals []usageTotal
+	err := config.DB.Model(&models.LineUsageRecord{}).
+		Select("line_id, SUM(amount) AS amount, SUM(data_used_mb) AS data_used_mb").
+		Where("organization_id = ? AND period = ?", org.ID, period).
+		Group("line_id").
+		Scan(&totals).Error
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Database error"})
+		return
+	}
+	byLine := make(map[uint]usageTotal, len(totals))
+	for _, t := range totals {
+		byLine[t.LineID] = t
+	}
+
+	report := OrganizationReport{
+		OrganizationID: org.ID,
+		Name:           org.Name,
+		Period:         period,
+		Lines:          make([]LineReport, 0, len(lines)),
+		TotalLines:     len(lines),
+	}
+	for _, line := range lines {
+		usage := byLine[line.ID]
+		report.Lines = append(report.Lines, LineReport{
+			LineID:            line.ID,
+			EmployeeName:      line.EmployeeName,
+			EmployeeEmail:     line.EmployeeEmail,
+			PhoneNumber:       line.PhoneNumber,
+			PackageID:         line.PackageID,
+			MonthlySpendLimit: line.MonthlySpendLimit,
+			TotalSpend:        usage.Amount,
+			DataUsedMB:        usage.DataUsedMB,
+			OverLimit:         line.MonthlySpendLimit > 0 && usage.Amount > line.MonthlySpendLimit,
+		})
+		report.TotalSpend += usage.Amount
+		report.TotalDataMB += usage.DataUsedMB
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// loadOrganization resolves the ":id" organization and checks the caller's membership
+func loadOrganization(c *gin.Context, requireAdmin bool) (*models.Organization, bool) {
+	user, ok := currentUser(c)
+	if !ok {
+		return nil, false
+	}
+	orgID, ok := parseIDParam(c, "id")
+	if !ok {
+		return nil, false
+	}
+
+	var org models.Organization
+	if err := config.DB.Where("id = ? AND deleted_at IS NULL", orgID).First(&org).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, ErrorResponse{Error: "Organization not found"})
+		} else {
+			c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Database error"})
+		}
+		return nil, false
+	}
+
+	var member models.OrganizationMember
+	if err := config.DB.Where("organization_id = ? AND user_id = ?", org.ID, user.ID).First(&member).Error; err != nil {
+		c.JSON(http.StatusForbidden, ErrorResponse{Error: "You are not a member of this organization"})
+		return nil, false
+	}
+	if requireAdmin && member.Role != models.OrganizationRoleAdmin {
+		c.JSON(http.StatusForbidden, ErrorResponse{Error: "Organization admin role required"})
+		return nil, false
+	}
+
+	return &org, true
+}
+
+// loadOrganizationLine resolves the ":lineId" line within the organization
+func loadOrganizationLine(c *gin.Context, org *models.Organization) (*models.OrganizationLine, bool) {
+	lineID, ok := parseIDParam(c, "lineId")
+	if !ok {
+		return nil, false
+	}
+
+	var line models.OrganizationLine
+	if err := config.DB.Where("id = ? AND organization_id = ? AND deleted_at IS NULL", lineID, org.ID).First(&line).Error; err != nil {
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: "Line not found"})
+		return nil, false
+	}
+	return &line, true
+}
+
+// checkSpendLimit returns errSpendLimitExceeded if charging amount to the line would exceed
+// its monthly spend limit. The line row is locked until tx ends, so concurrent charges to
+// the same line are checked one after another against the committed spend.
+func checkSpendLimit(tx *gorm.DB, lineID uint, period string, amount float64) error {
+	var line models.OrganizationLine
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&line, lineID).Error; err != nil {
+		return err
+	}
+	if line.MonthlySpendLimit <= 0 || amount <= 0 {
+		return nil
+	}
+
+	spent, err := lineSpend(tx, line.ID, period)
+	if err != nil {
+		return err
+	}
+	if spent+amount > line.MonthlySpendLimit {
+		return errSpendLimitExceeded
+	}
+	return nil
+}
+
+// lineSpend sums the charges recorded for a line in a billing period
+func lineSpend(tx *gorm.DB, lineID uint, period string) (float64, error) {
+	var total float64
+	err := tx.Model(&models.LineUsageRecord{}).
+		Select("COALESCE(SUM(amount), 0)").
+		Where("line_id = ? AND period = ?", lineID, period).
+		Scan(&total).Error
+	return total, err
+}
+
+// currentPeriod returns the current billing period in YYYY-MM format
+func currentPeriod() string {
+	return time.Now().Format("2006-01")
+}
@@ -5,10 +5,9 @@ import (
 	"strconv"
 
 	"github.com/gin-gonic/gin"
-	"gorm.io/gorm"
 
+	"github.com/mfuadfakhruzzaki/backend-api/audit"
 	"github.com/mfuadfakhruzzaki/backend-api/config"
-	"github.com/mfuadfakhruzzaki/backend-api/middleware"
 	"github.com/mfuadfakhruzzaki/backend-api/models"
 )
 
@@ -51,41 +50,31 @@ func SelectPackage(c *gin.Context) {
 		return
 	}
 
-	// Retrieve the user's email from the context (set by JWT middleware)
-	email, exists := c.Get(string(middleware.UserContextKey))
-	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "User email not found in context"})
-		return
-	}
-
-	emailStr, ok := email.(string)
-	if !ok || emailStr == "" {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid user email in context"})
-		return
-	}
-
-	// Find the user by email
-	var user models.User
-	result := config.DB.Where("email = ?", emailStr).First(&user)
-	if result.Error != nil {
-		if result.Error == gorm.ErrRecordNotFound {
-			c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
-		} else {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
-		}
+	// Load the authenticated user (set by JWT middleware)
+	user, ok := currentUser(c)
+	if !ok {
 		return
 	}
 
 	// Update the user's PackageID
+	previous := user.PackageID
 	pkgID := uint(packageID)
 	user.PackageID = &pkgID
 
-	if err := config.DB.Save(&user).Error; err != nil {
+	if err := config.DB.Save(user).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error updating user package"})
 		return
 	}
+	recordAudit(c, audit.Entry{
+		Action: audit.ActionPackageSelected,
+		UserID: &user.ID,
+		Before: gin.H{"package_id": previous},
+		After:  gin.H{"package_id": pkgID},
+	})
 
 	// Optionally, you can fetch the updated user or include additional information
+	user.Password = ""
+	presentUser(c.Request.Context(), user)
 	c.JSON(http.StatusOK, gin.H{
 		"message":      "Package selected successfully",
 		"user":         user,
@@ -0,0 +1,217 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-webauthn/webauthn/protocol"
+
+	"github.com/mfuadfakhruzzaki/backend-api/config"
+	"github.com/mfuadfakhruzzaki/backend-api/models"
+	"github.com/mfuadfakhruzzaki/backend-api/passkey"
+)
+
+// PasskeyRegistrationRequest names the passkey that is about to be registered
+type PasskeyRegistrationRequest struct {
+	Name string `json:"name" binding:"max=64"`
+}
+
+// PasskeyCeremonyResponse carries the options for navigator.credentials.create()/get()
+// and the session that must be sent back to the matching finish endpoint
+type PasskeyCeremonyResponse struct {
+	Session string      `json:"session"`
+	Options interface{} `json:"options"`
+}
+
+// GetPasskeys lists the passkeys registered by the current user
+// @Summary List passkeys
+// @Description List the WebAuthn passkeys registered by the current user
+// @Tags Passkeys
+// @Produce json
+// @Success 200 {array} models.WebAuthnCredential "Passkeys"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 500 {object} ErrorResponse "Database error"
+// @Router /users/passkeys [get]
+func GetPasskeys(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	var credentials []models.WebAuthnCredential
+	if err := config.DB.Where("user_id = ?", user.ID).Order("created_at").Find(&credentials).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Database error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, credentials)
+}
+
+// BeginPasskeyRegistration starts registering a passkey for the current user
+// @Summary Start passkey registration
+// @Description Returns the PublicKeyCredentialCreationOptions for navigator.credentials.create() and a session that must be passed to /users/passkeys/register/finish within a few minutes.
+// @Tags Passkeys
+// @Accept json
+// @Produce json
+// @Param request body PasskeyRegistrationRequest false "Display name for the passkey"
+// @Success 200 {object} PasskeyCeremonyResponse "Creation options and session"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 500 {object} ErrorResponse "Error starting registration"
+// @Router /users/passkeys/register/begin [post]
+func BeginPasskeyRegistration(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	var input PasskeyRegistrationRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&input); err != nil {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid request payload"})
+			return
+		}
+	}
+
+	options, session, err := passkey.BeginRegistration(user, strings.TrimSpace(input.Name))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Error starting passkey registration"})
+		return
+	}
+
+	c.JSON(http.StatusOK, PasskeyCeremonyResponse{Session: session, Options: options})
+}
+
+// FinishPasskeyRegistration verifies the authenticator response and stores the passkey
+// @Summary Finish passkey registration
+// @Description Send the PublicKeyCredential returned by navigator.credentials.create() as the JSON body.
+// @Tags Passkeys
+// @Accept json
+// @Produce json
+// @Param session query string true "Session from /users/passkeys/register/begin"
+// @Success 201 {object} models.WebAuthnCredential "Registered passkey"
+// @Failure 400 {object} ErrorResponse "Invalid or expired session, or the response could not be verified"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 409 {object} ErrorResponse "Passkey already registered"
+// @Router /users/passkeys/register/finish [post]
+func FinishPasskeyRegistration(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	credential, err := passkey.FinishRegistration(user, c.Query("session"), c.Request)
+	if err != nil {
+		switch {
+		case errors.Is(err, passkey.ErrInvalidSession):
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid or expired passkey session"})
+		case strings.Contains(err.Error(), "duplicate key value"):
+			c.JSON(http.StatusConflict, ErrorResponse{Error: "This passkey is already registered"})
+		default:
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Passkey registration failed: " + passkeyErrorDetail(err)})
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, credential)
+}
+
+// DeletePasskey removes one of the current user's passkeys
+// @Summary Delete a passkey
+// @Description Remove a passkey. A user without a password cannot remove their last passkey or linked identity.
+// @Tags Passkeys
+// @Produce json
+// @Param id path int true "Passkey ID"
+// @Success 200 {object} SuccessResponse "Passkey deleted"
+// @Failure 404 {object} ErrorResponse "Passkey not found"
+// @Failure 409 {object} ErrorResponse "Last login method"
+// @Failure 500 {object} ErrorResponse "Error deleting passkey"
+// @Router /users/passkeys/{id} [delete]
+func DeletePasskey(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+	credentialID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var credential models.WebAuthnCredential
+	if err := config.DB.Where("id = ? AND user_id = ?", credentialID, user.ID).First(&credential).Error; err != nil {
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: "Passkey not found"})
+		return
+	}
+
+	if user.Password == "" && passwordlessLoginMethods(user.ID) <= 1 {
+		c.JSON(http.StatusConflict, ErrorResponse{Error: "Set a password before removing your only passkey"})
+		return
+	}
+
+	if err := config.DB.Delete(&credential).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Error deleting passkey"})
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{Message: "Passkey deleted"})
+}
+
+// BeginPasskeyLogin starts a passwordless login with a passkey
+// @Summary Start passkey login
+// @Description Returns the PublicKeyCredentialRequestOptions for navigator.credentials.get() and a session for /auth/passkey/login/finish. No email is needed; the authenticator offers the passkeys it holds for this site.
+// @Tags Auth
+// @Produce json
+// @Success 200 {object} PasskeyCeremonyResponse "Request options and session"
+// @Failure 500 {object} ErrorResponse "Error starting login"
+// @Router /auth/passkey/login/begin [post]
+func BeginPasskeyLogin(c *gin.Context) {
+	options, session, err := passkey.BeginLogin()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Error starting passkey login"})
+		return
+	}
+
+	c.JSON(http.StatusOK, PasskeyCeremonyResponse{Session: session, Options: options})
+}
+
+// FinishPasskeyLogin verifies the assertion and logs the user in
+// @Summary Finish passkey login
+// @Description Send the PublicKeyCredential returned by navigator.credentials.get() as the JSON body. Passkeys require user verification on the device, so no additional two-factor code is asked for.
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param session query string true "Session from /auth/passkey/login/begin"
+// @Success 200 {object} SuccessResponse{data=LoginResult} "JWT token"
+// @Failure 401 {object} ErrorResponse "The passkey could not be verified"
+// @Failure 403 {object} ErrorResponse "Account suspended or banned"
+// @Failure 500 {object} ErrorResponse "Error generating token"
+// @Router /auth/passkey/login/finish [post]
+func FinishPasskeyLogin(c *gin.Context) {
+	user, err := passkey.FinishLogin(c.Query("session"), c.Request)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{Error: "Passkey login failed: " + passkeyErrorDetail(err)})
+		return
+	}
+	if !user.EmailVerified {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{Error: "Email not verified. Please verify your email first."})
+		return
+	}
+
+	result, err := issueAccessToken(c, user, "passkey")
+	if err != nil {
+		respondLoginError(c, user, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{Message: "Login successful", Data: result})
+}
+
+// passkeyErrorDetail returns the most useful message from a WebAuthn protocol error
+func passkeyErrorDetail(err error) string {
+	var protocolErr *protocol.Error
+	if errors.As(err, &protocolErr) && protocolErr.DevInfo != "" {
+		return protocolErr.DevInfo
+	}
+	return err.Error()
+}
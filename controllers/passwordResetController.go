@@ -0,0 +1,195 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm/clause"
+
+	"github.com/mfuadfakhruzzaki/backend-api/audit"
+	"github.com/mfuadfakhruzzaki/backend-api/config"
+	"github.com/mfuadfakhruzzaki/backend-api/jobs"
+	"github.com/mfuadfakhruzzaki/backend-api/lockout"
+	"github.com/mfuadfakhruzzaki/backend-api/models"
+	"github.com/mfuadfakhruzzaki/backend-api/session"
+	"github.com/mfuadfakhruzzaki/backend-api/utils"
+)
+
+const (
+	// passwordResetTTL is how long a password reset link stays valid
+	passwordResetTTL = time.Hour
+	// passwordResetResendInterval stops repeated requests from flooding a mailbox
+	passwordResetResendInterval = time.Minute
+)
+
+// ForgotPasswordRequest represents the request body for requesting a password reset link
+type ForgotPasswordRequest struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+// ResetPasswordRequest represents the request body for choosing a new password with a reset token
+type ResetPasswordRequest struct {
+	Token       string `json:"token" binding:"required"`
+	NewPassword string `json:"new_password" binding:"required"`
+}
+
+// ForgotPassword emails a single-use password reset link
+// @Summary Request a password reset link
+// @Description Emails a single-use link for choosing a new password. The link points at PASSWORD_RESET_URL with the token in the "token" query parameter; the frontend posts it to /auth/password/reset. The response is the same whether or not an account exists for the email, and the email is sent in the background.
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param request body ForgotPasswordRequest true "Email address"
+// @Success 200 {object} SuccessResponse "Reset link sent if the account exists"
+// @Failure 400 {object} ErrorResponse "Invalid request payload"
+// @Router /auth/password/forgot [post]
+func ForgotPassword(c *gin.Context) {
+	var input ForgotPasswordRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid request payload"})
+		return
+	}
+
+	response := SuccessResponse{Message: "If an account exists for this email, a password reset link has been sent."}
+
+	var user models.User
+	if err := config.DB.Where("LOWER(email) = ? AND deleted_at IS NULL", utils.NormalizeEmail(input.Email)).First(&user).Error; err == nil {
+		// Creating and sending the link happens in the background, so neither the status
+		// code nor the response time tells whether the account exists
+		jobs.Go(func() { requestPasswordReset(&user) })
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// requestPasswordReset sends a reset link unless one was sent moments ago. Failures are
+// only logged, since the request has already been answered.
+func requestPasswordReset(user *models.User) {
+	// A link sent moments ago is still on its way; do not send another one
+	var recent int64
+	config.DB.Model(&models.PasswordReset{}).
+		Where("user_id = ? AND created_at > ?", user.ID, time.Now().Add(-passwordResetResendInterval)).
+		Count(&recent)
+	if recent > 0 {
+		return
+	}
+
+	if err := sendPasswordReset(user); err != nil && err != errPasswordResetEmail {
+		fmt.Printf("Failed to create password reset link for user %d: %v\n", user.ID, err)
+	}
+}
+
+// errPasswordResetEmail is returned by sendPasswordReset when the link was created but not sent
+var errPasswordResetEmail = errors.New("failed to send password reset email")
+
+// sendPasswordReset replaces any earlier reset link of the user with a new one and emails it
+func sendPasswordReset(user *models.User) error {
+	token, err := utils.RandomToken(32)
+	if err != nil {
+		return err
+	}
+
+	// Only the newest link of a user is valid
+	config.DB.Where("user_id = ? OR expires_at < ?", user.ID, time.Now()).Delete(&models.PasswordReset{})
+
+	reset := models.PasswordReset{
+		UserID:    user.ID,
+		TokenHash: utils.HashToken(token),
+		ExpiresAt: time.Now().Add(passwordResetTTL),
+	}
+	if err := config.DB.Create(&reset).Error; err != nil {
+		return err
+	}
+
+	if err := utils.SendPasswordResetLink(user.Email, frontendLink(config.App.Account.PasswordResetURL, token), token, passwordResetTTL); err != nil {
+		fmt.Printf("Failed to send password reset link to %s: %v\n", user.Email, err)
+		return errPasswordResetEmail
+	}
+	return nil
+}
+
+// respondPasswordResetError answers a failed sendPasswordReset call of an admin request
+func respondPasswordResetError(c *gin.Context, err error) {
+	if err == errPasswordResetEmail {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to send password reset email"})
+		return
+	}
+	c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Error creating reset link"})
+}
+
+// ResetPassword sets a new password with a token from a reset link
+// @Summary Reset password
+// @Description Sets a new password using the token from a password reset link. The new password must meet the password policy; if it does not, the token stays valid so the user can try another password. A successful reset also verifies the email and lifts a failed-login lockout, and logs out all existing sessions. It does not log the user in, and two-factor authentication still applies at the next login.
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param request body ResetPasswordRequest true "Reset token and new password"
+// @Success 200 {object} SuccessResponse "Password reset"
+// @Failure 400 {object} PasswordPolicyError "Invalid request payload, or the password does not meet the password policy"
+// @Failure 401 {object} ErrorResponse "Invalid or expired reset link"
+// @Failure 500 {object} ErrorResponse "Error updating password"
+// @Router /auth/password/reset [post]
+func ResetPassword(c *gin.Context) {
+	var input ResetPasswordRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid request payload"})
+		return
+	}
+
+	invalid := ErrorResponse{Error: "Invalid or expired password reset link. Please request a new one."}
+	tokenHash := utils.HashToken(strings.TrimSpace(input.Token))
+
+	var reset models.PasswordReset
+	if err := config.DB.Where("token_hash = ? AND expires_at > ?", tokenHash, time.Now()).First(&reset).Error; err != nil {
+		c.JSON(http.StatusUnauthorized, invalid)
+		return
+	}
+	var user models.User
+	if err := config.DB.First(&user, reset.UserID).Error; err != nil {
+		c.JSON(http.StatusUnauthorized, invalid)
+		return
+	}
+
+	if !checkPasswordPolicy(c, input.NewPassword, user.Email, user.Username) {
+		return
+	}
+	hashedPassword, err := utils.HashPassword(input.NewPassword)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Error hashing password"})
+		return
+	}
+
+	// Deleting with RETURNING makes the token single-use even under concurrent requests
+	var consumed []models.PasswordReset
+	if err := config.DB.Clauses(clause.Returning{}).
+		Where("token_hash = ? AND expires_at > ?", tokenHash, time.Now()).
+		Delete(&consumed).Error; err != nil || len(consumed) != 1 {
+		c.JSON(http.StatusUnauthorized, invalid)
+		return
+	}
+
+	// Receiving the email proves ownership of the address
+	if err := config.DB.Model(&user).Updates(map[string]interface{}{
+		"password":          hashedPassword,
+		"email_verified":    true,
+		"verification_code": "",
+	}).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Error updating password"})
+		return
+	}
+	config.DB.Where("user_id = ?", user.ID).Delete(&models.PasswordReset{})
+	lockout.Reset(lockout.AccountKey(user.Email))
+	// Whoever knew the old password is logged out everywhere
+	revoked, _ := session.RevokeAll(user.ID, "")
+	recordAudit(c, audit.Entry{Action: audit.ActionPasswordReset, ActorID: &user.ID, UserID: &user.ID, Metadata: gin.H{"sessions_revoked": revoked}})
+
+	if err := utils.SendPasswordChangedNotice(user.Email); err != nil {
+		fmt.Printf("Failed to notify %s about password reset: %v\n", user.Email, err)
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{Message: "Password reset successfully. You can now log in with your new password."})
+}
@@ -0,0 +1,111 @@
+package controllers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/mfuadfakhruzzaki/backend-api/audit"
+	"github.com/mfuadfakhruzzaki/backend-api/config"
+	"github.com/mfuadfakhruzzaki/backend-api/models"
+	"github.com/mfuadfakhruzzaki/backend-api/session"
+)
+
+// SessionResponse is a login session as shown to its owner
+type SessionResponse struct {
+	models.Session
+	// Current marks the session of the token used for this request
+	Current bool `json:"current"`
+}
+
+// GetSessions lists the active login sessions of the current user
+// @Summary List sessions
+// @Description List the devices the current user is logged in on, with the login method, IP address and last activity. The session of the token used for the request is marked as current.
+// @Tags Sessions
+// @Produce json
+// @Success 200 {array} SessionResponse "Active sessions"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 500 {object} ErrorResponse "Database error"
+// @Router /users/sessions [get]
+func GetSessions(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	var sessions []models.Session
+	err := config.DB.Where("user_id = ? AND revoked_at IS NULL AND expires_at > ?", user.ID, time.Now()).
+		Order("last_active_at DESC").Find(&sessions).Error
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Database error"})
+		return
+	}
+
+	current := currentSessionID(c)
+	response := make([]SessionResponse, 0, len(sessions))
+	for _, s := range sessions {
+		response = append(response, SessionResponse{Session: s, Current: current != "" && s.PublicID == current})
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// RevokeSession logs the current user out of one session
+// @Summary Revoke a session
+// @Description Log out of one session. Tokens of the session stop working immediately. Revoking the current session logs out of this device.
+// @Tags Sessions
+// @Produce json
+// @Param id path int true "Session ID"
+// @Success 200 {object} SuccessResponse "Session revoked"
+// @Failure 404 {object} ErrorResponse "Session not found"
+// @Failure 500 {object} ErrorResponse "Database error"
+// @Router /users/sessions/{id} [delete]
+func RevokeSession(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+	sessionID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	revoked, err := session.Revoke(user.ID, sessionID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Database error"})
+		return
+	}
+	if !revoked {
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: "Session not found"})
+		return
+	}
+	recordAudit(c, audit.Entry{Action: audit.ActionSessionRevoked, UserID: &user.ID, Metadata: gin.H{"session_id": sessionID}})
+
+	c.JSON(http.StatusOK, SuccessResponse{Message: "Session revoked"})
+}
+
+// RevokeOtherSessions logs the current user out everywhere except on this device
+// @Summary Revoke all other sessions
+// @Description Log out of every session except the one making this request.
+// @Tags Sessions
+// @Produce json
+// @Success 200 {object} SuccessResponse "Sessions revoked, data.revoked holds the count"
+// @Failure 500 {object} ErrorResponse "Database error"
+// @Router /users/sessions [delete]
+func RevokeOtherSessions(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	count, err := session.RevokeAll(user.ID, currentSessionID(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Database error"})
+		return
+	}
+	if count > 0 {
+		recordAudit(c, audit.Entry{Action: audit.ActionSessionRevoked, UserID: &user.ID, Metadata: gin.H{"sessions_revoked": count}})
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{Message: "Other sessions revoked", Data: gin.H{"revoked": count}})
+}
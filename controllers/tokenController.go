@@ -0,0 +1,195 @@
+package controllers
+
+import (
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/mfuadfakhruzzaki/backend-api/audit"
+	"github.com/mfuadfakhruzzaki/backend-api/config"
+	"github.com/mfuadfakhruzzaki/backend-api/models"
+	"github.com/mfuadfakhruzzaki/backend-api/pat"
+)
+
+const (
+	// tokenDefaultLifetimeDays is used when a token is created without expires_in_days
+	tokenDefaultLifetimeDays = 90
+	// tokenMaxLifetimeDays keeps forgotten tokens from working forever
+	tokenMaxLifetimeDays = 365
+	// maxTokensPerUser limits how many active tokens one user can have
+	maxTokensPerUser = 50
+)
+
+// CreateTokenRequest represents the request body for creating a personal access token
+type CreateTokenRequest struct {
+	Name          string   `json:"name" binding:"required,max=100"`
+	Scopes        []string `json:"scopes" binding:"required" example:"packages:read,usage:write"`
+	ExpiresInDays int      `json:"expires_in_days" example:"90"`
+}
+
+// PersonalAccessTokenResponse is a personal access token as shown to its owner
+type PersonalAccessTokenResponse struct {
+	models.PersonalAccessToken
+	Scopes []string `json:"scopes"`
+	// Token is only returned once, right after the token was created
+	Token string `json:"token,omitempty"`
+}
+
+// TokenScopeResponse describes a scope that can be granted to a token
+type TokenScopeResponse struct {
+	Scope       string `json:"scope"`
+	Description string `json:"description"`
+}
+
+// GetTokenScopes lists the scopes that personal access tokens can be given
+// @Summary List token scopes
+// @Description List the scopes that can be granted to a personal access token. Endpoints that do not require one of these scopes cannot be called with a token.
+// @Tags Tokens
+// @Produce json
+// @Success 200 {array} TokenScopeResponse "Available scopes"
+// @Router /users/tokens/scopes [get]
+func GetTokenScopes(c *gin.Context) {
+	scopes := make([]TokenScopeResponse, 0, len(models.TokenScopes))
+	for scope, description := range models.TokenScopes {
+		scopes = append(scopes, TokenScopeResponse{Scope: scope, Description: description})
+	}
+	sort.Slice(scopes, func(i, j int) bool { return scopes[i].Scope < scopes[j].Scope })
+	c.JSON(http.StatusOK, scopes)
+}
+
+// GetPersonalAccessTokens lists the personal access tokens of the current user
+// @Summary List personal access tokens
+// @Description List the personal access tokens of the current user, including expired ones. The tokens themselves are never shown again after creation.
+// @Tags Tokens
+// @Produce json
+// @Success 200 {array} PersonalAccessTokenResponse "Personal access tokens"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 500 {object} ErrorResponse "Database error"
+// @Router /users/tokens [get]
+func GetPersonalAccessTokens(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	var tokens []models.PersonalAccessToken
+	if err := config.DB.Where("user_id = ?", user.ID).Order("created_at DESC").Find(&tokens).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Database error"})
+		return
+	}
+
+	response := make([]PersonalAccessTokenResponse, 0, len(tokens))
+	for _, token := range tokens {
+		response = append(response, PersonalAccessTokenResponse{PersonalAccessToken: token, Scopes: pat.Scopes(&token)})
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// CreatePersonalAccessToken creates a personal access token for scripts and integrations
+// @Summary Create a personal access token
+// @Description Create a token that scripts can send as "Authorization: Bearer <token>" instead of logging in with a password. The token can only call endpoints that require one of its scopes, and is shown only in this response. Tokens expire after expires_in_days (default 90, at most 365). Tokens cannot be created with another token.
+// @Tags Tokens
+// @Accept json
+// @Produce json
+// @Param token body CreateTokenRequest true "Token name, scopes and lifetime"
+// @Success 201 {object} SuccessResponse{data=PersonalAccessTokenResponse} "Token created"
+// @Failure 400 {object} ErrorResponse "Invalid request payload or unknown scope"
+// @Failure 409 {object} ErrorResponse "Too many tokens"
+// @Failure 500 {object} ErrorResponse "Error creating token"
+// @Router /users/tokens [post]
+func CreatePersonalAccessToken(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	var input CreateTokenRequest
+	if err := c.ShouldBindJSON(&input); err != nil || strings.TrimSpace(input.Name) == "" {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid request payload"})
+		return
+	}
+	scopes, err := pat.NormalizeScopes(input.Scopes)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid scopes: " + err.Error()})
+		return
+	}
+	if input.ExpiresInDays == 0 {
+		input.ExpiresInDays = tokenDefaultLifetimeDays
+	}
+	if input.ExpiresInDays < 1 || input.ExpiresInDays > tokenMaxLifetimeDays {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "expires_in_days must be between 1 and 365"})
+		return
+	}
+
+	var active int64
+	config.DB.Model(&models.PersonalAccessToken{}).Where("user_id = ? AND expires_at > ?", user.ID, time.Now()).Count(&active)
+	if active >= maxTokensPerUser {
+		c.JSON(http.StatusConflict, ErrorResponse{Error: "Too many personal access tokens. Delete unused tokens first."})
+		return
+	}
+
+	token, prefix, hash, err := pat.Generate()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Error creating token"})
+		return
+	}
+	record := models.PersonalAccessToken{
+		UserID:    user.ID,
+		Name:      strings.TrimSpace(input.Name),
+		Prefix:    prefix,
+		TokenHash: hash,
+		Scopes:    strings.Join(scopes, ","),
+		ExpiresAt: time.Now().AddDate(0, 0, input.ExpiresInDays),
+	}
+	if err := config.DB.Create(&record).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Error creating token"})
+		return
+	}
+	recordAudit(c, audit.Entry{
+		Action: audit.ActionTokenCreated,
+		UserID: &user.ID,
+		After:  gin.H{"token_id": record.ID, "name": record.Name, "prefix": record.Prefix, "scopes": scopes, "expires_at": record.ExpiresAt},
+	})
+
+	c.JSON(http.StatusCreated, SuccessResponse{
+		Message: "Token created. Copy it now, it will not be shown again.",
+		Data:    PersonalAccessTokenResponse{PersonalAccessToken: record, Scopes: scopes, Token: token},
+	})
+}
+
+// DeletePersonalAccessToken revokes a personal access token
+// @Summary Delete a personal access token
+// @Description Revoke a personal access token of the current user. Requests made with it fail immediately.
+// @Tags Tokens
+// @Produce json
+// @Param id path int true "Token ID"
+// @Success 200 {object} SuccessResponse "Token deleted"
+// @Failure 404 {object} ErrorResponse "Token not found"
+// @Failure 500 {object} ErrorResponse "Database error"
+// @Router /users/tokens/{id} [delete]
+func DeletePersonalAccessToken(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+	tokenID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	result := config.DB.Where("id = ? AND user_id = ?", tokenID, user.ID).Delete(&models.PersonalAccessToken{})
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Database error"})
+		return
+	}
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: "Token not found"})
+		return
+	}
+	recordAudit(c, audit.Entry{Action: audit.ActionTokenDeleted, UserID: &user.ID, Metadata: gin.H{"token_id": tokenID}})
+
+	c.JSON(http.StatusOK, SuccessResponse{Message: "Token deleted"})
+}
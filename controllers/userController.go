@@ -1,16 +1,24 @@
 package controllers
 
 import (
+	"bytes"
+	"crypto/subtle"
+	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
-	"os"
-	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/mfuadfakhruzzaki/backend-api/audit"
 	"github.com/mfuadfakhruzzaki/backend-api/config"
-	"github.com/mfuadfakhruzzaki/backend-api/middleware"
 	"github.com/mfuadfakhruzzaki/backend-api/models"
+	"github.com/mfuadfakhruzzaki/backend-api/session"
+	"github.com/mfuadfakhruzzaki/backend-api/storage"
+	"github.com/mfuadfakhruzzaki/backend-api/utils"
+	"gorm.io/datatypes"
 	"gorm.io/gorm"
 )
 
@@ -20,7 +28,7 @@ import (
 // @Tags User
 // @Accept multipart/form-data
 // @Produce json
-// @Param profile_picture formData file true "Profile picture file (jpg, jpeg, png)"
+// @Param profile_picture formData file true "Profile picture file (JPEG, PNG or WebP, detected from content)"
 // @Success 200 {object} map[string]interface{} "Profile picture uploaded successfully"
 // @Failure 400 {object} map[string]interface{} "Invalid request or file type"
 // @Failure 401 {object} map[string]interface{} "Unauthorized or email not found"
@@ -31,39 +39,17 @@ func UploadProfilePicture(c *gin.Context) {
 	// Log the request for debugging purposes
 	fmt.Println("Received request to upload profile picture")
 
-	// Retrieve the user's email from the context (set by JWT middleware)
-	email, exists := c.Get(string(middleware.UserContextKey))
-	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized: Email not found in context"})
+	// Load the authenticated user (set by JWT middleware)
+	user, ok := currentUser(c)
+	if !ok {
 		fmt.Println("Unauthorized: Missing or invalid token")
 		return
 	}
 
-	emailStr, ok := email.(string)
-	if !ok || emailStr == "" {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized: Invalid email in context"})
-		fmt.Println("Unauthorized: Invalid email in context")
-		return
-	}
-
-	// Find the user in the database based on email
-	var user models.User
-	result := config.DB.Where("email = ?", emailStr).First(&user)
-	if result.Error != nil {
-		if result.Error == gorm.ErrRecordNotFound {
-			c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
-			fmt.Printf("User not found: %s\n", emailStr)
-		} else {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
-			fmt.Printf("Database error: %v\n", result.Error)
-		}
-		return
-	}
-
 	// Check if the email is verified before allowing profile picture upload
 	if !user.EmailVerified {
 		c.JSON(http.StatusForbidden, gin.H{"error": "Email not verified. Please verify your email to upload a profile picture."})
-		fmt.Printf("User email not verified: %s\n", emailStr)
+		fmt.Printf("User email not verified: %s\n", user.Email)
 		return
 	}
 
@@ -83,64 +69,75 @@ func UploadProfilePicture(c *gin.Context) {
 	}
 	defer file.Close()
 
-	// Validate the file extension
-	allowedExtensions := map[string]bool{
-		".jpg":  true,
-		".jpeg": true,
-		".png":  true,
-	}
-	fileExt := filepath.Ext(handler.Filename)
-	if !allowedExtensions[fileExt] {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid file type. Only JPG, JPEG, and PNG are allowed."})
-		fmt.Printf("Invalid file type: %s\n", fileExt)
-		return
-	}
-
 	// Validate the file size (optional, already limited by ParseMultipartForm)
-	maxFileSize := int64(10 << 20) // 10MB
-	if handler.Size > maxFileSize {
+	if handler.Size > utils.MaxImageBytes {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "File size exceeds 10MB"})
 		fmt.Printf("File size exceeds limit: %d bytes\n", handler.Size)
 		return
 	}
 
-	// Define the directory to save the uploaded files
-	uploadDir := "./uploads/profile_pictures/"
-	if err := os.MkdirAll(uploadDir, os.ModePerm); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error creating upload directory"})
-		fmt.Printf("Error creating upload directory: %v\n", err)
+	data, err := io.ReadAll(io.LimitReader(file, utils.MaxImageBytes+1))
+	if err != nil || len(data) > utils.MaxImageBytes {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Error reading file"})
+		fmt.Printf("Error reading file: %v\n", err)
 		return
 	}
 
-	// Create a unique filename using the user's ID and the original file extension
-	filename := fmt.Sprintf("user_%d%s", user.ID, fileExt)
-	filePath := filepath.Join(uploadDir, filename)
-
-	// Save the file to the specified directory
-	dst, err := os.Create(filePath)
+	// Detect the real image type from its content, decode it and re-encode it into
+	// several thumbnail sizes. Re-encoding drops EXIF and other metadata.
+	processed, err := utils.ProcessProfileImage(data)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error saving file"})
-		fmt.Printf("Error creating file: %v\n", err)
+		switch err {
+		case utils.ErrUnsupportedImage, utils.ErrInvalidImage, utils.ErrImageTooLarge:
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid image: " + err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error processing image"})
+		}
+		fmt.Printf("Error processing image: %v\n", err)
 		return
 	}
-	defer dst.Close()
 
-	if _, err := io.Copy(dst, file); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error saving file"})
-		fmt.Printf("Error saving file: %v\n", err)
-		return
+	// Filenames contain a hash of the content so a new avatar never reuses a cached URL
+	ctx := c.Request.Context()
+	previous, err := userProfilePictureKeys(ctx, user.ID)
+	if err != nil {
+		fmt.Printf("Error listing previous profile pictures: %v\n", err)
+	}
+	saved := make(map[string]bool, len(processed.Variants))
+	variants := make(map[string]string, len(processed.Variants))
+	for i, variant := range processed.Variants {
+		key := fmt.Sprintf("%suser_%d_%s_%d%s", storage.ProfilePicturePrefix, user.ID, processed.Hash, utils.ProfilePictureSizes[i], processed.Extension)
+		if err := storage.Default.Put(ctx, key, bytes.NewReader(variant.Data), int64(len(variant.Data)), processed.ContentType); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error saving file"})
+			fmt.Printf("Error saving file: %v\n", err)
+			return
+		}
+		saved[key] = true
+		variants[strconv.Itoa(utils.ProfilePictureSizes[i])] = key
 	}
 
-	// Update the user's ProfilePicture field with the new file path
-	user.ProfilePicture = fmt.Sprintf("/uploads/profile_pictures/%s", filename)
-	if err := config.DB.Save(&user).Error; err != nil {
+	// Update the user's ProfilePicture field with the storage key of the largest variant
+	variantsJSON, _ := json.Marshal(variants)
+	user.ProfilePicture = variants[strconv.Itoa(utils.ProfilePictureSizes[0])]
+	user.ProfilePictureVariants = datatypes.JSON(variantsJSON)
+	if err := config.DB.Save(user).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error updating user profile"})
 		fmt.Printf("Error updating user profile: %v\n", err)
 		return
 	}
 
+	// Remove the previous avatar files once the profile points to the new ones
+	for _, old := range previous {
+		if !saved[old] {
+			if err := storage.Default.Delete(ctx, old); err != nil {
+				fmt.Printf("Error removing old profile picture %s: %v\n", old, err)
+			}
+		}
+	}
+
 	// Return a success response
-	c.JSON(http.StatusOK, gin.H{"message": "Profile picture uploaded successfully", "profile_picture": user.ProfilePicture})
+	presentUser(ctx, user)
+	c.JSON(http.StatusOK, gin.H{"message": "Profile picture uploaded successfully", "profile_picture": user.ProfilePicture, "profile_picture_variants": user.ProfilePictureVariants})
 }
 
 // GetProfile returns the profile data of the currently logged-in user
@@ -155,40 +152,369 @@ func UploadProfilePicture(c *gin.Context) {
 // @Failure 500 {object} map[string]interface{} "Database error"
 // @Router /users/profile [get]
 func GetProfile(c *gin.Context) {
-	// Retrieve the user's email from the context (set by JWT middleware)
-	email, exists := c.Get(string(middleware.UserContextKey))
-	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized: Email not found in context"})
+	// Load the authenticated user (set by JWT middleware)
+	user, ok := currentUser(c)
+	if !ok {
 		return
 	}
 
-	emailStr, ok := email.(string)
-	if !ok || emailStr == "" {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized: Invalid email in context"})
+	// Check if the email is verified
+	if !user.EmailVerified {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Email not verified. Please verify your email to view profile."})
 		return
 	}
 
-	// Find the user in the database based on email
-	var user models.User
-	result := config.DB.Where("email = ?", emailStr).First(&user)
-	if result.Error != nil {
-		if result.Error == gorm.ErrRecordNotFound {
-			c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
-		} else {
+	// Remove the password field before sending the response for security
+	user.Password = ""
+	presentUser(c.Request.Context(), user)
+
+	// Return the user's profile data as JSON
+	c.JSON(http.StatusOK, user)
+}
+
+// UpdateProfileRequest represents the fields a user may change on their profile
+type UpdateProfileRequest struct {
+	Username    *string `json:"username"`
+	PhoneNumber *string `json:"phone_number"`
+}
+
+// ChangePasswordRequest represents the body for changing the current user's password
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password"`
+	NewPassword     string `json:"new_password" binding:"required"`
+}
+
+// EmailChangeRequest represents the body for requesting an email change
+type EmailChangeRequest struct {
+	NewEmail string `json:"new_email" binding:"required,email"`
+}
+
+// EmailChangeConfirmRequest represents the body for confirming an email change
+type EmailChangeConfirmRequest struct {
+	Code string `json:"code" binding:"required"`
+}
+
+const (
+	// emailChangeCodeTTL is how long an email change code stays valid
+	emailChangeCodeTTL = 30 * time.Minute
+	// emailChangeMaxAttempts is the number of wrong codes allowed before the change is discarded
+	emailChangeMaxAttempts = 5
+)
+
+// UpdateProfile updates the username and/or phone number of the current user
+// @Summary Update user profile
+// @Description Update the username and/or phone number of the currently logged-in user. Omitted fields are left unchanged. Usernames follow the same rules as at registration and are unique regardless of case. Phone numbers are stored in E.164 format; changing the number marks it as unverified, so it cannot be used to log in until an admin verifies it again.
+// @Tags User
+// @Accept json
+// @Produce json
+// @Param profile body UpdateProfileRequest true "Profile fields to update"
+// @Success 200 {object} models.User "Updated user profile"
+// @Failure 400 {object} map[string]interface{} "Invalid request payload"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 409 {object} map[string]interface{} "Username already exists"
+// @Failure 500 {object} map[string]interface{} "Database error"
+// @Router /users/profile [patch]
+func UpdateProfile(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	var input UpdateProfileRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request payload"})
+		return
+	}
+
+	updates := map[string]interface{}{}
+	if input.Username != nil {
+		username := utils.NormalizeUsername(*input.Username)
+		if username == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Username cannot be empty"})
+			return
+		}
+		if err := utils.ValidateUsername(username); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid username: " + err.Error()})
+			return
+		}
+		var count int64
+		if err := config.DB.Model(&models.User{}).Where("LOWER(username) = ? AND id <> ?", strings.ToLower(username), user.ID).Count(&count).Error; err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+			return
+		}
+		if count > 0 {
+			c.JSON(http.StatusConflict, gin.H{"error": "Username already exists"})
+			return
 		}
+		updates["username"] = username
+	}
+	if input.PhoneNumber != nil {
+		phone := strings.TrimSpace(*input.PhoneNumber)
+		if phone != "" {
+			normalized, err := utils.NormalizePhoneNumber(phone)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid phone number"})
+				return
+			}
+			phone = normalized
+		}
+		// A new number has to be verified again before it can be used to log in
+		if phone != user.PhoneNumber {
+			updates["phone_number"] = phone
+			updates["phone_verified"] = false
+		}
+	}
+	if len(updates) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "No fields to update"})
 		return
 	}
 
-	// Check if the email is verified
-	if !user.EmailVerified {
-		c.JSON(http.StatusForbidden, gin.H{"error": "Email not verified. Please verify your email to view profile."})
+	if err := config.DB.Model(user).Updates(updates).Error; err != nil {
+		if strings.Contains(err.Error(), "duplicate key value") {
+			c.JSON(http.StatusConflict, gin.H{"error": "Username already exists"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error updating user profile"})
 		return
 	}
 
-	// Remove the password field before sending the response for security
 	user.Password = ""
-
-	// Return the user's profile data as JSON
+	presentUser(c.Request.Context(), user)
 	c.JSON(http.StatusOK, user)
 }
+
+// ChangePassword changes the current user's password after checking the current one
+// @Summary Change password
+// @Description Change the password of the currently logged-in user. The current password is required unless the account was created through OAuth and has no password yet. Other sessions of the user are logged out.
+// @Tags User
+// @Accept json
+// @Produce json
+// @Param password body ChangePasswordRequest true "Current and new password"
+// @Success 200 {object} map[string]interface{} "Password changed successfully"
+// @Failure 400 {object} PasswordPolicyError "Invalid request payload, or the new password does not meet the password policy"
+// @Failure 401 {object} map[string]interface{} "Current password is incorrect"
+// @Failure 500 {object} map[string]interface{} "Error updating password"
+// @Router /users/password [put]
+func ChangePassword(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	var input ChangePasswordRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request payload"})
+		return
+	}
+	if strings.TrimSpace(input.NewPassword) == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Password cannot be empty"})
+		return
+	}
+
+	// Akun OAuth belum memiliki password, sehingga tidak ada password lama untuk dicek
+	if user.Password != "" && !utils.CheckPasswordHash(input.CurrentPassword, user.Password) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Current password is incorrect"})
+		return
+	}
+	if !checkPasswordPolicy(c, input.NewPassword, user.Email, user.Username) {
+		return
+	}
+
+	hashedPassword, err := utils.HashPassword(input.NewPassword)
+	if err == utils.ErrPasswordTooLong {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Password is too long"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error hashing password"})
+		return
+	}
+
+	if err := config.DB.Model(user).Update("password", hashedPassword).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error updating password"})
+		return
+	}
+	// Perangkat lain yang mungkin memakai password lama harus login ulang
+	revoked, _ := session.RevokeAll(user.ID, currentSessionID(c))
+	recordAudit(c, audit.Entry{Action: audit.ActionPasswordChanged, UserID: &user.ID, Metadata: gin.H{"sessions_revoked": revoked}})
+
+	c.JSON(http.StatusOK, gin.H{"message": "Password changed successfully"})
+}
+
+// RequestEmailChange sends a confirmation code to the new email address
+// @Summary Request email change
+// @Description Start changing the email address of the current user. A 6-digit confirmation code is sent to the new address; the email is only changed after the code is confirmed. Requesting again replaces the previous code.
+// @Tags User
+// @Accept json
+// @Produce json
+// @Param email body EmailChangeRequest true "New email address"
+// @Success 200 {object} map[string]interface{} "Confirmation code sent"
+// @Failure 400 {object} map[string]interface{} "Invalid request payload"
+// @Failure 409 {object} map[string]interface{} "Email already in use"
+// @Failure 500 {object} map[string]interface{} "Error sending confirmation email"
+// @Router /users/email [post]
+func RequestEmailChange(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	var input EmailChangeRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request payload"})
+		return
+	}
+	input.NewEmail = utils.NormalizeEmail(input.NewEmail)
+	if strings.EqualFold(input.NewEmail, user.Email) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "New email is the same as the current email"})
+		return
+	}
+
+	var count int64
+	if err := config.DB.Model(&models.User{}).Where("LOWER(email) = ?", input.NewEmail).Count(&count).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+	if count > 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "Email already in use"})
+		return
+	}
+
+	code, err := utils.RandomDigits(6)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error generating confirmation code"})
+		return
+	}
+	expiresAt := time.Now().Add(emailChangeCodeTTL)
+	err = config.DB.Model(user).Updates(map[string]interface{}{
+		"pending_email":           input.NewEmail,
+		"email_change_code":       emailChangeCodeHash(user.ID, code),
+		"email_change_expires_at": expiresAt,
+		"email_change_attempts":   0,
+	}).Error
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+
+	if err := utils.SendEmailChangeCode(input.NewEmail, code); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to send confirmation email"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "A confirmation code has been sent to the new email address"})
+}
+
+// ConfirmEmailChange swaps the user's email after the code sent to the new address is confirmed
+// @Summary Confirm email change
+// @Description Confirm the pending email change with the code sent to the new address. After 5 wrong codes the pending change is discarded and a new one must be requested. The old address is notified of the change.
+// @Tags User
+// @Accept json
+// @Produce json
+// @Param code body EmailChangeConfirmRequest true "Confirmation code"
+// @Success 200 {object} map[string]interface{} "Email changed successfully"
+// @Failure 400 {object} map[string]interface{} "Invalid or expired code, or too many wrong codes"
+// @Failure 409 {object} map[string]interface{} "Email already in use"
+// @Failure 500 {object} map[string]interface{} "Error updating email"
+// @Router /users/email/verify [post]
+func ConfirmEmailChange(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	var input EmailChangeConfirmRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request payload"})
+		return
+	}
+
+	if user.PendingEmail == "" || user.EmailChangeExpiresAt == nil || time.Now().After(*user.EmailChangeExpiresAt) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "No pending email change or the code has expired"})
+		return
+	}
+
+	// Every check uses up an attempt before the code is compared, so parallel guesses
+	// cannot get past the limit either
+	result := config.DB.Model(&models.User{}).
+		Where("id = ? AND pending_email <> '' AND email_change_attempts < ?", user.ID, emailChangeMaxAttempts).
+		Update("email_change_attempts", gorm.Expr("email_change_attempts + 1"))
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+	if result.RowsAffected == 0 {
+		discardEmailChange(user)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Too many wrong codes. Please request a new email change."})
+		return
+	}
+	codeHash := emailChangeCodeHash(user.ID, strings.TrimSpace(input.Code))
+	if subtle.ConstantTimeCompare([]byte(codeHash), []byte(user.EmailChangeCode)) != 1 {
+		if user.EmailChangeAttempts+1 >= emailChangeMaxAttempts {
+			discardEmailChange(user)
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Too many wrong codes. Please request a new email change."})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid verification code"})
+		return
+	}
+
+	oldEmail, newEmail := user.Email, user.PendingEmail
+	err := config.DB.Model(user).Updates(map[string]interface{}{
+		"email":                   newEmail,
+		"email_verified":          true,
+		"pending_email":           "",
+		"email_change_code":       "",
+		"email_change_expires_at": nil,
+		"email_change_attempts":   0,
+	}).Error
+	if err != nil {
+		if strings.Contains(err.Error(), "duplicate key value") {
+			c.JSON(http.StatusConflict, gin.H{"error": "Email already in use"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error updating email"})
+		return
+	}
+
+	recordAudit(c, audit.Entry{
+		Action: audit.ActionEmailChanged,
+		UserID: &user.ID,
+		Before: gin.H{"email": oldEmail},
+		After:  gin.H{"email": newEmail},
+	})
+
+	if err := utils.SendEmailChangedNotice(oldEmail, newEmail); err != nil {
+		fmt.Printf("Failed to notify %s about email change: %v\n", oldEmail, err)
+	}
+
+	// Token lama tetap berlaku (sub berisi ID pengguna), token baru berisi email yang baru
+	// dan tetap milik sesi yang sama
+	sessionID := currentSessionID(c)
+	tokenString, err := utils.GenerateJWT(user.ID, newEmail, sessionID)
+	if err == nil && sessionID != "" {
+		err = session.Extend(sessionID)
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error generating token"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Email changed successfully", "email": newEmail, "token": tokenString})
+}
+
+// emailChangeCodeHash hashes an email change code together with the user ID, so the same
+// code of two users never has the same hash
+func emailChangeCodeHash(userID uint, code string) string {
+	return utils.HashToken(fmt.Sprintf("email-change:%d:%s", userID, code))
+}
+
+// discardEmailChange cancels the pending email change so its code can no longer be used
+func discardEmailChange(user *models.User) {
+	config.DB.Model(user).Updates(map[string]interface{}{
+		"pending_email":           "",
+		"email_change_code":       "",
+		"email_change_expires_at": nil,
+		"email_change_attempts":   0,
+	})
+}
@@ -15,181 +15,237 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/auth/github/callback": {
+        "/.well-known/jwks.json": {
             "get": {
-                "description": "This endpoint handles the callback from GitHub after the user has authenticated. It logs in the user or creates a new user account if the user does not already exist.",
+                "description": "Returns the public keys (RS256/EdDSA) that verify tokens issued by this service. The \"kid\" header of a token selects the key. Keys being rotated out stay listed until their tokens expire.",
+                "produces": [
+                    "application/json"
+                ],
                 "tags": [
-                    "OAuth"
+                    "Auth"
+                ],
+                "summary": "JSON Web Key Set",
+                "responses": {
+                    "200": {
+                        "description": "Active verification keys",
+                        "schema": {
+                            "$ref": "#/definitions/utils.JSONWebKeySet"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/audit-logs": {
+            "get": {
+                "description": "Admin only. Lists security events (logins, failed logins, linked identities, password and email changes, package selections, admin actions, ...) newest first. Filters are combined. An action ending with a dot, such as \"admin.\", matches every action with that prefix. Pass next_before from the response as \"before\" to get the next page.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin"
                 ],
-                "summary": "Handle GitHub OAuth callback",
+                "summary": "Search the audit log",
                 "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Affected user",
+                        "name": "user_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "User who performed the action",
+                        "name": "actor_id",
+                        "in": "query"
+                    },
                     {
                         "type": "string",
-                        "description": "OAuth State",
-                        "name": "state",
-                        "in": "query",
-                        "required": true
+                        "description": "Action, or action prefix ending with a dot",
+                        "name": "action",
+                        "in": "query"
                     },
                     {
                         "type": "string",
-                        "description": "OAuth Code",
-                        "name": "code",
-                        "in": "query",
-                        "required": true
+                        "description": "Client IP address",
+                        "name": "ip",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only entries at or after this time (RFC 3339)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only entries before this time (RFC 3339)",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Only entries with a smaller ID, for paging",
+                        "name": "before",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Entries per page (default 50, at most 200)",
+                        "name": "limit",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "JWT Token",
+                        "description": "Audit log entries",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "$ref": "#/definitions/controllers.AuditLogPage"
                         }
                     },
-                    "302": {
-                        "description": "Redirects to home or error page",
+                    "400": {
+                        "description": "Invalid filter",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/controllers.ErrorResponse"
                         }
                     },
-                    "400": {
-                        "description": "Invalid OAuth state or code",
+                    "403": {
+                        "description": "Admin access required",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "$ref": "#/definitions/controllers.ErrorResponse"
                         }
                     },
                     "500": {
-                        "description": "Internal server error",
+                        "description": "Database error",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "$ref": "#/definitions/controllers.ErrorResponse"
                         }
                     }
                 }
             }
         },
-        "/auth/github/login": {
+        "/admin/config": {
             "get": {
-                "description": "Redirects the user to the GitHub OAuth login page",
+                "description": "Admin only. Returns the configuration loaded at startup from defaults, the YAML config file, .env and environment variables, to check what a deployment actually uses. Passwords, client secrets, signing keys and the Redis URL are replaced by \"[REDACTED]\"; secrets that are not set stay empty. Durations are in nanoseconds.",
+                "produces": [
+                    "application/json"
+                ],
                 "tags": [
-                    "OAuth"
+                    "Admin"
                 ],
-                "summary": "Initiate GitHub OAuth",
+                "summary": "Show the running configuration",
                 "responses": {
-                    "302": {
-                        "description": "Redirects to GitHub OAuth login"
+                    "200": {
+                        "description": "Running configuration with secrets redacted",
+                        "schema": {
+                            "$ref": "#/definitions/config.Config"
+                        }
+                    },
+                    "403": {
+                        "description": "Admin access required",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
                     }
                 }
             }
         },
-        "/auth/google/callback": {
+        "/admin/users": {
             "get": {
-                "description": "This endpoint handles the callback from Google after the user has authenticated. It logs in the user or creates a new user account if the user does not already exist.",
+                "description": "Admin only. Finds users whose email or username contains q (ignoring case), or whose phone number matches q in any common format. Without q all users are listed. Pass next_before from the response as \"before\" to get the next page.",
+                "produces": [
+                    "application/json"
+                ],
                 "tags": [
-                    "OAuth"
+                    "Admin"
                 ],
-                "summary": "Handle Google OAuth callback",
+                "summary": "Search users",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "OAuth State",
-                        "name": "state",
-                        "in": "query",
-                        "required": true
+                        "description": "Part of an email or username, or a phone number",
+                        "name": "q",
+                        "in": "query"
                     },
                     {
+                        "enum": [
+                            "active",
+                            "suspended",
+                            "banned"
+                        ],
                         "type": "string",
-                        "description": "OAuth Code",
-                        "name": "code",
-                        "in": "query",
-                        "required": true
+                        "description": "Only users with this status",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Only users with a smaller ID, for paging",
+                        "name": "before",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Users per page (default 50, at most 200)",
+                        "name": "limit",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "JWT Token",
+                        "description": "Matching users",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "$ref": "#/definitions/controllers.AdminUserPage"
                         }
                     },
-                    "302": {
-                        "description": "Redirects to home or error page",
+                    "400": {
+                        "description": "Invalid filter",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/controllers.ErrorResponse"
                         }
                     },
-                    "400": {
-                        "description": "Invalid OAuth state or code",
+                    "403": {
+                        "description": "Admin access required",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "$ref": "#/definitions/controllers.ErrorResponse"
                         }
                     },
                     "500": {
-                        "description": "Internal server error",
+                        "description": "Database error",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "$ref": "#/definitions/controllers.ErrorResponse"
                         }
                     }
                 }
             }
         },
-        "/auth/google/login": {
+        "/admin/users/{id}": {
             "get": {
-                "description": "Redirects the user to the Google OAuth login page",
-                "tags": [
-                    "OAuth"
-                ],
-                "summary": "Initiate Google OAuth",
-                "responses": {
-                    "302": {
-                        "description": "Redirects to Google OAuth login"
-                    }
-                }
-            }
-        },
-        "/auth/login": {
-            "post": {
-                "description": "This endpoint allows users to log in by providing email and password. A JWT token will be returned upon successful login.",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Admin only. Shows a user with their selected package, email and phone verification, how the account was created (password or OAuth provider), linked identities, two-factor and passkey state, active sessions and account status.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Auth"
+                    "Admin"
                 ],
-                "summary": "User login",
+                "summary": "Get a user",
                 "parameters": [
                     {
-                        "description": "User credentials (email and password)",
-                        "name": "credentials",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/controllers.LoginCredentials"
-                        }
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "JWT token",
-                        "schema": {
-                            "$ref": "#/definitions/controllers.SuccessResponse"
-                        }
-                    },
-                    "400": {
-                        "description": "Invalid request payload",
+                        "description": "User details",
                         "schema": {
-                            "$ref": "#/definitions/controllers.ErrorResponse"
+                            "$ref": "#/definitions/controllers.AdminUserDetail"
                         }
                     },
-                    "401": {
-                        "description": "Unauthorized, invalid credentials or email not verified",
+                    "403": {
+                        "description": "Admin access required",
                         "schema": {
                             "$ref": "#/definitions/controllers.ErrorResponse"
                         }
@@ -201,7 +257,7 @@ const docTemplate = `{
                         }
                     },
                     "500": {
-                        "description": "Error generating token or database error",
+                        "description": "Database error",
                         "schema": {
                             "$ref": "#/definitions/controllers.ErrorResponse"
                         }
@@ -209,51 +265,52 @@ const docTemplate = `{
                 }
             }
         },
-        "/auth/register": {
-            "post": {
-                "description": "This endpoint allows users to register by providing email, username, password, and phone number. A verification email will be sent after registration.",
-                "consumes": [
-                    "application/json"
-                ],
+        "/admin/users/{id}/2fa": {
+            "delete": {
+                "description": "Admin only. Disables two-factor authentication and deletes the recovery codes of a user who lost access to their authenticator, after their identity was checked by support. The user is notified by email and can enrol again after logging in.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Auth"
+                    "Admin"
                 ],
-                "summary": "Register a new user",
+                "summary": "Reset a user's two-factor authentication",
                 "parameters": [
                     {
-                        "description": "User registration data",
-                        "name": "user",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/controllers.RegisterRequest"
-                        }
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Registration successful",
+                    "200": {
+                        "description": "Two-factor authentication reset",
                         "schema": {
                             "$ref": "#/definitions/controllers.SuccessResponse"
                         }
                     },
                     "400": {
-                        "description": "Invalid request payload or password is empty",
+                        "description": "Two-factor authentication is not enabled",
                         "schema": {
                             "$ref": "#/definitions/controllers.ErrorResponse"
                         }
                     },
-                    "409": {
-                        "description": "Email or username already exists",
+                    "403": {
+                        "description": "Admin access required",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "User not found",
                         "schema": {
                             "$ref": "#/definitions/controllers.ErrorResponse"
                         }
                     },
                     "500": {
-                        "description": "Error creating user or sending verification email",
+                        "description": "Database error",
                         "schema": {
                             "$ref": "#/definitions/controllers.ErrorResponse"
                         }
@@ -261,9 +318,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/auth/verify-email": {
+        "/admin/users/{id}/impersonate": {
             "post": {
-                "description": "This endpoint allows users to verify their email by providing the verification code sent via email.",
+                "description": "Admin only. Returns a token that sees the API as the user does, for example to check their profile or package. The token expires after 15 minutes, only allows GET requests, cannot export the user's data, and stops working if the admin loses the admin role. The token carries the admin in its \"act\" claim, and the start and every request made with it are written to the audit log with the admin as actor. Admins cannot be impersonated.",
                 "consumes": [
                     "application/json"
                 ],
@@ -271,29 +328,54 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Auth"
+                    "Admin"
                 ],
-                "summary": "Verify user email",
+                "summary": "Impersonate a user",
                 "parameters": [
                     {
-                        "description": "Email and verification code",
-                        "name": "verification",
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Reason for impersonating",
+                        "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/controllers.VerificationRequest"
+                            "$ref": "#/definitions/controllers.ImpersonateRequest"
                         }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Email verified successfully",
+                        "description": "Impersonation token",
                         "schema": {
-                            "$ref": "#/definitions/controllers.SuccessResponse"
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/controllers.ImpersonationResponse"
+                                        }
+                                    }
+                                }
+                            ]
                         }
                     },
                     "400": {
-                        "description": "Invalid request payload or verification code",
+                        "description": "Reason is required",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Admin access required, or the user is an admin",
                         "schema": {
                             "$ref": "#/definitions/controllers.ErrorResponse"
                         }
@@ -304,8 +386,14 @@ const docTemplate = `{
                             "$ref": "#/definitions/controllers.ErrorResponse"
                         }
                     },
+                    "409": {
+                        "description": "The account is suspended or banned",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    },
                     "500": {
-                        "description": "Failed to verify email",
+                        "description": "Error generating token",
                         "schema": {
                             "$ref": "#/definitions/controllers.ErrorResponse"
                         }
@@ -313,52 +401,67 @@ const docTemplate = `{
                 }
             }
         },
-        "/packages": {
-            "get": {
-                "description": "Retrieve a list of all available packages",
+        "/admin/users/{id}/lockout": {
+            "delete": {
+                "description": "Admin only. Clears the failed-login counter of a user whose password login was locked after too many failed attempts, so they can log in again right away. Per-IP limits are not affected.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Packages"
+                    "Admin"
+                ],
+                "summary": "Unlock a user's password login",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
                 ],
-                "summary": "Get all packages",
                 "responses": {
                     "200": {
-                        "description": "List of available packages",
+                        "description": "Account unlocked",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/models.Package"
-                            }
+                            "$ref": "#/definitions/controllers.SuccessResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Admin access required",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "User not found",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
                         }
                     },
                     "500": {
-                        "description": "Error fetching packages",
+                        "description": "Database error",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/controllers.ErrorResponse"
                         }
                     }
                 }
             }
         },
-        "/packages/{id}": {
+        "/admin/users/{id}/logout": {
             "post": {
-                "description": "Allows a user to select a package by its ID, updates the user's selected package",
+                "description": "Admin only. Revokes all sessions of a user, so every device has to log in again. Personal access tokens are not affected; suspend the account to stop them as well.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Packages"
+                    "Admin"
                 ],
-                "summary": "Select a package",
+                "summary": "Log a user out everywhere",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Package ID",
+                        "description": "User ID",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -366,212 +469,4009 @@ const docTemplate = `{
                 ],
                 "responses": {
                     "200": {
-                        "description": "Package selected successfully, includes user and package information",
+                        "description": "Sessions revoked, data.revoked holds the count",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "$ref": "#/definitions/controllers.SuccessResponse"
                         }
                     },
-                    "400": {
-                        "description": "Invalid package ID",
+                    "403": {
+                        "description": "Admin access required",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/controllers.ErrorResponse"
                         }
                     },
-                    "401": {
-                        "description": "Unauthorized, user not found in context",
+                    "404": {
+                        "description": "User not found",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Database error",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/users/{id}/password-reset": {
+            "post": {
+                "description": "Admin only. Emails the user the same single-use password reset link as /auth/password/forgot, replacing any earlier link. The admin never sees the link.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "Send a password reset link",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Reset link sent",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.SuccessResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Admin access required",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
                         }
                     },
                     "404": {
                         "description": "User not found",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/controllers.ErrorResponse"
                         }
                     },
                     "500": {
-                        "description": "Database error or error updating user package",
+                        "description": "Error creating or sending the reset link",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/controllers.ErrorResponse"
                         }
                     }
                 }
             }
         },
-        "/users/profile": {
-            "get": {
-                "description": "Retrieve the profile of the currently logged-in user",
+        "/admin/users/{id}/status": {
+            "put": {
+                "description": "Admin only. Suspended and banned users cannot log in, and requests with their existing tokens are rejected with 403. A suspension can end automatically at suspended_until. Suspending or banning also logs the user out of all sessions. Admins cannot change their own status.",
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "User"
+                    "Admin"
+                ],
+                "summary": "Suspend, ban or reactivate a user",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "New status",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controllers.AdminSetStatusRequest"
+                        }
+                    }
                 ],
-                "summary": "Get user profile",
                 "responses": {
                     "200": {
-                        "description": "User profile data",
+                        "description": "Status changed",
                         "schema": {
-                            "$ref": "#/definitions/models.User"
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/controllers.AdminUserSummary"
+                                        }
+                                    }
+                                }
+                            ]
                         }
                     },
-                    "401": {
-                        "description": "Unauthorized, email not found",
+                    "400": {
+                        "description": "Invalid status or suspension end",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "$ref": "#/definitions/controllers.ErrorResponse"
                         }
                     },
                     "403": {
-                        "description": "Email not verified",
+                        "description": "Admin access required, or changing your own status",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "$ref": "#/definitions/controllers.ErrorResponse"
                         }
                     },
                     "404": {
                         "description": "User not found",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "$ref": "#/definitions/controllers.ErrorResponse"
                         }
                     },
                     "500": {
                         "description": "Database error",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "$ref": "#/definitions/controllers.ErrorResponse"
                         }
                     }
                 }
             }
         },
-        "/users/profile/picture": {
+        "/admin/users/{id}/verify-email": {
             "post": {
-                "description": "Upload a profile picture for the currently logged-in user",
-                "consumes": [
-                    "multipart/form-data"
+                "description": "Admin only. Marks the email address of a user as verified, for users who cannot receive the verification email, after support confirmed they own the address.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "Verify a user's email",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
                 ],
+                "responses": {
+                    "200": {
+                        "description": "Email verified",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Email is already verified",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Admin access required",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "User not found",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Database error",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/users/{id}/verify-phone": {
+            "post": {
+                "description": "Admin only. Marks the phone number of a user as verified after support confirmed they own it, so it can be used to log in. The number is stored in normalized international form (e.g. +62812...). A phone number can only be verified on one account at a time.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "User"
+                    "Admin"
                 ],
-                "summary": "Upload profile picture",
+                "summary": "Verify a user's phone number",
                 "parameters": [
                     {
-                        "type": "file",
-                        "description": "Profile picture file (jpg, jpeg, png)",
-                        "name": "profile_picture",
-                        "in": "formData",
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
                         "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Profile picture uploaded successfully",
+                        "description": "Phone number verified",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "$ref": "#/definitions/controllers.SuccessResponse"
                         }
                     },
                     "400": {
-                        "description": "Invalid request or file type",
+                        "description": "User has no valid phone number or it is already verified",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Admin access required",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "User not found",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Phone number is verified on another account",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Database error",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/2fa/verify": {
+            "post": {
+                "description": "Completes a login that returned mfa_required by sending the mfa_token together with a TOTP code or a recovery code. Each challenge allows a limited number of attempts and expires after a few minutes. Wrong codes count towards the same account lockout as wrong passwords.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Auth"
+                ],
+                "summary": "Complete two-factor login",
+                "parameters": [
+                    {
+                        "description": "MFA challenge token and code",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controllers.TwoFactorLoginRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "JWT token",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/controllers.LoginResult"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request payload",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
                         }
                     },
                     "401": {
-                        "description": "Unauthorized or email not found",
+                        "description": "Invalid code or expired challenge",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "$ref": "#/definitions/controllers.ErrorResponse"
                         }
                     },
                     "403": {
-                        "description": "Email not verified",
+                        "description": "Account suspended or banned",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Too many failed attempts, see the Retry-After header",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
                         }
                     },
                     "500": {
-                        "description": "Internal server error",
+                        "description": "Error generating token",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "$ref": "#/definitions/controllers.ErrorResponse"
                         }
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "controllers.ErrorResponse": {
+        },
+        "/auth/login": {
+            "post": {
+                "description": "This endpoint allows users to log in with an identifier and password. The identifier may be an email address or username (both matched regardless of case) or a phone number verified by an admin; the older \"email\" field is still accepted. A JWT token will be returned upon successful login, or an MFA challenge token (mfa_required) if the account has two-factor authentication enabled. Unknown emails and wrong passwords get the same response. Repeated failures per account and per IP address, including wrong two-factor codes, slow down further attempts and then lock login temporarily; the account owner is notified by email when their account is locked.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Auth"
+                ],
+                "summary": "User login",
+                "parameters": [
+                    {
+                        "description": "User credentials (identifier and password)",
+                        "name": "credentials",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controllers.LoginCredentials"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "JWT token or MFA challenge",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/controllers.LoginResult"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request payload",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Invalid credentials, or email not verified",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Account suspended or banned",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Too many failed attempts, see the Retry-After header",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Error generating token or database error",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/magic-link": {
+            "post": {
+                "description": "Emails a single-use login link and a 6-digit code that log the user in without a password. The link points at MAGIC_LINK_URL with the token in the \"token\" query parameter; the frontend posts it to /auth/magic-link/verify. The response is the same whether or not an account exists for the email, and the email is sent in the background.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Auth"
+                ],
+                "summary": "Request a login link",
+                "parameters": [
+                    {
+                        "description": "Email address",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controllers.MagicLinkRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Login link sent if the account exists",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request payload",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/magic-link/verify": {
+            "post": {
+                "description": "Exchanges the token from a login link, or the email together with the 6-digit code, for a JWT. Both can be used only once. The first use also marks the email as verified. Accounts with two-factor authentication get an MFA challenge instead of a token.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Auth"
+                ],
+                "summary": "Log in with a link or code",
+                "parameters": [
+                    {
+                        "description": "Token, or email and code",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controllers.MagicLinkVerifyRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "JWT token or MFA challenge",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/controllers.LoginResult"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request payload",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Invalid or expired link or code",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Account suspended or banned",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Error generating token",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/passkey/login/begin": {
+            "post": {
+                "description": "Returns the PublicKeyCredentialRequestOptions for navigator.credentials.get() and a session for /auth/passkey/login/finish. No email is needed; the authenticator offers the passkeys it holds for this site.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Auth"
+                ],
+                "summary": "Start passkey login",
+                "responses": {
+                    "200": {
+                        "description": "Request options and session",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.PasskeyCeremonyResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Error starting login",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/passkey/login/finish": {
+            "post": {
+                "description": "Send the PublicKeyCredential returned by navigator.credentials.get() as the JSON body. Passkeys require user verification on the device, so no additional two-factor code is asked for.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Auth"
+                ],
+                "summary": "Finish passkey login",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Session from /auth/passkey/login/begin",
+                        "name": "session",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "JWT token",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/controllers.LoginResult"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "401": {
+                        "description": "The passkey could not be verified",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Account suspended or banned",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Error generating token",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/password/forgot": {
+            "post": {
+                "description": "Emails a single-use link for choosing a new password. The link points at PASSWORD_RESET_URL with the token in the \"token\" query parameter; the frontend posts it to /auth/password/reset. The response is the same whether or not an account exists for the email, and the email is sent in the background.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Auth"
+                ],
+                "summary": "Request a password reset link",
+                "parameters": [
+                    {
+                        "description": "Email address",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ForgotPasswordRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Reset link sent if the account exists",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request payload",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/password/reset": {
+            "post": {
+                "description": "Sets a new password using the token from a password reset link. The new password must meet the password policy; if it does not, the token stays valid so the user can try another password. A successful reset also verifies the email and lifts a failed-login lockout, and logs out all existing sessions. It does not log the user in, and two-factor authentication still applies at the next login.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Auth"
+                ],
+                "summary": "Reset password",
+                "parameters": [
+                    {
+                        "description": "Reset token and new password",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ResetPasswordRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Password reset",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request payload, or the password does not meet the password policy",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.PasswordPolicyError"
+                        }
+                    },
+                    "401": {
+                        "description": "Invalid or expired reset link",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Error updating password",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/providers": {
+            "get": {
+                "description": "List the names of the configured OAuth2/OIDC providers that can be used with /auth/{provider}/login",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "OAuth"
+                ],
+                "summary": "List OAuth providers",
+                "responses": {
+                    "200": {
+                        "description": "Provider names",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/register": {
+            "post": {
+                "description": "This endpoint allows users to register by providing email, username, password, and phone number. A verification email will be sent after registration. Emails are stored in lower case. Usernames are 3-32 letters, digits, dots, dashes and underscores with at least one letter, and are unique regardless of case. Phone numbers are stored in E.164 format (local numbers starting with 0 are taken as Indonesian) and cannot be used to log in until an admin has verified them.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Auth"
+                ],
+                "summary": "Register a new user",
+                "parameters": [
+                    {
+                        "description": "User registration data",
+                        "name": "user",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controllers.RegisterRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Registration successful",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request payload, or the password does not meet the password policy",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.PasswordPolicyError"
+                        }
+                    },
+                    "409": {
+                        "description": "Email or username already exists",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Error creating user or sending verification email",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/verify-email": {
+            "post": {
+                "description": "This endpoint allows users to verify their email by providing the verification code sent via email.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Auth"
+                ],
+                "summary": "Verify user email",
+                "parameters": [
+                    {
+                        "description": "Email and verification code",
+                        "name": "verification",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controllers.VerificationRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Email verified successfully",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request payload or verification code",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "User not found",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to verify email",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/{provider}/callback": {
+            "get": {
+                "description": "Handles the callback from the provider. For OIDC providers the ID token signature is validated against the provider JWKS. Logs in the user linked to the provider identity, or creates a new account when the verified email is not registered yet. Existing accounts must link the provider explicitly, except accounts created by the old Google and GitHub login, which are linked once by their verified email. When the flow was started from the link endpoint, the identity is linked to that user instead.",
+                "tags": [
+                    "OAuth"
+                ],
+                "summary": "Handle OAuth callback",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Provider name",
+                        "name": "provider",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "OAuth State",
+                        "name": "state",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "OAuth Code",
+                        "name": "code",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "JWT Token",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "302": {
+                        "description": "Redirects to the allow-listed redirect_uri with the token in the URL fragment",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid OAuth state or code",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "403": {
+                        "description": "Provider email not verified, or account suspended or banned",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Unknown provider",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "409": {
+                        "description": "Email belongs to an existing account, or identity linked to another user",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/{provider}/login": {
+            "get": {
+                "description": "Redirects the user to the login page of a configured OAuth2/OIDC provider (for example google or github)",
+                "tags": [
+                    "OAuth"
+                ],
+                "summary": "Initiate OAuth login",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Provider name",
+                        "name": "provider",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Where to send the browser after login; must be in OAUTH_REDIRECT_ALLOWLIST",
+                        "name": "redirect_uri",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "302": {
+                        "description": "Redirects to the provider login page"
+                    },
+                    "400": {
+                        "description": "Redirect target not allowed",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Unknown provider",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "502": {
+                        "description": "Provider discovery failed",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/organizations": {
+            "get": {
+                "description": "List the organizations the current user is a member of",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Organizations"
+                ],
+                "summary": "List organizations",
+                "responses": {
+                    "200": {
+                        "description": "Organizations",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Organization"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Database error",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Create a corporate account. The current user becomes an admin of the organization.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Organizations"
+                ],
+                "summary": "Create organization",
+                "parameters": [
+                    {
+                        "description": "Organization data",
+                        "name": "organization",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controllers.CreateOrganizationRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Organization created",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request payload",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Database error",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/organizations/{id}/lines": {
+            "get": {
+                "description": "List the employee lines managed by the organization",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Organizations"
+                ],
+                "summary": "List organization lines",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Organization ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Lines",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.OrganizationLine"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Not an organization member",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Organization not found",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/organizations/{id}/lines/import": {
+            "post": {
+                "description": "Upload a CSV with a header row containing phone_number and optionally employee_name, employee_email, package_id and monthly_spend_limit. Existing lines of the organization are updated by phone number; missing or empty columns keep their current value, and a monthly_spend_limit of 0 removes the limit. Each row is reported individually.",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Organizations"
+                ],
+                "summary": "Import employee lines from CSV",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Organization ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "file",
+                        "description": "CSV file",
+                        "name": "file",
+                        "in": "formData",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Per-row import results",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid CSV file",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Not an organization admin",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/organizations/{id}/lines/package": {
+            "post": {
+                "description": "Assign a package to multiple lines of the organization. Each assignment is charged to the line and rejected if it would exceed the line's monthly spend limit.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Organizations"
+                ],
+                "summary": "Assign package to lines",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Organization ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Lines and package",
+                        "name": "assignment",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controllers.AssignPackageRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Per-line results",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request payload",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Not an organization admin",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Package not found",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/organizations/{id}/lines/{lineId}/spend-limit": {
+            "put": {
+                "description": "Set the monthly spend limit for an employee line. Use 0 to remove the limit.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Organizations"
+                ],
+                "summary": "Update line spend limit",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Organization ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Line ID",
+                        "name": "lineId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Monthly spend limit",
+                        "name": "limit",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controllers.SpendLimitRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Spend limit updated",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request payload",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Not an organization admin",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Line not found",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/organizations/{id}/lines/{lineId}/usage": {
+            "post": {
+                "description": "Record data usage for an employee line. An optional amount is added to the line's spend for the period and is rejected if it would exceed the line's monthly spend limit.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Organizations"
+                ],
+                "summary": "Record line usage",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Organization ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Line ID",
+                        "name": "lineId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Usage data",
+                        "name": "usage",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controllers.RecordUsageRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Usage recorded",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request payload",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Not an organization admin",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Line not found",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Monthly spend limit exceeded",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/organizations/{id}/members": {
+            "post": {
+                "description": "Add an existing user to the organization as admin or member. Only organization admins may do this.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Organizations"
+                ],
+                "summary": "Add organization member",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Organization ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Member data",
+                        "name": "member",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controllers.AddOrganizationMemberRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Member added",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request payload",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Not an organization admin",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Organization or user not found",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "User is already a member",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/organizations/{id}/report": {
+            "get": {
+                "description": "Consolidated usage and spend per line for the given month, with totals for the organization",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Organizations"
+                ],
+                "summary": "Organization usage and billing report",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Organization ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Billing period in YYYY-MM format, defaults to the current month",
+                        "name": "period",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Report",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.OrganizationReport"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid period",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Not an organization member",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Organization not found",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/packages": {
+            "get": {
+                "description": "Retrieve a list of all available packages",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Packages"
+                ],
+                "summary": "Get all packages",
+                "responses": {
+                    "200": {
+                        "description": "List of available packages",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Package"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Error fetching packages",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/packages/{id}": {
+            "post": {
+                "description": "Allows a user to select a package by its ID, updates the user's selected package",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Packages"
+                ],
+                "summary": "Select a package",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Package ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Package selected successfully, includes user and package information",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid package ID",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized, user not found in context",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "User not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Database error or error updating user package",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/users/2fa": {
+            "get": {
+                "description": "Shows whether TOTP two-factor authentication is enabled and how many unused recovery codes are left",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Two-Factor"
+                ],
+                "summary": "Two-factor authentication status",
+                "responses": {
+                    "200": {
+                        "description": "Status",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/2fa/disable": {
+            "post": {
+                "description": "Disables two-factor authentication. Requires a current TOTP or recovery code, and the password if the account has one.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Two-Factor"
+                ],
+                "summary": "Disable two-factor authentication",
+                "parameters": [
+                    {
+                        "description": "Password and code",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controllers.DisableTwoFactorRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Two-factor authentication disabled",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Two-factor authentication is not enabled",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Invalid password or code",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Database error",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/2fa/enable": {
+            "post": {
+                "description": "Enables two-factor authentication after checking a code from the authenticator app, and returns one-time recovery codes. The recovery codes are only shown once.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Two-Factor"
+                ],
+                "summary": "Confirm two-factor enrolment",
+                "parameters": [
+                    {
+                        "description": "Code from the authenticator app",
+                        "name": "code",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controllers.TwoFactorCodeRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Two-factor authentication enabled, with recovery codes",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid code or setup not started",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Two-factor authentication is already enabled",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Database error",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/2fa/recovery-codes": {
+            "post": {
+                "description": "Invalidates the existing recovery codes and returns a new set. Requires a current TOTP code.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Two-Factor"
+                ],
+                "summary": "Regenerate recovery codes",
+                "parameters": [
+                    {
+                        "description": "Code from the authenticator app",
+                        "name": "code",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controllers.TwoFactorCodeRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "New recovery codes",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Two-factor authentication is not enabled",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Invalid code",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Database error",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/2fa/setup": {
+            "post": {
+                "description": "Generates a TOTP secret and returns it with an otpauth:// provisioning URI and QR code. Two-factor authentication is only enabled after a first code is confirmed at /users/2fa/enable. Calling this again before confirming replaces the secret.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Two-Factor"
+                ],
+                "summary": "Start two-factor enrolment",
+                "responses": {
+                    "200": {
+                        "description": "Secret, provisioning URI and QR code",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.TwoFactorSetupResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Two-factor authentication is already enabled",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Error generating secret",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/activity": {
+            "get": {
+                "description": "List the latest security events on the current user's account, such as logins, failed login attempts, password and email changes and actions taken by an administrator (including support viewing the account as the user), so the user can spot activity that was not theirs.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User"
+                ],
+                "summary": "Recent account activity",
+                "responses": {
+                    "200": {
+                        "description": "Recent activity, newest first",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/controllers.ActivityEntry"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Database error",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/deletion": {
+            "post": {
+                "description": "Schedule the account for deletion after a grace period (ACCOUNT_DELETION_GRACE_DAYS, default 30 days). After the grace period the personal data is anonymized and the profile picture is removed. Accounts with a password must confirm it.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User"
+                ],
+                "summary": "Request account deletion",
+                "parameters": [
+                    {
+                        "description": "Password confirmation",
+                        "name": "confirmation",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.AccountDeletionRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Deletion scheduled",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.SuccessResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Invalid password",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Deletion already scheduled",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Database error",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Cancel a scheduled account deletion during the grace period",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User"
+                ],
+                "summary": "Cancel account deletion",
+                "responses": {
+                    "200": {
+                        "description": "Deletion cancelled",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.SuccessResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "No deletion scheduled",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Database error",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/email": {
+            "post": {
+                "description": "Start changing the email address of the current user. A 6-digit confirmation code is sent to the new address; the email is only changed after the code is confirmed. Requesting again replaces the previous code.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User"
+                ],
+                "summary": "Request email change",
+                "parameters": [
+                    {
+                        "description": "New email address",
+                        "name": "email",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controllers.EmailChangeRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Confirmation code sent",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request payload",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "409": {
+                        "description": "Email already in use",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Error sending confirmation email",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/users/email/verify": {
+            "post": {
+                "description": "Confirm the pending email change with the code sent to the new address. After 5 wrong codes the pending change is discarded and a new one must be requested. The old address is notified of the change.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User"
+                ],
+                "summary": "Confirm email change",
+                "parameters": [
+                    {
+                        "description": "Confirmation code",
+                        "name": "code",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controllers.EmailChangeConfirmRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Email changed successfully",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid or expired code, or too many wrong codes",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "409": {
+                        "description": "Email already in use",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Error updating email",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/users/export": {
+            "get": {
+                "description": "Export all data held for the current user, including profile, selected package, organization memberships, linked OAuth provider accounts (provider, subject, email and link time) and other OAuth-derived data, and the profile picture. Use format=zip to receive a ZIP archive containing data.json and the picture file. With delivery=link the archive is stored privately and a signed, time-limited download URL is returned instead.",
+                "produces": [
+                    "application/json",
+                    "application/zip"
+                ],
+                "tags": [
+                    "User"
+                ],
+                "summary": "Export personal data",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "json (default) or zip",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "inline (default) or link",
+                        "name": "delivery",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Exported data",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.DataExport"
+                        }
+                    },
+                    "400": {
+                        "description": "Unsupported format",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Database error",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/identities": {
+            "get": {
+                "description": "List the OAuth provider accounts linked to the current user",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Identities"
+                ],
+                "summary": "List linked identities",
+                "responses": {
+                    "200": {
+                        "description": "Linked identities",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.UserIdentity"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Database error",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/identities/{id}": {
+            "delete": {
+                "description": "Remove a linked provider account. The last identity of an account without a password cannot be removed, because the user would no longer be able to log in.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Identities"
+                ],
+                "summary": "Unlink an identity",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Identity ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Identity unlinked",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.SuccessResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Identity not found",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Cannot remove the only login method",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/identities/{provider}/link": {
+            "post": {
+                "description": "Start the OAuth flow that links a provider account to the logged-in user. Returns the provider authorization URL and sets the state cookie, so the request must be sent with credentials and the browser must then be sent to the returned URL.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Identities"
+                ],
+                "summary": "Start linking an identity",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Provider name, see /auth/providers",
+                        "name": "provider",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Where to send the browser after linking; must be in OAUTH_REDIRECT_ALLOWLIST",
+                        "name": "redirect_uri",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Authorization URL",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Redirect target not allowed",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Unknown provider",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    },
+                    "502": {
+                        "description": "Provider discovery failed",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/passkeys": {
+            "get": {
+                "description": "List the WebAuthn passkeys registered by the current user",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Passkeys"
+                ],
+                "summary": "List passkeys",
+                "responses": {
+                    "200": {
+                        "description": "Passkeys",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.WebAuthnCredential"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Database error",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/passkeys/register/begin": {
+            "post": {
+                "description": "Returns the PublicKeyCredentialCreationOptions for navigator.credentials.create() and a session that must be passed to /users/passkeys/register/finish within a few minutes.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Passkeys"
+                ],
+                "summary": "Start passkey registration",
+                "parameters": [
+                    {
+                        "description": "Display name for the passkey",
+                        "name": "request",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.PasskeyRegistrationRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Creation options and session",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.PasskeyCeremonyResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Error starting registration",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/passkeys/register/finish": {
+            "post": {
+                "description": "Send the PublicKeyCredential returned by navigator.credentials.create() as the JSON body.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Passkeys"
+                ],
+                "summary": "Finish passkey registration",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Session from /users/passkeys/register/begin",
+                        "name": "session",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Registered passkey",
+                        "schema": {
+                            "$ref": "#/definitions/models.WebAuthnCredential"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid or expired session, or the response could not be verified",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Passkey already registered",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/passkeys/{id}": {
+            "delete": {
+                "description": "Remove a passkey. A user without a password cannot remove their last passkey or linked identity.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Passkeys"
+                ],
+                "summary": "Delete a passkey",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Passkey ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Passkey deleted",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.SuccessResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Passkey not found",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Last login method",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Error deleting passkey",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/password": {
+            "put": {
+                "description": "Change the password of the currently logged-in user. The current password is required unless the account was created through OAuth and has no password yet. Other sessions of the user are logged out.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User"
+                ],
+                "summary": "Change password",
+                "parameters": [
+                    {
+                        "description": "Current and new password",
+                        "name": "password",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ChangePasswordRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Password changed successfully",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request payload, or the new password does not meet the password policy",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.PasswordPolicyError"
+                        }
+                    },
+                    "401": {
+                        "description": "Current password is incorrect",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Error updating password",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/users/profile": {
+            "get": {
+                "description": "Retrieve the profile of the currently logged-in user",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User"
+                ],
+                "summary": "Get user profile",
+                "responses": {
+                    "200": {
+                        "description": "User profile data",
+                        "schema": {
+                            "$ref": "#/definitions/models.User"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized, email not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "403": {
+                        "description": "Email not verified",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "User not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Database error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "description": "Update the username and/or phone number of the currently logged-in user. Omitted fields are left unchanged. Usernames follow the same rules as at registration and are unique regardless of case. Phone numbers are stored in E.164 format; changing the number marks it as unverified, so it cannot be used to log in until an admin verifies it again.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User"
+                ],
+                "summary": "Update user profile",
+                "parameters": [
+                    {
+                        "description": "Profile fields to update",
+                        "name": "profile",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controllers.UpdateProfileRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Updated user profile",
+                        "schema": {
+                            "$ref": "#/definitions/models.User"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request payload",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "409": {
+                        "description": "Username already exists",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Database error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/users/profile/picture": {
+            "post": {
+                "description": "Upload a profile picture for the currently logged-in user",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User"
+                ],
+                "summary": "Upload profile picture",
+                "parameters": [
+                    {
+                        "type": "file",
+                        "description": "Profile picture file (JPEG, PNG or WebP, detected from content)",
+                        "name": "profile_picture",
+                        "in": "formData",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Profile picture uploaded successfully",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request or file type",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized or email not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "403": {
+                        "description": "Email not verified",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/users/sessions": {
+            "get": {
+                "description": "List the devices the current user is logged in on, with the login method, IP address and last activity. The session of the token used for the request is marked as current.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Sessions"
+                ],
+                "summary": "List sessions",
+                "responses": {
+                    "200": {
+                        "description": "Active sessions",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/controllers.SessionResponse"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Database error",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Log out of every session except the one making this request.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Sessions"
+                ],
+                "summary": "Revoke all other sessions",
+                "responses": {
+                    "200": {
+                        "description": "Sessions revoked, data.revoked holds the count",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.SuccessResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Database error",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/sessions/{id}": {
+            "delete": {
+                "description": "Log out of one session. Tokens of the session stop working immediately. Revoking the current session logs out of this device.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Sessions"
+                ],
+                "summary": "Revoke a session",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Session ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Session revoked",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.SuccessResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Session not found",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Database error",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/tokens": {
+            "get": {
+                "description": "List the personal access tokens of the current user, including expired ones. The tokens themselves are never shown again after creation.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Tokens"
+                ],
+                "summary": "List personal access tokens",
+                "responses": {
+                    "200": {
+                        "description": "Personal access tokens",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/controllers.PersonalAccessTokenResponse"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Database error",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Create a token that scripts can send as \"Authorization: Bearer \u003ctoken\u003e\" instead of logging in with a password. The token can only call endpoints that require one of its scopes, and is shown only in this response. Tokens expire after expires_in_days (default 90, at most 365). Tokens cannot be created with another token.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Tokens"
+                ],
+                "summary": "Create a personal access token",
+                "parameters": [
+                    {
+                        "description": "Token name, scopes and lifetime",
+                        "name": "token",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controllers.CreateTokenRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Token created",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/controllers.PersonalAccessTokenResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request payload or unknown scope",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Too many tokens",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Error creating token",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/tokens/scopes": {
+            "get": {
+                "description": "List the scopes that can be granted to a personal access token. Endpoints that do not require one of these scopes cannot be called with a token.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Tokens"
+                ],
+                "summary": "List token scopes",
+                "responses": {
+                    "200": {
+                        "description": "Available scopes",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/controllers.TokenScopeResponse"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/users/tokens/{id}": {
+            "delete": {
+                "description": "Revoke a personal access token of the current user. Requests made with it fail immediately.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Tokens"
+                ],
+                "summary": "Delete a personal access token",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Token ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Token deleted",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.SuccessResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Token not found",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Database error",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "config.AccountConfig": {
+            "type": "object",
+            "properties": {
+                "admin_emails": {
+                    "description": "AdminEmails diberi role admin saat aplikasi dijalankan, lihat seeds.SeedAdmins",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "deletion_grace_days": {
+                    "type": "integer"
+                },
+                "magic_link_url": {
+                    "description": "MagicLinkURL dan PasswordResetURL adalah halaman frontend yang menerima token di\nparameter \"token\". Jika kosong, email hanya berisi token atau kode.",
+                    "type": "string"
+                },
+                "password_reset_url": {
+                    "type": "string"
+                }
+            }
+        },
+        "config.Config": {
+            "type": "object",
+            "properties": {
+                "account": {
+                    "$ref": "#/definitions/config.AccountConfig"
+                },
+                "database": {
+                    "$ref": "#/definitions/config.DatabaseConfig"
+                },
+                "jwt": {
+                    "$ref": "#/definitions/config.JWTConfig"
+                },
+                "oauth": {
+                    "$ref": "#/definitions/config.OAuthConfig"
+                },
+                "password": {
+                    "$ref": "#/definitions/config.PasswordConfig"
+                },
+                "rate_limit": {
+                    "$ref": "#/definitions/config.RateLimitConfig"
+                },
+                "server": {
+                    "$ref": "#/definitions/config.ServerConfig"
+                },
+                "smtp": {
+                    "$ref": "#/definitions/config.SMTPConfig"
+                },
+                "storage": {
+                    "$ref": "#/definitions/config.StorageConfig"
+                },
+                "webauthn": {
+                    "$ref": "#/definitions/config.WebAuthnConfig"
+                }
+            }
+        },
+        "config.DatabaseConfig": {
+            "type": "object",
+            "properties": {
+                "host": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "password": {
+                    "type": "string"
+                },
+                "port": {
+                    "type": "integer"
+                },
+                "sslmode": {
+                    "type": "string"
+                },
+                "timezone": {
+                    "type": "string"
+                },
+                "user": {
+                    "type": "string"
+                }
+            }
+        },
+        "config.JWTConfig": {
+            "type": "object",
+            "properties": {
+                "audience": {
+                    "type": "string"
+                },
+                "issuer": {
+                    "type": "string"
+                },
+                "keys_dir": {
+                    "type": "string"
+                },
+                "signing_kid": {
+                    "type": "string"
+                }
+            }
+        },
+        "config.OAuthClientConfig": {
+            "type": "object",
+            "properties": {
+                "auth_url": {
+                    "type": "string"
+                },
+                "claim_email": {
+                    "type": "string"
+                },
+                "claim_email_verified": {
+                    "type": "string"
+                },
+                "claim_name": {
+                    "type": "string"
+                },
+                "claim_picture": {
+                    "type": "string"
+                },
+                "claim_subject": {
+                    "type": "string"
+                },
+                "client_id": {
+                    "type": "string"
+                },
+                "client_secret": {
+                    "type": "string"
+                },
+                "emails_url": {
+                    "type": "string"
+                },
+                "issuer": {
+                    "description": "Issuer dipakai untuk OIDC (discovery dan JWKS), AuthURL dan TokenURL untuk OAuth2 biasa",
+                    "type": "string"
+                },
+                "scopes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "token_url": {
+                    "type": "string"
+                },
+                "userinfo_url": {
+                    "type": "string"
+                }
+            }
+        },
+        "config.OAuthConfig": {
+            "type": "object",
+            "properties": {
+                "clients": {
+                    "description": "Clients berisi pengaturan per penyedia. Environment variable-nya diawali\nOAUTH_\u003cNAMA\u003e_, mis. OAUTH_GOOGLE_CLIENT_ID.",
+                    "type": "object",
+                    "additionalProperties": {
+                        "$ref": "#/definitions/config.OAuthClientConfig"
+                    }
+                },
+                "providers": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "redirect_allowlist": {
+                    "description": "RedirectAllowlist adalah URL frontend yang boleh menjadi tujuan redirect setelah login",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "redirect_base_url": {
+                    "type": "string"
+                }
+            }
+        },
+        "config.PasswordConfig": {
+            "type": "object",
+            "properties": {
+                "argon2_iterations": {
+                    "type": "integer"
+                },
+                "argon2_memory_kib": {
+                    "type": "integer"
+                },
+                "argon2_parallelism": {
+                    "type": "integer"
+                },
+                "breached_dir": {
+                    "type": "string"
+                },
+                "breached_min_count": {
+                    "type": "integer"
+                },
+                "max_length": {
+                    "type": "integer"
+                },
+                "min_character_classes": {
+                    "type": "integer"
+                },
+                "min_length": {
+                    "type": "integer"
+                }
+            }
+        },
+        "config.RateLimitConfig": {
+            "type": "object",
+            "properties": {
+                "policies": {
+                    "description": "Policies menimpa batas bawaan per grup route, mis. register: \"3/1h\" atau global: \"off\".\nEnvironment variable-nya RATE_LIMIT_\u003cNAMA\u003e.",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "redis_url": {
+                    "type": "string"
+                },
+                "store": {
+                    "description": "\"memory\" atau \"redis\"",
+                    "type": "string"
+                }
+            }
+        },
+        "config.S3Config": {
+            "type": "object",
+            "properties": {
+                "access_key": {
+                    "type": "string"
+                },
+                "bucket": {
+                    "type": "string"
+                },
+                "endpoint": {
+                    "type": "string"
+                },
+                "public_url": {
+                    "type": "string"
+                },
+                "region": {
+                    "type": "string"
+                },
+                "secret_key": {
+                    "type": "string"
+                },
+                "use_ssl": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "config.SMTPConfig": {
+            "type": "object",
+            "properties": {
+                "host": {
+                    "type": "string"
+                },
+                "password": {
+                    "type": "string"
+                },
+                "port": {
+                    "type": "integer"
+                },
+                "sender": {
+                    "type": "string"
+                }
+            }
+        },
+        "config.ServerConfig": {
+            "type": "object",
+            "properties": {
+                "host": {
+                    "description": "kosong berarti semua interface",
+                    "type": "string"
+                },
+                "idle_timeout": {
+                    "type": "integer"
+                },
+                "port": {
+                    "type": "integer"
+                },
+                "read_header_timeout": {
+                    "type": "integer"
+                },
+                "read_timeout": {
+                    "description": "Timeout koneksi, mis. \"30s\"; 0 berarti tanpa batas",
+                    "type": "integer"
+                },
+                "shutdown_timeout": {
+                    "description": "ShutdownTimeout adalah waktu maksimum untuk menyelesaikan permintaan yang sedang berjalan\ndan menghentikan worker saat aplikasi dimatikan",
+                    "type": "integer"
+                },
+                "tls_cert_file": {
+                    "description": "TLSCertFile dan TLSKeyFile mengaktifkan HTTPS. File dibaca ulang otomatis jika berubah,\nsehingga sertifikat bisa diperpanjang tanpa restart.",
+                    "type": "string"
+                },
+                "tls_key_file": {
+                    "type": "string"
+                },
+                "trusted_proxies": {
+                    "description": "TrustedProxies adalah IP atau CIDR reverse proxy yang boleh mengisi X-Forwarded-For.\nKosong berarti header itu diabaikan dan IP klien diambil dari koneksi, sehingga rate\nlimit dan lockout per IP tidak bisa diakali dengan header palsu.",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "write_timeout": {
+                    "type": "integer"
+                }
+            }
+        },
+        "config.StorageConfig": {
+            "type": "object",
+            "properties": {
+                "driver": {
+                    "description": "\"local\" atau \"s3\"",
+                    "type": "string"
+                },
+                "local_root": {
+                    "type": "string"
+                },
+                "public_prefixes": {
+                    "description": "PublicPrefixes adalah prefix key yang boleh diakses tanpa tanda tangan; kosong berarti\nhanya foto profil",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "s3": {
+                    "$ref": "#/definitions/config.S3Config"
+                },
+                "signing_key": {
+                    "description": "SigningKey adalah kunci HMAC untuk URL bertanda tangan milik storage lokal",
+                    "type": "string"
+                }
+            }
+        },
+        "config.WebAuthnConfig": {
+            "type": "object",
+            "properties": {
+                "rp_id": {
+                    "description": "domain tanpa skema dan port",
+                    "type": "string"
+                },
+                "rp_name": {
+                    "type": "string"
+                },
+                "rp_origins": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "controllers.AccountDeletionRequest": {
+            "type": "object",
+            "properties": {
+                "password": {
+                    "type": "string"
+                }
+            }
+        },
+        "controllers.ActivityEntry": {
+            "type": "object",
+            "properties": {
+                "action": {
+                    "type": "string"
+                },
+                "by_admin": {
+                    "description": "ByAdmin is set when an administrator acted on the account",
+                    "type": "boolean"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "ip_address": {
+                    "type": "string"
+                },
+                "method": {
+                    "description": "Method is the login method for login events, such as password or google",
+                    "type": "string"
+                },
+                "user_agent": {
+                    "type": "string"
+                }
+            }
+        },
+        "controllers.AddOrganizationMemberRequest": {
+            "type": "object",
+            "required": [
+                "email"
+            ],
+            "properties": {
+                "email": {
+                    "type": "string"
+                },
+                "role": {
+                    "type": "string",
+                    "enum": [
+                        "admin",
+                        "member"
+                    ]
+                }
+            }
+        },
+        "controllers.AdminSetStatusRequest": {
+            "type": "object",
+            "required": [
+                "status"
+            ],
+            "properties": {
+                "reason": {
+                    "description": "Reason is shown to other admins only",
+                    "type": "string",
+                    "maxLength": 500
+                },
+                "status": {
+                    "type": "string",
+                    "enum": [
+                        "active",
+                        "suspended",
+                        "banned"
+                    ],
+                    "example": "suspended"
+                },
+                "suspended_until": {
+                    "description": "SuspendedUntil ends a suspension automatically; without it the suspension lasts until reactivated",
+                    "type": "string"
+                }
+            }
+        },
+        "controllers.AdminUserDetail": {
+            "type": "object",
+            "properties": {
+                "active_sessions": {
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "deletion_scheduled_at": {
+                    "type": "string"
+                },
+                "email": {
+                    "type": "string"
+                },
+                "email_verified": {
+                    "type": "boolean"
+                },
+                "has_password": {
+                    "type": "boolean"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "identities": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.UserIdentity"
+                    }
+                },
+                "package": {
+                    "$ref": "#/definitions/models.Package"
+                },
+                "package_id": {
+                    "type": "integer"
+                },
+                "passkeys": {
+                    "type": "integer"
+                },
+                "phone_number": {
+                    "type": "string"
+                },
+                "phone_verified": {
+                    "type": "boolean"
+                },
+                "registered_with": {
+                    "description": "RegisteredWith is \"password\", or the OAuth provider the account was created with",
+                    "type": "string"
+                },
+                "role": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "status_reason": {
+                    "type": "string"
+                },
+                "suspended_until": {
+                    "type": "string"
+                },
+                "two_factor_enabled": {
+                    "type": "boolean"
+                },
+                "username": {
+                    "type": "string"
+                }
+            }
+        },
+        "controllers.AdminUserPage": {
+            "type": "object",
+            "properties": {
+                "next_before": {
+                    "description": "NextBefore is passed as \"before\" to fetch the next page; it is omitted on the last page",
+                    "type": "integer"
+                },
+                "users": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/controllers.AdminUserSummary"
+                    }
+                }
+            }
+        },
+        "controllers.AdminUserSummary": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "email": {
+                    "type": "string"
+                },
+                "email_verified": {
+                    "type": "boolean"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "package_id": {
+                    "type": "integer"
+                },
+                "phone_number": {
+                    "type": "string"
+                },
+                "phone_verified": {
+                    "type": "boolean"
+                },
+                "role": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "suspended_until": {
+                    "type": "string"
+                },
+                "username": {
+                    "type": "string"
+                }
+            }
+        },
+        "controllers.AssignPackageRequest": {
+            "type": "object",
+            "required": [
+                "line_ids",
+                "package_id"
+            ],
+            "properties": {
+                "line_ids": {
+                    "type": "array",
+                    "minItems": 1,
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "package_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "controllers.AuditLogPage": {
+            "type": "object",
+            "properties": {
+                "entries": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.AuditLog"
+                    }
+                },
+                "next_before": {
+                    "description": "NextBefore is passed as \"before\" to fetch the next page; it is omitted on the last page",
+                    "type": "integer"
+                }
+            }
+        },
+        "controllers.ChangePasswordRequest": {
+            "type": "object",
+            "required": [
+                "new_password"
+            ],
+            "properties": {
+                "current_password": {
+                    "type": "string"
+                },
+                "new_password": {
+                    "type": "string"
+                }
+            }
+        },
+        "controllers.CreateOrganizationRequest": {
+            "type": "object",
+            "required": [
+                "name"
+            ],
+            "properties": {
+                "billing_email": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "controllers.CreateTokenRequest": {
+            "type": "object",
+            "required": [
+                "name",
+                "scopes"
+            ],
+            "properties": {
+                "expires_in_days": {
+                    "type": "integer",
+                    "example": 90
+                },
+                "name": {
+                    "type": "string",
+                    "maxLength": 100
+                },
+                "scopes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "packages:read",
+                        "usage:write"
+                    ]
+                }
+            }
+        },
+        "controllers.DataExport": {
+            "type": "object",
+            "properties": {
+                "exported_at": {
+                    "type": "string"
+                },
+                "oauth": {
+                    "$ref": "#/definitions/controllers.OAuthExport"
+                },
+                "organizations": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.OrganizationMember"
+                    }
+                },
+                "package": {
+                    "$ref": "#/definitions/models.Package"
+                },
+                "profile": {
+                    "$ref": "#/definitions/models.User"
+                },
+                "profile_picture": {
+                    "$ref": "#/definitions/controllers.ProfilePictureExport"
+                }
+            }
+        },
+        "controllers.DisableTwoFactorRequest": {
+            "type": "object",
+            "required": [
+                "code"
+            ],
+            "properties": {
+                "code": {
+                    "type": "string"
+                },
+                "password": {
+                    "type": "string"
+                }
+            }
+        },
+        "controllers.EmailChangeConfirmRequest": {
+            "type": "object",
+            "required": [
+                "code"
+            ],
+            "properties": {
+                "code": {
+                    "type": "string"
+                }
+            }
+        },
+        "controllers.EmailChangeRequest": {
+            "type": "object",
+            "required": [
+                "new_email"
+            ],
+            "properties": {
+                "new_email": {
+                    "type": "string"
+                }
+            }
+        },
+        "controllers.ErrorResponse": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string"
+                }
+            }
+        },
+        "controllers.ForgotPasswordRequest": {
+            "type": "object",
+            "required": [
+                "email"
+            ],
+            "properties": {
+                "email": {
+                    "type": "string"
+                }
+            }
+        },
+        "controllers.ImpersonateRequest": {
+            "type": "object",
+            "required": [
+                "reason"
+            ],
+            "properties": {
+                "reason": {
+                    "description": "Reason is recorded in the audit log, e.g. the support ticket",
+                    "type": "string",
+                    "maxLength": 500,
+                    "example": "Ticket #1234: package not shown"
+                }
+            }
+        },
+        "controllers.ImpersonationResponse": {
+            "type": "object",
+            "properties": {
+                "expires_at": {
+                    "type": "string"
+                },
+                "token": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "controllers.LineReport": {
+            "type": "object",
+            "properties": {
+                "data_used_mb": {
+                    "type": "number"
+                },
+                "employee_email": {
+                    "type": "string"
+                },
+                "employee_name": {
+                    "type": "string"
+                },
+                "line_id": {
+                    "type": "integer"
+                },
+                "monthly_spend_limit": {
+                    "type": "number"
+                },
+                "over_limit": {
+                    "type": "boolean"
+                },
+                "package_id": {
+                    "type": "integer"
+                },
+                "phone_number": {
+                    "type": "string"
+                },
+                "total_spend": {
+                    "type": "number"
+                }
+            }
+        },
+        "controllers.LoginCredentials": {
+            "type": "object",
+            "required": [
+                "password"
+            ],
+            "properties": {
+                "email": {
+                    "description": "Email is accepted instead of identifier for older clients",
+                    "type": "string"
+                },
+                "identifier": {
+                    "description": "Identifier is an email address, username or verified phone number",
+                    "type": "string",
+                    "example": "user@example.com"
+                },
+                "password": {
+                    "type": "string"
+                }
+            }
+        },
+        "controllers.LoginResult": {
+            "type": "object",
+            "properties": {
+                "mfa_expires_at": {
+                    "type": "string"
+                },
+                "mfa_required": {
+                    "type": "boolean"
+                },
+                "mfa_token": {
+                    "type": "string"
+                },
+                "token": {
+                    "type": "string"
+                }
+            }
+        },
+        "controllers.MagicLinkRequest": {
+            "type": "object",
+            "required": [
+                "email"
+            ],
+            "properties": {
+                "email": {
+                    "type": "string"
+                }
+            }
+        },
+        "controllers.MagicLinkVerifyRequest": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string"
+                },
+                "email": {
+                    "type": "string"
+                },
+                "token": {
+                    "type": "string"
+                }
+            }
+        },
+        "controllers.OAuthExport": {
+            "type": "object",
+            "properties": {
+                "identities": {
+                    "description": "Identities are the linked provider accounts: provider, subject, email and link time",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.UserIdentity"
+                    }
+                },
+                "profile_picture_url": {
+                    "type": "string"
+                }
+            }
+        },
+        "controllers.OrganizationReport": {
+            "type": "object",
+            "properties": {
+                "lines": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/controllers.LineReport"
+                    }
+                },
+                "name": {
+                    "type": "string"
+                },
+                "organization_id": {
+                    "type": "integer"
+                },
+                "period": {
+                    "type": "string"
+                },
+                "total_data_used_mb": {
+                    "type": "number"
+                },
+                "total_lines": {
+                    "type": "integer"
+                },
+                "total_spend": {
+                    "type": "number"
+                }
+            }
+        },
+        "controllers.PasskeyCeremonyResponse": {
+            "type": "object",
+            "properties": {
+                "options": {},
+                "session": {
+                    "type": "string"
+                }
+            }
+        },
+        "controllers.PasskeyRegistrationRequest": {
+            "type": "object",
+            "properties": {
+                "name": {
+                    "type": "string",
+                    "maxLength": 64
+                }
+            }
+        },
+        "controllers.PasswordPolicyError": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string"
+                },
+                "violations": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/controllers.PasswordPolicyViolation"
+                    }
+                }
+            }
+        },
+        "controllers.PasswordPolicyViolation": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string"
+                },
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "controllers.PersonalAccessTokenResponse": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "expires_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "last_used_at": {
+                    "type": "string"
+                },
+                "last_used_ip": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "prefix": {
+                    "type": "string"
+                },
+                "scopes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "token": {
+                    "description": "Token is only returned once, right after the token was created",
+                    "type": "string"
+                }
+            }
+        },
+        "controllers.ProfilePictureExport": {
+            "type": "object",
+            "properties": {
+                "archive_entry": {
+                    "type": "string"
+                },
+                "path": {
+                    "type": "string"
+                }
+            }
+        },
+        "controllers.RecordUsageRequest": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "number",
+                    "minimum": 0
+                },
+                "data_used_mb": {
+                    "type": "number",
+                    "minimum": 0
+                },
+                "description": {
+                    "type": "string"
+                },
+                "period": {
+                    "type": "string"
+                }
+            }
+        },
+        "controllers.RegisterRequest": {
+            "type": "object",
+            "required": [
+                "email",
+                "password",
+                "username"
+            ],
+            "properties": {
+                "email": {
+                    "type": "string"
+                },
+                "password": {
+                    "type": "string"
+                },
+                "phone_number": {
+                    "type": "string"
+                },
+                "username": {
+                    "type": "string"
+                }
+            }
+        },
+        "controllers.ResetPasswordRequest": {
+            "type": "object",
+            "required": [
+                "new_password",
+                "token"
+            ],
+            "properties": {
+                "new_password": {
+                    "type": "string"
+                },
+                "token": {
+                    "type": "string"
+                }
+            }
+        },
+        "controllers.SessionResponse": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "current": {
+                    "description": "Current marks the session of the token used for this request",
+                    "type": "boolean"
+                },
+                "device_name": {
+                    "type": "string"
+                },
+                "expires_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "ip_address": {
+                    "type": "string"
+                },
+                "last_active_at": {
+                    "type": "string"
+                },
+                "method": {
+                    "description": "password, google, github, magic_link, passkey, ...",
+                    "type": "string"
+                },
+                "revoked_at": {
+                    "type": "string"
+                },
+                "user_agent": {
+                    "type": "string"
+                }
+            }
+        },
+        "controllers.SpendLimitRequest": {
             "type": "object",
             "properties": {
-                "error": {
+                "monthly_spend_limit": {
+                    "type": "number",
+                    "minimum": 0
+                }
+            }
+        },
+        "controllers.SuccessResponse": {
+            "type": "object",
+            "properties": {
+                "data": {},
+                "message": {
                     "type": "string"
                 }
             }
         },
-        "controllers.LoginCredentials": {
+        "controllers.TokenScopeResponse": {
             "type": "object",
-            "required": [
-                "email",
-                "password"
-            ],
             "properties": {
-                "email": {
+                "description": {
                     "type": "string"
                 },
-                "password": {
+                "scope": {
                     "type": "string"
                 }
             }
         },
-        "controllers.RegisterRequest": {
+        "controllers.TwoFactorCodeRequest": {
             "type": "object",
             "required": [
-                "email",
-                "password",
-                "username"
+                "code"
             ],
             "properties": {
-                "email": {
+                "code": {
+                    "type": "string"
+                }
+            }
+        },
+        "controllers.TwoFactorLoginRequest": {
+            "type": "object",
+            "required": [
+                "code",
+                "mfa_token"
+            ],
+            "properties": {
+                "code": {
                     "type": "string"
                 },
-                "password": {
+                "mfa_token": {
+                    "type": "string"
+                }
+            }
+        },
+        "controllers.TwoFactorSetupResponse": {
+            "type": "object",
+            "properties": {
+                "otpauth_url": {
                     "type": "string"
                 },
-                "phone_number": {
+                "qr_code": {
+                    "description": "PNG data URI",
                     "type": "string"
                 },
-                "username": {
+                "secret": {
                     "type": "string"
                 }
             }
         },
-        "controllers.SuccessResponse": {
+        "controllers.UpdateProfileRequest": {
             "type": "object",
             "properties": {
-                "data": {},
-                "message": {
+                "phone_number": {
+                    "type": "string"
+                },
+                "username": {
                     "type": "string"
                 }
             }
@@ -591,6 +4491,141 @@ const docTemplate = `{
                 }
             }
         },
+        "models.AuditLog": {
+            "type": "object",
+            "properties": {
+                "action": {
+                    "description": "mis. auth.login, user.password_changed",
+                    "type": "string"
+                },
+                "actor_id": {
+                    "description": "pengguna yang melakukan aksi, kosong jika anonim",
+                    "type": "integer"
+                },
+                "after": {
+                    "description": "data sesudah perubahan",
+                    "type": "object"
+                },
+                "before": {
+                    "description": "data sebelum perubahan",
+                    "type": "object"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "ip_address": {
+                    "type": "string"
+                },
+                "metadata": {
+                    "description": "keterangan tambahan, mis. metode login",
+                    "type": "object"
+                },
+                "user_agent": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "description": "akun yang terdampak",
+                    "type": "integer"
+                }
+            }
+        },
+        "models.Organization": {
+            "type": "object",
+            "properties": {
+                "billing_email": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "deleted_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "members": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.OrganizationMember"
+                    }
+                },
+                "name": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.OrganizationLine": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "deleted_at": {
+                    "type": "string"
+                },
+                "employee_email": {
+                    "type": "string"
+                },
+                "employee_name": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "monthly_spend_limit": {
+                    "description": "0 berarti tanpa batas",
+                    "type": "number"
+                },
+                "organization_id": {
+                    "type": "integer"
+                },
+                "package": {
+                    "$ref": "#/definitions/models.Package"
+                },
+                "package_id": {
+                    "type": "integer"
+                },
+                "phone_number": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.OrganizationMember": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "organization_id": {
+                    "type": "integer"
+                },
+                "role": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "user": {
+                    "$ref": "#/definitions/models.User"
+                },
+                "user_id": {
+                    "type": "integer"
+                }
+            }
+        },
         "models.Package": {
             "type": "object",
             "properties": {
@@ -636,6 +4671,13 @@ const docTemplate = `{
                 "deleted_at": {
                     "type": "string"
                 },
+                "deletion_requested_at": {
+                    "description": "Penghapusan akun dijadwalkan setelah masa tenggang",
+                    "type": "string"
+                },
+                "deletion_scheduled_at": {
+                    "type": "string"
+                },
                 "email": {
                     "type": "string"
                 },
@@ -655,11 +4697,35 @@ const docTemplate = `{
                     "type": "string"
                 },
                 "phone_number": {
+                    "description": "format E.164, mis. +6281234567890",
                     "type": "string"
                 },
+                "phone_verified": {
+                    "description": "hanya nomor terverifikasi yang bisa dipakai login",
+                    "type": "boolean"
+                },
                 "profile_picture": {
                     "type": "string"
                 },
+                "profile_picture_variants": {
+                    "description": "ukuran -\u003e URL thumbnail",
+                    "type": "object"
+                },
+                "role": {
+                    "description": "Role menentukan akses ke endpoint admin (\"user\" atau \"admin\")",
+                    "type": "string"
+                },
+                "status": {
+                    "description": "Status akun yang diatur admin. Akun yang ditangguhkan atau diblokir tidak bisa login\ndan tokennya ditolak oleh JWTMiddleware.",
+                    "type": "string"
+                },
+                "suspended_until": {
+                    "description": "kosong berarti sampai diaktifkan lagi",
+                    "type": "string"
+                },
+                "totp_enabled": {
+                    "type": "boolean"
+                },
                 "updated_at": {
                     "type": "string"
                 },
@@ -667,6 +4733,119 @@ const docTemplate = `{
                     "type": "string"
                 }
             }
+        },
+        "models.UserIdentity": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "email": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "linked_at": {
+                    "type": "string"
+                },
+                "provider": {
+                    "type": "string"
+                },
+                "subject": {
+                    "description": "ID pengguna di penyedia",
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.WebAuthnCredential": {
+            "type": "object",
+            "properties": {
+                "attestation_type": {
+                    "type": "string"
+                },
+                "backup_eligible": {
+                    "type": "boolean"
+                },
+                "backup_state": {
+                    "type": "boolean"
+                },
+                "clone_warning": {
+                    "description": "sign count mundur, kemungkinan authenticator disalin",
+                    "type": "boolean"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "last_used_at": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "sign_count": {
+                    "type": "integer"
+                },
+                "transports": {
+                    "description": "dipisahkan koma, mis. \"internal,hybrid\"",
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "utils.JSONWebKey": {
+            "type": "object",
+            "properties": {
+                "alg": {
+                    "type": "string"
+                },
+                "crv": {
+                    "type": "string"
+                },
+                "e": {
+                    "type": "string"
+                },
+                "kid": {
+                    "type": "string"
+                },
+                "kty": {
+                    "type": "string"
+                },
+                "n": {
+                    "type": "string"
+                },
+                "use": {
+                    "type": "string"
+                },
+                "x": {
+                    "type": "string"
+                }
+            }
+        },
+        "utils.JSONWebKeySet": {
+            "type": "object",
+            "properties": {
+                "keys": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/utils.JSONWebKey"
+                    }
+                }
+            }
         }
     }
 }`
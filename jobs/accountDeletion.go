@@ -0,0 +1,185 @@
+// jobs/accountDeletion.go
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/mfuadfakhruzzaki/backend-api/audit"
+	"github.com/mfuadfakhruzzaki/backend-api/config"
+	"github.com/mfuadfakhruzzaki/backend-api/lockout"
+	"github.com/mfuadfakhruzzaki/backend-api/mfa"
+	"github.com/mfuadfakhruzzaki/backend-api/models"
+	"github.com/mfuadfakhruzzaki/backend-api/storage"
+)
+
+// exportMaxAge adalah umur maksimum file ekspor data sebelum dihapus
+const exportMaxAge = time.Hour
+
+// StartAccountPurger menjalankan penghapusan akun yang masa tenggangnya sudah lewat secara berkala
+// sampai ctx dibatalkan. Channel yang dikembalikan ditutup setelah putaran yang sedang berjalan
+// selesai dan worker berhenti.
+func StartAccountPurger(ctx context.Context, interval time.Duration) <-chan struct{} {
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			if n, err := PurgeDueAccounts(); err != nil {
+				log.Printf("Account purge failed: %v", err)
+			} else if n > 0 {
+				log.Printf("Purged %d account(s) scheduled for deletion", n)
+			}
+			if err := CleanupExpiredExports(exportMaxAge); err != nil {
+				log.Printf("Export cleanup failed: %v", err)
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+	return done
+}
+
+// PurgeDueAccounts menganonimkan semua akun yang jadwal penghapusannya sudah lewat
+func PurgeDueAccounts() (int, error) {
+	var users []models.User
+	err := config.DB.
+		Where("deletion_scheduled_at IS NOT NULL AND deletion_scheduled_at <= ? AND deleted_at IS NULL", time.Now()).
+		Find(&users).Error
+	if err != nil {
+		return 0, err
+	}
+
+	purged := 0
+	for i := range users {
+		if err := PurgeAccount(&users[i]); err != nil {
+			log.Printf("Failed to purge account %d: %v", users[i].ID, err)
+			continue
+		}
+		purged++
+	}
+	return purged, nil
+}
+
+// PurgeAccount menghapus data pribadi pengguna dan file foto profilnya.
+// Baris pengguna tetap disimpan dalam bentuk anonim agar relasi lain tidak rusak.
+func PurgeAccount(user *models.User) error {
+	now := time.Now()
+
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", user.ID).Delete(&models.OrganizationMember{}).Error; err != nil {
+			return err
+		}
+		// Identitas OAuth dilepas agar akun penyedia tidak bisa lagi login ke akun anonim
+		if err := tx.Where("user_id = ?", user.ID).Delete(&models.UserIdentity{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("user_id = ?", user.ID).Delete(&models.WebAuthnCredential{}).Error; err != nil {
+			return err
+		}
+		if err := mfa.Disable(tx, user.ID); err != nil {
+			return err
+		}
+		// Tautan login dan reset password yang belum dipakai tidak boleh membuka akun anonim
+		if err := tx.Where("user_id = ?", user.ID).Delete(&models.MagicLink{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("user_id = ?", user.ID).Delete(&models.PasswordReset{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("user_id = ?", user.ID).Delete(&models.PersonalAccessToken{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("user_id = ?", user.ID).Delete(&models.Session{}).Error; err != nil {
+			return err
+		}
+		// Penghitung login gagal memakai email asli sebagai kunci
+		if err := tx.Where("key = ?", lockout.AccountKey(user.Email)).Delete(&models.LoginThrottle{}).Error; err != nil {
+			return err
+		}
+		if err := audit.ScrubUser(tx, user.ID); err != nil {
+			return err
+		}
+
+		return tx.Model(user).Updates(map[string]interface{}{
+			"email":                    fmt.Sprintf("deleted-user-%d@deleted.invalid", user.ID),
+			"username":                 fmt.Sprintf("deleted-user-%d", user.ID),
+			"password":                 "",
+			"phone_number":             "",
+			"profile_picture":          "",
+			"profile_picture_variants": nil,
+			"package_id":               nil,
+			"email_verified":           false,
+			"verification_code":        "",
+			"pending_email":            "",
+			"email_change_code":        "",
+			"email_change_expires_at":  nil,
+			"email_change_attempts":    0,
+			"web_authn_handle":         nil,
+			"deletion_scheduled_at":    nil,
+			"deleted_at":               now,
+		}).Error
+	})
+	if err != nil {
+		return err
+	}
+
+	return removeUserFiles(user.ID)
+}
+
+// removeUserFiles menghapus semua foto profil dan file ekspor milik pengguna dari storage
+func removeUserFiles(userID uint) error {
+	ctx := context.Background()
+
+	keys, err := storage.ProfilePictureKeys(ctx, storage.Default, userID)
+	if err != nil {
+		return err
+	}
+	exports, err := storage.Default.List(ctx, fmt.Sprintf("exports/user_%d_", userID))
+	if err != nil {
+		return err
+	}
+
+	for _, key := range append(keys, exports...) {
+		if err := storage.Default.Delete(ctx, key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// CleanupExpiredExports menghapus file ekspor data yang tautan unduhnya sudah kedaluwarsa
+func CleanupExpiredExports(maxAge time.Duration) error {
+	ctx := context.Background()
+	keys, err := storage.Default.List(ctx, "exports/")
+	if err != nil {
+		return err
+	}
+
+	cutoff := time.Now().Add(-maxAge).Unix()
+	for _, key := range keys {
+		// Nama file berakhiran _<unix timestamp>.<format>
+		name := strings.TrimSuffix(path.Base(key), path.Ext(key))
+		createdAt, err := strconv.ParseInt(name[strings.LastIndex(name, "_")+1:], 10, 64)
+		if err != nil || createdAt > cutoff {
+			continue
+		}
+		if err := storage.Default.Delete(ctx, key); err != nil {
+			log.Printf("Failed to delete expired export %s: %v", key, err)
+		}
+	}
+	return nil
+}
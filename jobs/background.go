@@ -0,0 +1,36 @@
+// jobs/background.go
+package jobs
+
+import (
+	"context"
+	"sync"
+)
+
+// background menghitung tugas latar belakang yang masih berjalan, mis. pengiriman email
+var background sync.WaitGroup
+
+// Go menjalankan fn di goroutine terpisah. Saat aplikasi dimatikan, Wait menunggu tugas ini
+// selesai sehingga email yang sedang dikirim tidak terpotong.
+func Go(fn func()) {
+	background.Add(1)
+	go func() {
+		defer background.Done()
+		fn()
+	}()
+}
+
+// Wait menunggu semua tugas dari Go selesai, paling lama sampai ctx berakhir
+func Wait(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		background.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,156 @@
+// lockout/lockout.go
+package lockout
+
+import (
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/mfuadfakhruzzaki/backend-api/config"
+	"github.com/mfuadfakhruzzaki/backend-api/models"
+)
+
+// Policy mengatur kapan percobaan login diperlambat dan dikunci
+type Policy struct {
+	// DelayAfter adalah jumlah kegagalan sebelum jeda mulai berlaku. Jeda dimulai dari
+	// satu detik dan berlipat dua setiap kegagalan berikutnya sampai MaxDelay.
+	DelayAfter int
+	MaxDelay   time.Duration
+	// LockAfter adalah jumlah kegagalan yang mengunci kunci selama LockDuration.
+	// Setiap kegagalan setelah itu menggandakan durasi kunci sampai MaxLockDuration.
+	LockAfter       int
+	LockDuration    time.Duration
+	MaxLockDuration time.Duration
+	// Window adalah lama tanpa kegagalan sebelum penghitung dimulai dari nol lagi
+	Window time.Duration
+}
+
+var (
+	// AccountPolicy berlaku per email, termasuk email yang tidak terdaftar, sehingga
+	// respons kunci tidak bisa dipakai untuk menebak akun yang ada
+	AccountPolicy = Policy{
+		DelayAfter:      3,
+		MaxDelay:        30 * time.Second,
+		LockAfter:       10,
+		LockDuration:    15 * time.Minute,
+		MaxLockDuration: 24 * time.Hour,
+		Window:          time.Hour,
+	}
+	// IPPolicy berlaku per alamat IP dan lebih longgar karena banyak pengguna bisa
+	// berbagi satu alamat (NAT kantor, jaringan seluler)
+	IPPolicy = Policy{
+		DelayAfter:      20,
+		MaxDelay:        30 * time.Second,
+		LockAfter:       100,
+		LockDuration:    15 * time.Minute,
+		MaxLockDuration: 6 * time.Hour,
+		Window:          time.Hour,
+	}
+)
+
+// AccountKey adalah kunci penghitung untuk satu email
+func AccountKey(email string) string {
+	return "account:" + strings.ToLower(strings.TrimSpace(email))
+}
+
+// IPKey adalah kunci penghitung untuk satu alamat IP
+func IPKey(ip string) string {
+	return "ip:" + ip
+}
+
+// Wait mengembalikan berapa lama lagi percobaan login untuk kunci-kunci ini harus ditunda,
+// nol jika boleh dicoba sekarang
+func Wait(policies map[string]Policy) (time.Duration, error) {
+	keys := make([]string, 0, len(policies))
+	for key := range policies {
+		keys = append(keys, key)
+	}
+
+	var throttles []models.LoginThrottle
+	if err := config.DB.Where("key IN ?", keys).Find(&throttles).Error; err != nil {
+		return 0, err
+	}
+
+	now := time.Now()
+	var wait time.Duration
+	for _, t := range throttles {
+		policy := policies[t.Key]
+		var until time.Time
+		if t.LastFailureAt.After(now.Add(-policy.Window)) {
+			until = t.LastFailureAt.Add(policy.delay(t.Failures))
+		}
+		if t.LockedUntil != nil && t.LockedUntil.After(until) {
+			until = *t.LockedUntil
+		}
+		if d := until.Sub(now); d > wait {
+			wait = d
+		}
+	}
+	return wait, nil
+}
+
+// RecordFailure mencatat satu kegagalan dan mengembalikan true jika kegagalan ini baru saja
+// mengunci kunci tersebut (kunci pertama dalam satu rangkaian kegagalan), untuk notifikasi.
+// Penghitung dinaikkan dengan satu upsert sehingga permintaan bersamaan tetap terhitung semua.
+func RecordFailure(key string, policy Policy) (bool, error) {
+	now := time.Now()
+	throttle := models.LoginThrottle{Key: key, Failures: 1, LastFailureAt: now}
+	err := config.DB.Clauses(
+		clause.OnConflict{
+			Columns: []clause.Column{{Name: "key"}},
+			DoUpdates: clause.Assignments(map[string]interface{}{
+				"failures": gorm.Expr("CASE WHEN login_throttles.last_failure_at < ? THEN 1 ELSE login_throttles.failures + 1 END",
+					now.Add(-policy.Window)),
+				"last_failure_at": now,
+			}),
+		},
+		clause.Returning{},
+	).Create(&throttle).Error
+	if err != nil {
+		return false, err
+	}
+
+	// Bersihkan penghitung yang sudah lama tidak aktif
+	config.DB.Where("last_failure_at < ? AND (locked_until IS NULL OR locked_until < ?)", now.Add(-policy.Window), now).
+		Delete(&models.LoginThrottle{})
+
+	if throttle.Failures < policy.LockAfter {
+		return false, nil
+	}
+	lockedUntil := now.Add(policy.lockDuration(throttle.Failures))
+	if err := config.DB.Model(&models.LoginThrottle{}).Where("id = ?", throttle.ID).
+		Update("locked_until", lockedUntil).Error; err != nil {
+		return false, err
+	}
+	return throttle.Failures == policy.LockAfter, nil
+}
+
+// Reset menghapus penghitung kunci, dipakai setelah login berhasil dan saat admin membuka kunci
+func Reset(key string) error {
+	return config.DB.Where("key = ?", key).Delete(&models.LoginThrottle{}).Error
+}
+
+func (p Policy) delay(failures int) time.Duration {
+	if failures < p.DelayAfter {
+		return 0
+	}
+	return backoff(time.Second, failures-p.DelayAfter, p.MaxDelay)
+}
+
+func (p Policy) lockDuration(failures int) time.Duration {
+	return backoff(p.LockDuration, failures-p.LockAfter, p.MaxLockDuration)
+}
+
+// backoff menghitung base * 2^n, dibatasi max
+func backoff(base time.Duration, n int, max time.Duration) time.Duration {
+	d := base
+	for i := 0; i < n && d < max; i++ {
+		d *= 2
+	}
+	if d > max {
+		d = max
+	}
+	return d
+}
@@ -1,27 +1,97 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"log"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/mfuadfakhruzzaki/backend-api/config"
 	_ "github.com/mfuadfakhruzzaki/backend-api/docs"
+	"github.com/mfuadfakhruzzaki/backend-api/jobs"
+	"github.com/mfuadfakhruzzaki/backend-api/oauth"
+	"github.com/mfuadfakhruzzaki/backend-api/passkey"
+	"github.com/mfuadfakhruzzaki/backend-api/passwordpolicy"
+	"github.com/mfuadfakhruzzaki/backend-api/ratelimit"
 	"github.com/mfuadfakhruzzaki/backend-api/routes"
 	"github.com/mfuadfakhruzzaki/backend-api/seeds"
+	"github.com/mfuadfakhruzzaki/backend-api/server"
+	"github.com/mfuadfakhruzzaki/backend-api/storage"
+	"github.com/mfuadfakhruzzaki/backend-api/utils"
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
 )
 
 func main() {
+	// Membaca dan memeriksa seluruh konfigurasi; server tidak dijalankan jika ada yang salah
+	cfg, err := config.Load()
+	if err == nil {
+		err = cfg.Validate()
+	}
+	if err != nil {
+		log.Fatalf("Invalid configuration:\n%v", err)
+	}
+	config.App = cfg
+
+	// Memuat kunci penandatangan JWT; server tidak dijalankan tanpa kunci
+	if err := utils.InitJWTKeys(cfg.JWT); err != nil {
+		log.Fatalf("Error loading JWT keys: %v", err)
+	}
+
+	// Memakai parameter Argon2id untuk hashing password
+	if err := utils.InitPasswordHashing(cfg.Password); err != nil {
+		log.Fatalf("Error configuring password hashing: %v", err)
+	}
+
+	// Memakai aturan password dan lokasi data password bocor
+	if err := passwordpolicy.Init(cfg.Password); err != nil {
+		log.Fatalf("Error loading password policy: %v", err)
+	}
+
+	// Menyiapkan backend penyimpanan file upload (lokal atau S3)
+	if err := storage.Init(cfg.Storage); err != nil {
+		log.Fatalf("Error initializing storage: %v", err)
+	}
+
+	// Memuat registry penyedia OAuth2/OIDC
+	if err := oauth.Init(cfg.OAuth); err != nil {
+		log.Fatalf("Error loading OAuth providers: %v", err)
+	}
+
+	// Menyiapkan relying party WebAuthn untuk login dengan passkey
+	if err := passkey.Init(cfg.WebAuthn); err != nil {
+		log.Fatalf("Error initializing passkeys: %v", err)
+	}
+
+	// Memilih store rate limit (memori atau Redis) dan batas per grup route
+	if err := ratelimit.Init(cfg.RateLimit); err != nil {
+		log.Fatalf("Error initializing rate limits: %v", err)
+	}
+	log.Printf("Rate limits: %s", strings.Join(ratelimit.Policies(), ", "))
+
 	// Menghubungkan ke database dan menjalankan migrasi di config.ConnectDatabase()
-	config.ConnectDatabase()
+	config.ConnectDatabase(cfg.Database)
 
-	// Menjalankan seeding data paket
+	// Menjalankan seeding data paket dan role admin dari ADMIN_EMAILS
 	seeds.SeedPackages()
+	seeds.SeedAdmins()
+
+	// Menjalankan penghapusan akun yang masa tenggangnya sudah habis; worker berhenti saat
+	// workers dibatalkan ketika aplikasi dimatikan
+	workers, stopWorkers := context.WithCancel(context.Background())
+	purgerDone := jobs.StartAccountPurger(workers, time.Hour)
 
 	// Membuat router baru dengan Gin
 	router := gin.Default()
+	// IP klien hanya diambil dari X-Forwarded-For jika permintaan datang dari proxy tepercaya
+	if err := router.SetTrustedProxies(cfg.Server.TrustedProxies); err != nil {
+		log.Fatalf("Error configuring trusted proxies: %v", err)
+	}
 
 	// Mendaftarkan semua route API
 	routes.RegisterRoutes(router)
@@ -29,16 +99,69 @@ func main() {
 	// Menambahkan log untuk semua route yang terdaftar
 	logRoutes(router)
 
-	// Menyajikan file statis dari folder 'uploads'
-	router.Static("/uploads", "./uploads")
+	// Menyajikan file upload jika memakai storage lokal
+	storage.RegisterRoutes(router)
 
 	// Menambahkan rute untuk Swagger UI
 	router.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 
-	// Menjalankan server pada port 8080
-	fmt.Println("Server berjalan pada port 8080")
-	if err := router.Run(":8080"); err != nil {
+	// Membuat server HTTP(S) dengan timeout dan sertifikat TLS dari konfigurasi
+	srv, err := server.New(cfg.Server, router)
+	if err != nil {
+		log.Fatalf("Error creating server: %v", err)
+	}
+
+	// SIGINT/SIGTERM (mis. saat rollout) memulai shutdown yang rapi
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- srv.ListenAndServe()
+	}()
+	scheme := "http"
+	if cfg.Server.TLSEnabled() {
+		scheme = "https"
+	}
+	fmt.Printf("Server berjalan pada %s://%s\n", scheme, srv.Addr())
+
+	select {
+	case err := <-serveErr:
 		log.Fatalf("Error starting server: %v", err)
+	case <-ctx.Done():
+	}
+	stop()
+
+	log.Printf("Shutting down, waiting up to %s for in-flight requests and workers", cfg.Server.ShutdownTimeout)
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.Server.ShutdownTimeout)
+	defer cancel()
+	shutdown(shutdownCtx, srv, stopWorkers, purgerDone)
+	log.Println("Server stopped")
+}
+
+// shutdown stops the application in order: the HTTP server first stops accepting connections
+// and drains in-flight requests, then background workers are stopped and awaited, and finally
+// the rate limit store and database connections are closed. Each step gives up when ctx ends.
+func shutdown(ctx context.Context, srv *server.Server, stopWorkers context.CancelFunc, purgerDone <-chan struct{}) {
+	if err := srv.Shutdown(ctx); err != nil {
+		log.Printf("Error draining HTTP connections: %v", err)
+	}
+
+	stopWorkers()
+	select {
+	case <-purgerDone:
+	case <-ctx.Done():
+		log.Println("Account purger did not stop in time")
+	}
+	if err := jobs.Wait(ctx); err != nil {
+		log.Printf("Background tasks did not finish in time: %v", err)
+	}
+
+	if err := ratelimit.Close(); err != nil {
+		log.Printf("Error closing rate limit store: %v", err)
+	}
+	if err := config.CloseDatabase(); err != nil {
+		log.Printf("Error closing database: %v", err)
 	}
 }
 
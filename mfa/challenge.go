@@ -0,0 +1,76 @@
+// mfa/challenge.go
+package mfa
+
+import (
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/mfuadfakhruzzaki/backend-api/config"
+	"github.com/mfuadfakhruzzaki/backend-api/models"
+	"github.com/mfuadfakhruzzaki/backend-api/utils"
+)
+
+const (
+	// ChallengeTTL adalah waktu yang tersedia untuk memasukkan kode 2FA setelah faktor pertama
+	ChallengeTTL = 5 * time.Minute
+	// MaxChallengeAttempts adalah jumlah percobaan kode per tantangan
+	MaxChallengeAttempts = 5
+)
+
+var ErrInvalidChallenge = errors.New("invalid or expired two-factor challenge")
+
+// NewChallenge membuat tantangan 2FA untuk pengguna yang sudah lolos faktor pertama
+// dan mengembalikan token tantangannya
+func NewChallenge(userID uint, method string) (string, time.Time, error) {
+	token, err := utils.RandomToken(32)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	challenge := models.MFAChallenge{
+		TokenHash: utils.HashToken(token),
+		UserID:    userID,
+		Method:    method,
+		ExpiresAt: time.Now().Add(ChallengeTTL),
+	}
+	if err := config.DB.Create(&challenge).Error; err != nil {
+		return "", time.Time{}, err
+	}
+
+	// Bersihkan tantangan yang tidak pernah diselesaikan
+	config.DB.Where("expires_at < ?", time.Now()).Delete(&models.MFAChallenge{})
+
+	return token, challenge.ExpiresAt, nil
+}
+
+// AttemptChallenge mencatat satu percobaan pada tantangan yang masih berlaku dan mengembalikannya.
+// Percobaan dihitung sebelum kode diperiksa sehingga batas percobaan berlaku juga untuk
+// permintaan yang berjalan bersamaan.
+func AttemptChallenge(token string) (*models.MFAChallenge, error) {
+	var challenges []models.MFAChallenge
+	err := config.DB.Model(&challenges).Clauses(clause.Returning{}).
+		Where("token_hash = ? AND expires_at > ? AND attempts < ?", utils.HashToken(token), time.Now(), MaxChallengeAttempts).
+		Update("attempts", gorm.Expr("attempts + 1")).Error
+	if err != nil {
+		return nil, err
+	}
+	if len(challenges) != 1 {
+		return nil, ErrInvalidChallenge
+	}
+	return &challenges[0], nil
+}
+
+// CompleteChallenge menghapus tantangan yang berhasil; gagal jika tantangan sudah dipakai
+func CompleteChallenge(challenge *models.MFAChallenge) error {
+	result := config.DB.Delete(&models.MFAChallenge{}, challenge.ID)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected != 1 {
+		return ErrInvalidChallenge
+	}
+	return nil
+}
@@ -0,0 +1,101 @@
+// mfa/recovery.go
+package mfa
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"math/big"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/mfuadfakhruzzaki/backend-api/config"
+	"github.com/mfuadfakhruzzaki/backend-api/models"
+)
+
+// RecoveryCodeCount adalah jumlah kode pemulihan yang dibuat sekaligus
+const RecoveryCodeCount = 10
+
+// recoveryAlphabet tidak memuat karakter yang mudah tertukar (0/o, 1/l/i)
+const recoveryAlphabet = "abcdefghjkmnpqrstuvwxyz23456789"
+
+// GenerateRecoveryCodes mengganti semua kode pemulihan pengguna dengan kode baru.
+// Kode dalam bentuk teks hanya dikembalikan sekali ini.
+func GenerateRecoveryCodes(tx *gorm.DB, userID uint) ([]string, error) {
+	if err := tx.Where("user_id = ?", userID).Delete(&models.RecoveryCode{}).Error; err != nil {
+		return nil, err
+	}
+
+	codes := make([]string, RecoveryCodeCount)
+	records := make([]models.RecoveryCode, RecoveryCodeCount)
+	for i := range codes {
+		code, err := randomRecoveryCode()
+		if err != nil {
+			return nil, err
+		}
+		codes[i] = code
+		records[i] = models.RecoveryCode{UserID: userID, CodeHash: hashRecoveryCode(code)}
+	}
+	if err := tx.Create(&records).Error; err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// UseRecoveryCode menandai kode pemulihan sebagai terpakai jika cocok dan belum pernah dipakai
+func UseRecoveryCode(userID uint, code string) (bool, error) {
+	result := config.DB.Model(&models.RecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userID, hashRecoveryCode(code)).
+		Update("used_at", time.Now())
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// RemainingRecoveryCodes menghitung kode pemulihan yang belum dipakai
+func RemainingRecoveryCodes(userID uint) int64 {
+	var count int64
+	config.DB.Model(&models.RecoveryCode{}).Where("user_id = ? AND used_at IS NULL", userID).Count(&count)
+	return count
+}
+
+// Disable mematikan 2FA dan menghapus secret, kode pemulihan serta tantangan login yang tertunda
+func Disable(tx *gorm.DB, userID uint) error {
+	if err := tx.Where("user_id = ?", userID).Delete(&models.RecoveryCode{}).Error; err != nil {
+		return err
+	}
+	if err := tx.Where("user_id = ?", userID).Delete(&models.MFAChallenge{}).Error; err != nil {
+		return err
+	}
+	return tx.Model(&models.User{}).Where("id = ?", userID).Updates(map[string]interface{}{
+		"totp_secret":    "",
+		"totp_enabled":   false,
+		"totp_last_step": 0,
+	}).Error
+}
+
+// randomRecoveryCode menghasilkan kode berbentuk "xxxxx-xxxxx". rand.Int memilih setiap
+// karakter secara seragam; byte % 31 akan lebih sering memilih karakter awal alfabet.
+func randomRecoveryCode() (string, error) {
+	b := make([]byte, 10)
+	alphabetSize := big.NewInt(int64(len(recoveryAlphabet)))
+	for i := range b {
+		n, err := rand.Int(rand.Reader, alphabetSize)
+		if err != nil {
+			return "", err
+		}
+		b[i] = recoveryAlphabet[n.Int64()]
+	}
+	return string(b[:5]) + "-" + string(b[5:]), nil
+}
+
+// hashRecoveryCode menormalkan kode (huruf kecil, tanpa spasi dan tanda hubung) lalu meng-hash-nya.
+// Kode acak sekitar 49,5 bit (10 karakter dari 31) sehingga SHA-256 cukup tanpa hash password yang lambat.
+func hashRecoveryCode(code string) string {
+	normalized := strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(code))
+	sum := sha256.Sum256([]byte(normalized))
+	return hex.EncodeToString(sum[:])
+}
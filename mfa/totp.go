@@ -0,0 +1,115 @@
+// mfa/totp.go
+package mfa
+
+import (
+	"bytes"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"image/png"
+	"strings"
+	"time"
+
+	"github.com/pquerna/otp"
+	"github.com/pquerna/otp/totp"
+
+	"github.com/mfuadfakhruzzaki/backend-api/config"
+	"github.com/mfuadfakhruzzaki/backend-api/models"
+)
+
+const (
+	// Issuer ditampilkan di aplikasi authenticator
+	Issuer = "Data Quota Tracker"
+
+	totpPeriod = 30
+	// totpSkew adalah jumlah langkah waktu sebelum/sesudah sekarang yang masih diterima
+	totpSkew = 1
+)
+
+var ErrInvalidCode = errors.New("invalid two-factor code")
+
+var totpOpts = totp.ValidateOpts{Period: totpPeriod, Digits: otp.DigitsSix, Algorithm: otp.AlgorithmSHA1}
+
+// NewKey membuat secret TOTP baru untuk akun
+func NewKey(accountName string) (*otp.Key, error) {
+	return totp.Generate(totp.GenerateOpts{
+		Issuer:      Issuer,
+		AccountName: accountName,
+		Period:      totpPeriod,
+		SecretSize:  20,
+		Digits:      otp.DigitsSix,
+		Algorithm:   otp.AlgorithmSHA1,
+	})
+}
+
+// QRCodeDataURI merender URI provisioning sebagai gambar PNG dalam bentuk data URI
+func QRCodeDataURI(key *otp.Key) (string, error) {
+	img, err := key.Image(256, 256)
+	if err != nil {
+		return "", err
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return "", err
+	}
+	return "data:image/png;base64," + base64.StdEncoding.EncodeToString(buf.Bytes()), nil
+}
+
+// matchStep mengembalikan langkah waktu yang cocok dengan kode, atau -1 jika tidak ada
+func matchStep(secret, code string, now time.Time) int64 {
+	code = strings.ReplaceAll(code, " ", "")
+	if len(code) != otp.DigitsSix.Length() {
+		return -1
+	}
+
+	current := now.Unix() / totpPeriod
+	for step := current - totpSkew; step <= current+totpSkew; step++ {
+		expected, err := totp.GenerateCodeCustom(secret, time.Unix(step*totpPeriod, 0), totpOpts)
+		if err != nil {
+			return -1
+		}
+		if subtle.ConstantTimeCompare([]byte(expected), []byte(code)) == 1 {
+			return step
+		}
+	}
+	return -1
+}
+
+// UseTOTP memeriksa kode TOTP pengguna dan mencatat langkah waktunya, sehingga kode yang
+// sama (atau kode yang lebih lama) tidak bisa dipakai ulang
+func UseTOTP(user *models.User, code string) error {
+	step := matchStep(user.TOTPSecret, code, time.Now())
+	if user.TOTPSecret == "" || step < 0 {
+		return ErrInvalidCode
+	}
+
+	result := config.DB.Model(&models.User{}).
+		Where("id = ? AND totp_last_step < ?", user.ID, step).
+		Update("totp_last_step", step)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected != 1 {
+		return ErrInvalidCode
+	}
+	user.TOTPLastStep = step
+	return nil
+}
+
+// Verify menerima kode TOTP atau kode pemulihan dan mengembalikan jenis kode yang dipakai
+func Verify(user *models.User, code string) (string, error) {
+	if err := UseTOTP(user, code); err == nil {
+		return "totp", nil
+	} else if err != ErrInvalidCode {
+		return "", err
+	}
+
+	used, err := UseRecoveryCode(user.ID, code)
+	if err != nil {
+		return "", err
+	}
+	if !used {
+		return "", ErrInvalidCode
+	}
+	return "recovery_code", nil
+}
@@ -0,0 +1,26 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/mfuadfakhruzzaki/backend-api/config"
+	"github.com/mfuadfakhruzzaki/backend-api/models"
+)
+
+// AdminMiddleware only lets users with the admin role through. It must run after JWTMiddleware.
+func AdminMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, _ := c.Get(string(UserIDContextKey))
+
+		var user models.User
+		if err := config.DB.Select("id", "role").First(&user, userID).Error; err != nil || user.Role != models.RoleAdmin {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Admin access required"})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
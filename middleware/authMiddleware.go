@@ -2,21 +2,40 @@ package middleware
 
 import (
 	"net/http"
+	"reflect"
+	"runtime"
 	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"github.com/mfuadfakhruzzaki/backend-api/audit"
+	"github.com/mfuadfakhruzzaki/backend-api/config"
+	"github.com/mfuadfakhruzzaki/backend-api/models"
+	"github.com/mfuadfakhruzzaki/backend-api/pat"
+	"github.com/mfuadfakhruzzaki/backend-api/session"
 	"github.com/mfuadfakhruzzaki/backend-api/utils"
 )
 
 type ContextKey string
 
 const (
-	UserContextKey ContextKey = "userEmail"
-	AuthHeader     string     = "Authorization"
-	BearerSchema   string     = "bearer"
+	UserContextKey   ContextKey = "userEmail"
+	UserIDContextKey ContextKey = "userID"
+	ClaimsContextKey ContextKey = "tokenClaims"
+	// ScopesContextKey holds the scopes of a personal access token. It is not set for
+	// JWTs, which carry the full rights of the user.
+	ScopesContextKey ContextKey = "tokenScopes"
+	// ImpersonatorContextKey holds the ID of the admin behind an impersonation token. The
+	// user ID in the context is still the impersonated user.
+	ImpersonatorContextKey ContextKey = "impersonatorID"
+	AuthHeader             string     = "Authorization"
+	BearerSchema           string     = "bearer"
 )
 
-// JWTMiddleware verifies the JWT token and adds the user's email to the Gin context
+// JWTMiddleware verifies the JWT token and adds the user's ID, email and token claims to the Gin context.
+// Personal access tokens are accepted as well, but only on routes that declare a scope with RequireScope.
 func JWTMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		// Retrieve the Authorization header
@@ -39,18 +58,177 @@ func JWTMiddleware() gin.HandlerFunc {
 		// Extract the token part
 		tokenString := tokenParts[1]
 
-		// Validate the token and extract the email
-		email, err := utils.ValidateToken(tokenString)
+		if pat.IsToken(tokenString) {
+			authenticatePersonalAccessToken(c, tokenString)
+			return
+		}
+
+		// Validate the token and extract the claims
+		claims, err := utils.ValidateToken(tokenString)
 		if err != nil {
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired token: " + err.Error()})
 			c.Abort()
 			return
 		}
+		userID, _ := claims.UserID()
 
-		// Store the email in the Gin context
-		c.Set(string(UserContextKey), email)
+		// Tokens of revoked sessions are rejected even though they have not expired yet.
+		// Tokens issued before sessions were recorded carry no sid and run until they expire.
+		if claims.SessionID != "" {
+			if err := session.Validate(claims.SessionID, userID, c.ClientIP()); err != nil {
+				c.JSON(http.StatusUnauthorized, gin.H{"error": "Session has been revoked or has expired. Please log in again."})
+				c.Abort()
+				return
+			}
+		}
+
+		// Store the user ID (stable across email changes), email and claims in the Gin context
+		c.Set(string(UserIDContextKey), userID)
+		c.Set(string(UserContextKey), claims.Email)
+		c.Set(string(ClaimsContextKey), claims)
+
+		if adminID, ok := claims.ImpersonatorID(); ok {
+			serveImpersonation(c, userID, adminID)
+			return
+		}
+		if rejectBlockedAccount(c, userID) {
+			return
+		}
 
 		// Proceed to the next middleware or handler
 		c.Next()
 	}
 }
+
+// authenticatePersonalAccessToken handles requests made with a personal access token
+func authenticatePersonalAccessToken(c *gin.Context, token string) {
+	record, user, err := pat.Authenticate(token, c.ClientIP())
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired personal access token"})
+		c.Abort()
+		return
+	}
+
+	// Routes are closed to tokens unless they state which scope they need
+	if !declaresScope(c) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "This endpoint cannot be used with a personal access token"})
+		c.Abort()
+		return
+	}
+	if rejectBlockedAccount(c, user.ID) {
+		return
+	}
+
+	c.Set(string(UserIDContextKey), user.ID)
+	c.Set(string(UserContextKey), user.Email)
+	c.Set(string(ScopesContextKey), pat.Scopes(record))
+	c.Next()
+}
+
+// serveImpersonation handles a request made by an admin with an impersonation token. The
+// token is read-only, stops working as soon as the admin loses the admin role, and every
+// request is written to the audit log with the admin as actor.
+func serveImpersonation(c *gin.Context, userID, adminID uint) {
+	defer func() {
+		audit.Record(audit.Entry{
+			Action:    audit.ActionImpersonationCall,
+			ActorID:   &adminID,
+			UserID:    &userID,
+			IP:        c.ClientIP(),
+			UserAgent: c.Request.UserAgent(),
+			Metadata:  gin.H{"method": c.Request.Method, "path": c.Request.URL.RequestURI(), "status": c.Writer.Status()},
+		})
+	}()
+
+	var admin models.User
+	err := config.DB.Select("id", "role", "status", "suspended_until").First(&admin, adminID).Error
+	if err != nil || admin.Role != models.RoleAdmin || admin.Blocked(time.Now()) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Impersonation token is no longer valid"})
+		c.Abort()
+		return
+	}
+	switch c.Request.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+	default:
+		c.JSON(http.StatusForbidden, gin.H{"error": "Impersonation tokens are read-only"})
+		c.Abort()
+		return
+	}
+	if rejectBlockedAccount(c, userID) {
+		return
+	}
+
+	c.Set(string(ImpersonatorContextKey), adminID)
+	c.Next()
+}
+
+// NoImpersonation closes a read-only route to impersonation tokens, for data that support
+// should not see even when acting as the user
+func NoImpersonation() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if _, impersonating := c.Get(string(ImpersonatorContextKey)); impersonating {
+			c.JSON(http.StatusForbidden, gin.H{"error": "This endpoint cannot be used while impersonating a user"})
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// rejectBlockedAccount answers with 403 and returns true if an admin suspended or banned the
+// account, so that tokens issued before that stop working right away
+func rejectBlockedAccount(c *gin.Context, userID uint) bool {
+	var user models.User
+	err := config.DB.Select("id", "status", "suspended_until").First(&user, userID).Error
+	if err == gorm.ErrRecordNotFound {
+		// Handlers answer for accounts that no longer exist
+		return false
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		c.Abort()
+		return true
+	}
+	if user.Blocked(time.Now()) {
+		c.JSON(http.StatusForbidden, gin.H{"error": user.BlockedMessage()})
+		c.Abort()
+		return true
+	}
+	return false
+}
+
+// RequireScope lets a personal access token through only if it has the scope. Requests
+// authenticated with a JWT are not restricted. Routes without RequireScope reject tokens.
+func RequireScope(scope string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		value, isToken := c.Get(string(ScopesContextKey))
+		if !isToken {
+			c.Next()
+			return
+		}
+
+		scopes, _ := value.([]string)
+		for _, granted := range scopes {
+			if granted == scope {
+				c.Next()
+				return
+			}
+		}
+		c.JSON(http.StatusForbidden, gin.H{"error": "Personal access token is missing the " + scope + " scope"})
+		c.Abort()
+	}
+}
+
+// requireScopeHandlerName is the name Gin reports for every handler created by RequireScope
+var requireScopeHandlerName = runtime.FuncForPC(reflect.ValueOf(RequireScope("")).Pointer()).Name()
+
+// declaresScope reports whether the matched route has a RequireScope handler. Gin does not
+// expose the handler chain itself, only the handler names.
+func declaresScope(c *gin.Context) bool {
+	for _, name := range c.HandlerNames() {
+		if name == requireScopeHandlerName {
+			return true
+		}
+	}
+	return false
+}
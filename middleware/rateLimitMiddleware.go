@@ -0,0 +1,62 @@
+package middleware
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/mfuadfakhruzzaki/backend-api/ratelimit"
+)
+
+// RateLimitKey chooses who a rate limit applies to
+type RateLimitKey func(c *gin.Context) string
+
+// KeyByIP limits each client IP address
+func KeyByIP(c *gin.Context) string {
+	return "ip:" + c.ClientIP()
+}
+
+// KeyByUser limits each logged-in user, falling back to the client IP before JWTMiddleware has run
+func KeyByUser(c *gin.Context) string {
+	if userID, ok := c.Get(string(UserIDContextKey)); ok {
+		return fmt.Sprintf("user:%v", userID)
+	}
+	return KeyByIP(c)
+}
+
+// RateLimit applies the named policy from the ratelimit package to each key. Every response
+// carries RateLimit-Limit, RateLimit-Remaining, RateLimit-Reset and RateLimit-Policy headers;
+// rejected requests get 429 with Retry-After. Several limits can be stacked on one route,
+// the headers then describe the last one that ran.
+func RateLimit(policy string, key RateLimitKey) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		rate, result, ok := ratelimit.Take(c.Request.Context(), policy, key(c))
+		if !ok {
+			c.Next()
+			return
+		}
+
+		c.Header("RateLimit-Limit", strconv.Itoa(rate.Limit))
+		c.Header("RateLimit-Remaining", strconv.Itoa(result.Remaining))
+		c.Header("RateLimit-Reset", strconv.Itoa(seconds(result.Reset)))
+		c.Header("RateLimit-Policy", fmt.Sprintf("%d;w=%d", rate.Limit, seconds(rate.Period)))
+
+		if !result.Allowed {
+			c.Header("Retry-After", strconv.Itoa(seconds(result.RetryAfter)))
+			c.JSON(http.StatusTooManyRequests, gin.H{"error": "Too many requests. Please try again later."})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// seconds rounds up so clients never retry too early
+func seconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
@@ -0,0 +1,39 @@
+package models
+
+import (
+	"errors"
+	"time"
+
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+// ErrAuditLogAppendOnly dikembalikan jika ada kode yang mencoba mengubah atau menghapus catatan audit
+var ErrAuditLogAppendOnly = errors.New("audit log entries cannot be changed or deleted")
+
+// AuditLog adalah catatan kejadian penting untuk keamanan. Tabel ini hanya boleh ditambah;
+// catatan tidak pernah dihapus, juga tidak saat akun dihapus. Satu-satunya perubahan adalah
+// penghapusan data pribadi saat akun dihapus (lihat audit.ScrubUser).
+type AuditLog struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	CreatedAt time.Time `gorm:"index" json:"created_at"`
+
+	Action    string         `gorm:"size:64;index;not null" json:"action"` // mis. auth.login, user.password_changed
+	ActorID   *uint          `gorm:"index" json:"actor_id,omitempty"`      // pengguna yang melakukan aksi, kosong jika anonim
+	UserID    *uint          `gorm:"index" json:"user_id,omitempty"`       // akun yang terdampak
+	IPAddress string         `gorm:"size:64;index" json:"ip_address"`
+	UserAgent string         `gorm:"size:512" json:"user_agent"`
+	Before    datatypes.JSON `json:"before,omitempty" swaggertype:"object"`   // data sebelum perubahan
+	After     datatypes.JSON `json:"after,omitempty" swaggertype:"object"`    // data sesudah perubahan
+	Metadata  datatypes.JSON `json:"metadata,omitempty" swaggertype:"object"` // keterangan tambahan, mis. metode login
+}
+
+// BeforeUpdate menolak perubahan catatan audit
+func (AuditLog) BeforeUpdate(tx *gorm.DB) error {
+	return ErrAuditLogAppendOnly
+}
+
+// BeforeDelete menolak penghapusan catatan audit
+func (AuditLog) BeforeDelete(tx *gorm.DB) error {
+	return ErrAuditLogAppendOnly
+}
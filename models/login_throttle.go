@@ -0,0 +1,17 @@
+package models
+
+import (
+	"time"
+)
+
+// LoginThrottle menghitung percobaan login yang gagal untuk satu kunci, yaitu satu email
+// ("account:<email>") atau satu alamat IP ("ip:<alamat>")
+type LoginThrottle struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+
+	Key           string     `gorm:"size:320;uniqueIndex;not null" json:"key"`
+	Failures      int        `gorm:"not null;default:0" json:"failures"`
+	LastFailureAt time.Time  `gorm:"index;not null" json:"last_failure_at"`
+	LockedUntil   *time.Time `json:"locked_until,omitempty"`
+}
@@ -0,0 +1,18 @@
+package models
+
+import (
+	"time"
+)
+
+// MagicLink adalah permintaan login tanpa password lewat email. Token pada tautan dan kode
+// pendek yang dikirim bersamanya hanya disimpan dalam bentuk hash dan hanya bisa dipakai sekali.
+type MagicLink struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+
+	UserID    uint      `gorm:"index;not null" json:"user_id"`
+	TokenHash string    `gorm:"size:64;uniqueIndex;not null" json:"-"`
+	CodeHash  string    `gorm:"size:64;not null" json:"-"`
+	Attempts  int       `gorm:"not null;default:0" json:"attempts"` // percobaan kode yang salah
+	ExpiresAt time.Time `gorm:"index;not null" json:"expires_at"`
+}
@@ -0,0 +1,28 @@
+package models
+
+import (
+	"time"
+)
+
+// RecoveryCode adalah kode pemulihan 2FA satu kali pakai. Hanya hash kodenya yang disimpan.
+type RecoveryCode struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+
+	UserID   uint       `gorm:"index;not null" json:"user_id"`
+	CodeHash string     `gorm:"size:64;not null" json:"-"`
+	UsedAt   *time.Time `json:"used_at,omitempty"`
+}
+
+// MFAChallenge adalah login yang sudah lolos faktor pertama dan menunggu kode 2FA.
+// Token tantangan hanya disimpan dalam bentuk hash.
+type MFAChallenge struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+
+	TokenHash string    `gorm:"size:64;uniqueIndex;not null" json:"-"`
+	UserID    uint      `gorm:"index;not null" json:"user_id"`
+	Method    string    `gorm:"size:32;not null" json:"method"` // metode login faktor pertama, mis. password atau google
+	Attempts  int       `gorm:"not null;default:0" json:"attempts"`
+	ExpiresAt time.Time `gorm:"index;not null" json:"expires_at"`
+}
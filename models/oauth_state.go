@@ -0,0 +1,19 @@
+package models
+
+import (
+	"time"
+)
+
+// OAuthState menyimpan state dan PKCE verifier satu kali pakai untuk setiap login OAuth
+type OAuthState struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+
+	State        string    `gorm:"size:64;uniqueIndex;not null" json:"-"`
+	Provider     string    `gorm:"size:32;not null" json:"provider"`
+	CodeVerifier string    `gorm:"size:128;not null" json:"-"`
+	Nonce        string    `gorm:"size:64" json:"-"` // dicocokkan dengan claim nonce pada ID token OIDC
+	RedirectTo   string    `json:"redirect_to,omitempty"`
+	LinkUserID   *uint     `json:"link_user_id,omitempty"` // diisi untuk alur penautan identitas
+	ExpiresAt    time.Time `gorm:"index;not null" json:"expires_at"`
+}
@@ -0,0 +1,72 @@
+package models
+
+import (
+	"time"
+)
+
+// Organization roles
+const (
+	OrganizationRoleAdmin  = "admin"
+	OrganizationRoleMember = "member"
+)
+
+// Line usage record kinds
+const (
+	UsageKindPackage = "package"
+	UsageKindData    = "data"
+)
+
+// Organization is a corporate account that manages employee lines centrally
+type Organization struct {
+	ID        uint       `gorm:"primarykey" json:"id"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
+
+	Name         string               `gorm:"not null" json:"name"`
+	BillingEmail string               `json:"billing_email"`
+	Members      []OrganizationMember `json:"members,omitempty"`
+}
+
+// OrganizationMember links a user to an organization with a role
+type OrganizationMember struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	OrganizationID uint   `gorm:"uniqueIndex:idx_organization_member;not null" json:"organization_id"`
+	UserID         uint   `gorm:"uniqueIndex:idx_organization_member;not null" json:"user_id"`
+	Role           string `gorm:"size:20;not null;default:member" json:"role"`
+	User           *User  `json:"user,omitempty"`
+}
+
+// OrganizationLine is an employee SIM managed by an organization
+type OrganizationLine struct {
+	ID        uint       `gorm:"primarykey" json:"id"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
+
+	OrganizationID    uint     `gorm:"index;not null" json:"organization_id"`
+	EmployeeName      string   `json:"employee_name"`
+	EmployeeEmail     string   `json:"employee_email"`
+	PhoneNumber       string   `gorm:"uniqueIndex;not null" json:"phone_number"`
+	PackageID         *uint    `json:"package_id,omitempty"`
+	Package           *Package `json:"package,omitempty"`
+	MonthlySpendLimit float64  `gorm:"default:0" json:"monthly_spend_limit"` // 0 berarti tanpa batas
+}
+
+// LineUsageRecord stores a billable event (package purchase or data usage) for a line
+type LineUsageRecord struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+
+	OrganizationID uint    `gorm:"index;not null" json:"organization_id"`
+	LineID         uint    `gorm:"index;not null" json:"line_id"`
+	Period         string  `gorm:"size:7;index;not null" json:"period"` // format YYYY-MM
+	Kind           string  `gorm:"size:20;not null" json:"kind"`
+	PackageID      *uint   `json:"package_id,omitempty"`
+	DataUsedMB     float64 `json:"data_used_mb"`
+	Amount         float64 `json:"amount"`
+	Description    string  `json:"description,omitempty"`
+}
@@ -0,0 +1,16 @@
+package models
+
+import (
+	"time"
+)
+
+// PasswordReset adalah permintaan reset password lewat email. Tokennya hanya disimpan
+// dalam bentuk hash dan hanya bisa dipakai sekali.
+type PasswordReset struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+
+	UserID    uint      `gorm:"index;not null" json:"user_id"`
+	TokenHash string    `gorm:"size:64;uniqueIndex;not null" json:"-"`
+	ExpiresAt time.Time `gorm:"index;not null" json:"expires_at"`
+}
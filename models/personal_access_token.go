@@ -0,0 +1,44 @@
+package models
+
+import (
+	"time"
+)
+
+// PersonalAccessToken adalah token API milik pengguna untuk skrip dan integrasi. Tokennya
+// hanya disimpan dalam bentuk hash; Prefix disimpan agar pengguna bisa mengenali tokennya.
+type PersonalAccessToken struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+
+	UserID     uint       `gorm:"index;not null" json:"-"`
+	Name       string     `gorm:"size:100;not null" json:"name"`
+	Prefix     string     `gorm:"size:16;not null" json:"prefix"`
+	TokenHash  string     `gorm:"size:64;uniqueIndex;not null" json:"-"`
+	Scopes     string     `gorm:"size:512;not null" json:"-"` // dipisahkan koma
+	ExpiresAt  time.Time  `gorm:"index;not null" json:"expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	LastUsedIP string     `gorm:"size:64" json:"last_used_ip,omitempty"`
+}
+
+// Scope yang bisa diberikan ke personal access token. Route yang tidak meminta salah satu
+// scope ini tidak bisa dipanggil dengan personal access token sama sekali.
+const (
+	ScopeProfileRead        = "profile:read"
+	ScopePackagesRead       = "packages:read"
+	ScopePackagesWrite      = "packages:write"
+	ScopeOrganizationsRead  = "organizations:read"
+	ScopeOrganizationsWrite = "organizations:write"
+	ScopeUsageRead          = "usage:read"
+	ScopeUsageWrite         = "usage:write"
+)
+
+// TokenScopes menjelaskan setiap scope, ditampilkan saat membuat token
+var TokenScopes = map[string]string{
+	ScopeProfileRead:        "Read your profile",
+	ScopePackagesRead:       "List data packages",
+	ScopePackagesWrite:      "Select a data package for your account",
+	ScopeOrganizationsRead:  "List your organizations, their members and lines",
+	ScopeOrganizationsWrite: "Create organizations, add members, import lines, assign packages and set spend limits",
+	ScopeUsageRead:          "Read organization usage and billing reports",
+	ScopeUsageWrite:         "Record line usage",
+}
@@ -0,0 +1,22 @@
+package models
+
+import (
+	"time"
+)
+
+// Session adalah satu login pengguna di satu perangkat. Token akses membawa PublicID di
+// claim "sid", sehingga token dari sesi yang dicabut langsung ditolak.
+type Session struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+
+	UserID       uint       `gorm:"index;not null" json:"-"`
+	PublicID     string     `gorm:"size:64;uniqueIndex;not null" json:"-"`
+	Method       string     `gorm:"size:32;not null" json:"method"` // password, google, github, magic_link, passkey, ...
+	DeviceName   string     `gorm:"size:100" json:"device_name"`
+	UserAgent    string     `gorm:"size:512" json:"user_agent"`
+	IPAddress    string     `gorm:"size:64" json:"ip_address"`
+	LastActiveAt time.Time  `json:"last_active_at"`
+	ExpiresAt    time.Time  `gorm:"index;not null" json:"expires_at"`
+	RevokedAt    *time.Time `json:"revoked_at,omitempty"`
+}
@@ -2,6 +2,8 @@ package models
 
 import (
 	"time"
+
+	"gorm.io/datatypes"
 )
 
 type User struct {
@@ -13,10 +15,81 @@ type User struct {
     Email           string      `gorm:"uniqueIndex;not null" json:"email"`
     Username        string      `gorm:"uniqueIndex;not null" json:"username"`
     Password        string      `gorm:"not null" json:"password,omitempty"`
-    PhoneNumber     string      `json:"phone_number"`
+    PhoneNumber     string      `json:"phone_number"` // format E.164, mis. +6281234567890
+    PhoneVerified   bool        `gorm:"default:false" json:"phone_verified"` // hanya nomor terverifikasi yang bisa dipakai login
     ProfilePicture  string      `json:"profile_picture"`
+    ProfilePictureVariants datatypes.JSON `json:"profile_picture_variants,omitempty" swaggertype:"object"` // ukuran -> URL thumbnail
     PackageID       *uint       `json:"package_id,omitempty"`
     Package         Package     `json:"package,omitempty"`
     EmailVerified   bool        `gorm:"default:false" json:"email_verified"`
     VerificationCode string     `gorm:"size:6" json:"-"`
+
+    // LegacyOAuth menandai akun yang dibuat login Google/GitHub sebelum identitas OAuth
+    // dicatat. Akun ini boleh dihubungkan sekali lewat email terverifikasi dari provider.
+    LegacyOAuth bool `gorm:"column:legacy_oauth;default:false" json:"-"`
+
+    // Perubahan email menunggu konfirmasi kode yang dikirim ke alamat baru. Yang disimpan
+    // hanya hash kodenya; perubahan dibatalkan setelah terlalu banyak kode salah.
+    PendingEmail         string     `json:"-"`
+    EmailChangeCode      string     `gorm:"size:64" json:"-"`
+    EmailChangeExpiresAt *time.Time `json:"-"`
+    EmailChangeAttempts  int        `gorm:"not null;default:0" json:"-"`
+
+    // Penghapusan akun dijadwalkan setelah masa tenggang
+    DeletionRequestedAt *time.Time `json:"deletion_requested_at,omitempty"`
+    DeletionScheduledAt *time.Time `gorm:"index" json:"deletion_scheduled_at,omitempty"`
+
+    // Role menentukan akses ke endpoint admin ("user" atau "admin")
+    Role string `gorm:"size:16;not null;default:user" json:"role"`
+
+    // Status akun yang diatur admin. Akun yang ditangguhkan atau diblokir tidak bisa login
+    // dan tokennya ditolak oleh JWTMiddleware.
+    Status         string     `gorm:"size:16;not null;default:active;index" json:"status"`
+    StatusReason   string     `gorm:"size:500" json:"-"`
+    SuspendedUntil *time.Time `json:"suspended_until,omitempty"` // kosong berarti sampai diaktifkan lagi
+
+    // Autentikasi dua faktor (TOTP). TOTPSecret sudah diisi sejak setup, tetapi baru
+    // dipakai saat login setelah TOTPEnabled dikonfirmasi dengan kode pertama.
+    TOTPSecret   string `json:"-"`
+    TOTPEnabled  bool   `gorm:"default:false" json:"totp_enabled"`
+    TOTPLastStep int64  `gorm:"default:0" json:"-"` // langkah waktu kode terakhir yang dipakai, mencegah replay
+
+    // WebAuthnHandle adalah user handle acak untuk passkey; dibuat saat passkey pertama didaftarkan
+    WebAuthnHandle []byte `gorm:"uniqueIndex" json:"-"`
+}
+
+// Nilai Role yang dikenal
+const (
+    RoleUser  = "user"
+    RoleAdmin = "admin"
+)
+
+// Nilai Status yang dikenal
+const (
+    StatusActive    = "active"
+    StatusSuspended = "suspended"
+    StatusBanned    = "banned"
+)
+
+// Blocked menandai akun yang tidak boleh memakai API: diblokir, atau ditangguhkan dan masa
+// penangguhannya belum berakhir
+func (u *User) Blocked(now time.Time) bool {
+    switch u.Status {
+    case StatusBanned:
+        return true
+    case StatusSuspended:
+        return u.SuspendedUntil == nil || now.Before(*u.SuspendedUntil)
+    }
+    return false
+}
+
+// BlockedMessage menjelaskan kepada pengguna kenapa akunnya tidak bisa dipakai
+func (u *User) BlockedMessage() string {
+    if u.Status == StatusSuspended && u.SuspendedUntil != nil {
+        return "This account is suspended until " + u.SuspendedUntil.UTC().Format(time.RFC3339) + "."
+    }
+    if u.Status == StatusSuspended {
+        return "This account is suspended. Please contact support."
+    }
+    return "This account has been banned."
 }
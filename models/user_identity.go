@@ -0,0 +1,18 @@
+package models
+
+import (
+	"time"
+)
+
+// UserIdentity adalah akun penyedia OAuth yang ditautkan ke pengguna
+type UserIdentity struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	UserID   uint      `gorm:"index;not null" json:"user_id"`
+	Provider string    `gorm:"size:32;uniqueIndex:idx_identity_provider_subject;not null" json:"provider"`
+	Subject  string    `gorm:"uniqueIndex:idx_identity_provider_subject;not null" json:"subject"` // ID pengguna di penyedia
+	Email    string    `json:"email"`
+	LinkedAt time.Time `json:"linked_at"`
+}
@@ -0,0 +1,40 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/datatypes"
+)
+
+// WebAuthnCredential adalah passkey (kredensial WebAuthn) yang terdaftar untuk pengguna
+type WebAuthnCredential struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	UserID          uint       `gorm:"index;not null" json:"user_id"`
+	Name            string     `gorm:"size:64" json:"name"`
+	CredentialID    []byte     `gorm:"uniqueIndex;not null" json:"-"`
+	PublicKey       []byte     `gorm:"not null" json:"-"` // COSE public key
+	AttestationType string     `gorm:"size:32" json:"attestation_type"`
+	Transports      string     `json:"transports"` // dipisahkan koma, mis. "internal,hybrid"
+	AAGUID          []byte     `json:"-"`
+	SignCount       uint32     `gorm:"not null;default:0" json:"sign_count"`
+	BackupEligible  bool       `json:"backup_eligible"`
+	BackupState     bool       `json:"backup_state"`
+	CloneWarning    bool       `gorm:"default:false" json:"clone_warning"` // sign count mundur, kemungkinan authenticator disalin
+	LastUsedAt      *time.Time `json:"last_used_at,omitempty"`
+}
+
+// WebAuthnSession menyimpan challenge satu kali pakai untuk satu ceremony registrasi atau login
+type WebAuthnSession struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+
+	TokenHash string         `gorm:"size:64;uniqueIndex;not null" json:"-"`
+	Ceremony  string         `gorm:"size:16;not null" json:"ceremony"` // "registration" atau "login"
+	UserID    *uint          `json:"user_id,omitempty"`                // kosong untuk login dengan passkey yang ditemukan authenticator
+	Name      string         `gorm:"size:64" json:"name,omitempty"`    // nama passkey baru saat registrasi
+	Data      datatypes.JSON `gorm:"not null" json:"-"`                // webauthn.SessionData
+	ExpiresAt time.Time      `gorm:"index;not null" json:"expires_at"`
+}
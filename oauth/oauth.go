@@ -4,119 +4,241 @@ package oauth
 import (
 	"context"
 	"encoding/json"
-	"io/ioutil"
-	"log"
+	"errors"
+	"fmt"
+	"io"
 	"net/http"
-	"os"
+	"strconv"
+	"strings"
+	"sync"
 
-	"github.com/joho/godotenv"
+	"github.com/coreos/go-oidc/v3/oidc"
 	"golang.org/x/oauth2"
-	"golang.org/x/oauth2/github"
-	"golang.org/x/oauth2/google"
 )
 
-var (
-    GoogleOauthConfig *oauth2.Config
-    GithubOauthConfig *oauth2.Config
-    OauthStateString  = "random" // Sebaiknya gunakan string acak yang aman
-)
+// ClaimMapping menentukan nama claim (boleh bertitik untuk objek bersarang, mis. "data.email")
+// yang dipakai untuk membentuk Profile
+type ClaimMapping struct {
+	Subject       string
+	Email         string
+	EmailVerified string
+	Name          string
+	Picture       string
+}
+
+// Profile adalah identitas pengguna dari penyedia, sudah dipetakan ke bentuk yang seragam
+type Profile struct {
+	Provider      string
+	Subject       string
+	Email         string
+	EmailVerified bool
+	Name          string
+	Picture       string
+}
+
+// Provider adalah satu penyedia OAuth2/OIDC yang terdaftar di registry.
+// Penyedia dengan Issuer memakai discovery OIDC dan ID token diverifikasi lewat JWKS;
+// penyedia OAuth2 biasa memakai AuthURL/TokenURL dan UserInfoURL.
+type Provider struct {
+	Name        string
+	Issuer      string
+	UserInfoURL string
+	// EmailsURL adalah endpoint tambahan yang mengembalikan daftar email
+	// (format GitHub: [{email, primary, verified}]) jika userinfo tidak berisi email
+	EmailsURL string
+	Claims    ClaimMapping
+	OAuth2    oauth2.Config
+
+	mu       sync.Mutex
+	verifier *oidc.IDTokenVerifier
+}
+
+// IsOIDC menandai penyedia yang memakai ID token
+func (p *Provider) IsOIDC() bool {
+	return p.Issuer != ""
+}
+
+// discover memuat dokumen discovery OIDC saat pertama kali dibutuhkan, sehingga
+// aplikasi tetap bisa berjalan walaupun IdP sedang tidak dapat dihubungi saat startup
+func (p *Provider) discover(ctx context.Context) (*oidc.IDTokenVerifier, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.verifier != nil {
+		return p.verifier, nil
+	}
+
+	discovered, err := oidc.NewProvider(ctx, p.Issuer)
+	if err != nil {
+		return nil, fmt.Errorf("OIDC discovery for %s failed: %w", p.Name, err)
+	}
+	p.OAuth2.Endpoint = discovered.Endpoint()
+	if p.UserInfoURL == "" {
+		var meta struct {
+			UserInfoEndpoint string `json:"userinfo_endpoint"`
+		}
+		if err := discovered.Claims(&meta); err == nil {
+			p.UserInfoURL = meta.UserInfoEndpoint
+		}
+	}
+	p.verifier = discovered.Verifier(&oidc.Config{ClientID: p.OAuth2.ClientID})
+	return p.verifier, nil
+}
+
+// AuthCodeURL membuat URL login penyedia dengan state, PKCE challenge dan nonce (untuk OIDC)
+func (p *Provider) AuthCodeURL(ctx context.Context, state, codeVerifier, nonce string) (string, error) {
+	opts := []oauth2.AuthCodeOption{oauth2.S256ChallengeOption(codeVerifier)}
+	if p.IsOIDC() {
+		if _, err := p.discover(ctx); err != nil {
+			return "", err
+		}
+		opts = append(opts, oidc.Nonce(nonce))
+	}
+	return p.OAuth2.AuthCodeURL(state, opts...), nil
+}
+
+// Exchange menukar authorization code menjadi Profile. Untuk OIDC, ID token diverifikasi
+// (tanda tangan lewat JWKS, issuer, audience, masa berlaku dan nonce) sebelum claim dipakai.
+func (p *Provider) Exchange(ctx context.Context, code, codeVerifier, nonce string) (*Profile, error) {
+	var verifier *oidc.IDTokenVerifier
+	if p.IsOIDC() {
+		var err error
+		if verifier, err = p.discover(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	token, err := p.OAuth2.Exchange(ctx, code, oauth2.VerifierOption(codeVerifier))
+	if err != nil {
+		return nil, err
+	}
+
+	claims := map[string]interface{}{}
+	if verifier != nil {
+		rawIDToken, ok := token.Extra("id_token").(string)
+		if !ok {
+			return nil, errors.New("token response did not contain an id_token")
+		}
+		idToken, err := verifier.Verify(ctx, rawIDToken)
+		if err != nil {
+			return nil, fmt.Errorf("invalid id_token: %w", err)
+		}
+		if idToken.Nonce != nonce {
+			return nil, errors.New("id_token nonce does not match")
+		}
+		if err := idToken.Claims(&claims); err != nil {
+			return nil, err
+		}
+	}
+
+	client := p.OAuth2.Client(ctx, token)
+	if p.UserInfoURL != "" {
+		var info map[string]interface{}
+		if err := getJSON(client, p.UserInfoURL, &info); err != nil {
+			return nil, fmt.Errorf("fetching userinfo: %w", err)
+		}
+		// Claim dari ID token yang sudah diverifikasi tidak ditimpa oleh userinfo
+		for key, value := range info {
+			if _, exists := claims[key]; !exists {
+				claims[key] = value
+			}
+		}
+	}
+
+	profile := &Profile{
+		Provider:      p.Name,
+		Subject:       claimString(claims, p.Claims.Subject),
+		Email:         claimString(claims, p.Claims.Email),
+		EmailVerified: claimBool(claims, p.Claims.EmailVerified),
+		Name:          claimString(claims, p.Claims.Name),
+		Picture:       claimString(claims, p.Claims.Picture),
+	}
+
+	if p.EmailsURL != "" {
+		if err := p.fillPrimaryEmail(client, profile); err != nil {
+			return nil, fmt.Errorf("fetching emails: %w", err)
+		}
+	}
+
+	return profile, nil
+}
+
+// fillPrimaryEmail memakai email utama dari EmailsURL beserta status verifikasinya
+func (p *Provider) fillPrimaryEmail(client *http.Client, profile *Profile) error {
+	var emails []struct {
+		Email    string `json:"email"`
+		Primary  bool   `json:"primary"`
+		Verified bool   `json:"verified"`
+	}
+	if err := getJSON(client, p.EmailsURL, &emails); err != nil {
+		return err
+	}
+	for _, e := range emails {
+		if e.Primary {
+			profile.Email = e.Email
+			profile.EmailVerified = e.Verified
+			break
+		}
+	}
+	return nil
+}
+
+// getJSON melakukan GET dan men-decode body JSON, angka disimpan sebagai json.Number
+func getJSON(client *http.Client, url string, v interface{}) error {
+	response, err := client.Get(url)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(response.Body, 512))
+		return fmt.Errorf("%s returned %s: %s", url, response.Status, body)
+	}
+	decoder := json.NewDecoder(response.Body)
+	decoder.UseNumber()
+	return decoder.Decode(v)
+}
 
-func init() {
-    // Memuat file .env
-    err := godotenv.Load()
-    if err != nil {
-        // Jika gagal, gunakan variabel lingkungan
-        log.Println("Gagal memuat file .env, menggunakan variabel lingkungan")
-    }
-
-    GoogleOauthConfig = &oauth2.Config{
-        RedirectURL:  "http://localhost:8080/auth/google/callback",
-        ClientID:     os.Getenv("GOOGLE_CLIENT_ID"),
-        ClientSecret: os.Getenv("GOOGLE_CLIENT_SECRET"),
-        Scopes:       []string{"https://www.googleapis.com/auth/userinfo.email"},
-        Endpoint:     google.Endpoint,
-    }
-
-    GithubOauthConfig = &oauth2.Config{
-        RedirectURL:  "http://localhost:8080/auth/github/callback",
-        ClientID:     os.Getenv("GITHUB_CLIENT_ID"),
-        ClientSecret: os.Getenv("GITHUB_CLIENT_SECRET"),
-        Scopes:       []string{"user:email"},
-        Endpoint:     github.Endpoint,
-    }
+// claimValue mengambil nilai claim, mendukung path bertitik
+func claimValue(claims map[string]interface{}, path string) interface{} {
+	if path == "" {
+		return nil
+	}
+	var current interface{} = claims
+	for _, part := range strings.Split(path, ".") {
+		m, ok := current.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		current = m[part]
+	}
+	return current
 }
 
-func GetUserDataFromGoogle(code string) ([]byte, error) {
-    token, err := GoogleOauthConfig.Exchange(context.Background(), code)
-    if err != nil {
-        return nil, err
-    }
-
-    response, err := http.Get("https://www.googleapis.com/oauth2/v2/userinfo?access_token=" + token.AccessToken)
-    if err != nil {
-        return nil, err
-    }
-    defer response.Body.Close()
-    return ioutil.ReadAll(response.Body)
+func claimString(claims map[string]interface{}, path string) string {
+	switch v := claimValue(claims, path).(type) {
+	case string:
+		return v
+	case json.Number:
+		return v.String()
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case nil:
+		return ""
+	default:
+		return fmt.Sprint(v)
+	}
 }
 
-func GetUserDataFromGithub(code string) ([]byte, error) {
-    token, err := GithubOauthConfig.Exchange(context.Background(), code)
-    if err != nil {
-        return nil, err
-    }
-
-    // Membuat klien HTTP dengan token
-    client := oauth2.NewClient(context.Background(), oauth2.StaticTokenSource(token))
-
-    // Mendapatkan data pengguna
-    userResponse, err := client.Get("https://api.github.com/user")
-    if err != nil {
-        return nil, err
-    }
-    defer userResponse.Body.Close()
-    userData, err := ioutil.ReadAll(userResponse.Body)
-    if err != nil {
-        return nil, err
-    }
-
-    // Mendapatkan email pengguna
-    emailResponse, err := client.Get("https://api.github.com/user/emails")
-    if err != nil {
-        return nil, err
-    }
-    defer emailResponse.Body.Close()
-    emailData, err := ioutil.ReadAll(emailResponse.Body)
-    if err != nil {
-        return nil, err
-    }
-
-    // Gabungkan data user dan email menjadi satu map
-    var userMap map[string]interface{}
-    var emailArray []map[string]interface{}
-
-    err = json.Unmarshal(userData, &userMap)
-    if err != nil {
-        return nil, err
-    }
-
-    err = json.Unmarshal(emailData, &emailArray)
-    if err != nil {
-        return nil, err
-    }
-
-    // Tambahkan email ke userMap
-    if len(emailArray) > 0 {
-        for _, emailObj := range emailArray {
-            if primary, ok := emailObj["primary"].(bool); ok && primary {
-                if email, ok := emailObj["email"].(string); ok {
-                    userMap["email"] = email
-                    break
-                }
-            }
-        }
-    }
-
-    // Kembalikan data pengguna sebagai JSON
-    return json.Marshal(userMap)
+func claimBool(claims map[string]interface{}, path string) bool {
+	switch v := claimValue(claims, path).(type) {
+	case bool:
+		return v
+	case string:
+		// Beberapa IdP mengirim "true" sebagai string
+		b, _ := strconv.ParseBool(v)
+		return b
+	}
+	return false
 }
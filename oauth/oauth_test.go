@@ -0,0 +1,237 @@
+// oauth/oauth_test.go
+package oauth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"github.com/mfuadfakhruzzaki/backend-api/config"
+)
+
+// stubIdP adalah penyedia OIDC tiruan: discovery, JWKS dan token endpoint yang
+// mengembalikan idToken apa adanya
+type stubIdP struct {
+	server  *httptest.Server
+	key     *rsa.PrivateKey
+	idToken string
+}
+
+func newStubIdP(t *testing.T) *stubIdP {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	idp := &stubIdP{key: key}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"issuer":                                idp.server.URL,
+			"authorization_endpoint":                idp.server.URL + "/authorize",
+			"token_endpoint":                        idp.server.URL + "/token",
+			"jwks_uri":                              idp.server.URL + "/jwks",
+			"id_token_signing_alg_values_supported": []string{"RS256"},
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"keys": []map[string]string{{
+				"kty": "RSA",
+				"kid": "test",
+				"alg": "RS256",
+				"use": "sig",
+				"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+				"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+			}},
+		})
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		response := map[string]interface{}{"access_token": "access", "token_type": "Bearer", "expires_in": 3600}
+		if idp.idToken != "" {
+			response["id_token"] = idp.idToken
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(response)
+	})
+	idp.server = httptest.NewServer(mux)
+	t.Cleanup(idp.server.Close)
+	return idp
+}
+
+// sign membuat ID token RS256 dengan kid yang ada di JWKS
+func (idp *stubIdP) sign(t *testing.T, key *rsa.PrivateKey, claims jwt.MapClaims) string {
+	t.Helper()
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = "test"
+	signed, err := token.SignedString(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signed
+}
+
+func TestExchangeValidatesIDToken(t *testing.T) {
+	idp := newStubIdP(t)
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	const clientID, nonce = "client-123", "nonce-abc"
+	validClaims := func() jwt.MapClaims {
+		now := time.Now()
+		return jwt.MapClaims{
+			"iss":                idp.server.URL,
+			"aud":                clientID,
+			"sub":                "user-42",
+			"nonce":              nonce,
+			"iat":                now.Unix(),
+			"exp":                now.Add(5 * time.Minute).Unix(),
+			"email":              "budi@example.com",
+			"email_verified":     true,
+			"preferred_username": "budi",
+		}
+	}
+
+	tests := []struct {
+		name    string
+		modify  func(jwt.MapClaims)
+		key     *rsa.PrivateKey
+		noToken bool
+		wantErr string
+	}{
+		{name: "valid"},
+		{name: "wrong nonce", modify: func(c jwt.MapClaims) { c["nonce"] = "replayed" }, wantErr: "nonce"},
+		{name: "missing nonce", modify: func(c jwt.MapClaims) { delete(c, "nonce") }, wantErr: "nonce"},
+		{name: "wrong issuer", modify: func(c jwt.MapClaims) { c["iss"] = "https://evil.example.com" }, wantErr: "different provider"},
+		{name: "wrong audience", modify: func(c jwt.MapClaims) { c["aud"] = "another-client" }, wantErr: "audience"},
+		{name: "expired", modify: func(c jwt.MapClaims) { c["exp"] = time.Now().Add(-time.Minute).Unix() }, wantErr: "expired"},
+		{name: "unknown signing key", key: otherKey, wantErr: "signature"},
+		{name: "no id_token", noToken: true, wantErr: "id_token"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider, err := providerFromConfig("acme", config.OAuthClientConfig{
+				ClientID:     clientID,
+				ClientSecret: "secret",
+				Issuer:       idp.server.URL,
+			}, "http://localhost:8080")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			claims := validClaims()
+			if tt.modify != nil {
+				tt.modify(claims)
+			}
+			key := idp.key
+			if tt.key != nil {
+				key = tt.key
+			}
+			idp.idToken = ""
+			if !tt.noToken {
+				idp.idToken = idp.sign(t, key, claims)
+			}
+
+			profile, err := provider.Exchange(context.Background(), "code", "verifier", nonce)
+			if tt.wantErr != "" {
+				if err == nil {
+					t.Fatalf("Exchange accepted the ID token, profile %+v", profile)
+				}
+				if !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Exchange error = %q, want it to mention %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Exchange: %v", err)
+			}
+			want := Profile{Provider: "acme", Subject: "user-42", Email: "budi@example.com", EmailVerified: true, Name: "budi"}
+			if *profile != want {
+				t.Fatalf("profile = %+v, want %+v", *profile, want)
+			}
+		})
+	}
+}
+
+func TestProviderFromConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		client  config.OAuthClientConfig
+		wantNil bool
+		wantErr bool
+	}{
+		{name: "no client ID", client: config.OAuthClientConfig{}, wantNil: true},
+		{name: "OIDC issuer", client: config.OAuthClientConfig{ClientID: "id", Issuer: "https://idp.example.com"}},
+		{name: "plain OAuth2", client: config.OAuthClientConfig{ClientID: "id", AuthURL: "https://a", TokenURL: "https://t", UserInfoURL: "https://u"}},
+		{name: "plain OAuth2 without userinfo", client: config.OAuthClientConfig{ClientID: "id", AuthURL: "https://a", TokenURL: "https://t"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider, err := providerFromConfig("acme", tt.client, "http://localhost:8080")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if (provider == nil) != tt.wantNil {
+				t.Fatalf("provider = %+v, wantNil %v", provider, tt.wantNil)
+			}
+			if provider != nil && provider.OAuth2.RedirectURL != "http://localhost:8080/auth/acme/callback" {
+				t.Fatalf("RedirectURL = %q", provider.OAuth2.RedirectURL)
+			}
+		})
+	}
+}
+
+func TestIsAllowedRedirect(t *testing.T) {
+	previous := config.App
+	config.App = &config.Config{OAuth: config.OAuthConfig{RedirectAllowlist: []string{
+		"https://app.example.com/dashboard",
+		"https://www.example.com",
+	}}}
+	t.Cleanup(func() { config.App = previous })
+
+	tests := []struct {
+		target string
+		want   bool
+	}{
+		{target: "/profile", want: true},
+		{target: "//evil.example.com", want: false},
+		{target: "https://app.example.com/dashboard", want: true},
+		{target: "https://app.example.com/dashboard/", want: true},
+		{target: "https://app.example.com/dashboard/settings?tab=1", want: true},
+		{target: "https://APP.example.com/dashboard", want: true},
+		{target: "https://app.example.com/dashboard-evil", want: false},
+		{target: "https://app.example.com/dashboardx/settings", want: false},
+		{target: "https://app.example.com/dashboard/../admin", want: false},
+		{target: "https://app.example.com/dashboard/%2e%2e/admin", want: false},
+		{target: "https://app.example.com", want: false},
+		{target: "https://app.example.com/", want: false},
+		{target: "http://app.example.com/dashboard", want: false},
+		{target: "https://user@app.example.com/dashboard", want: false},
+		{target: "https://www.example.com", want: true},
+		{target: "https://www.example.com/anything", want: true},
+		{target: "https://evil.example.com/dashboard", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.target, func(t *testing.T) {
+			if got := IsAllowedRedirect(tt.target); got != tt.want {
+				t.Fatalf("IsAllowedRedirect(%q) = %v, want %v", tt.target, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,140 @@
+// oauth/registry.go
+package oauth
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/github"
+
+	"github.com/mfuadfakhruzzaki/backend-api/config"
+)
+
+// registry berisi penyedia yang aktif, diisi oleh Init
+var registry = map[string]*Provider{}
+
+// presets adalah nilai bawaan untuk penyedia yang dikenal. Semua nilai tetap bisa
+// ditimpa melalui environment variables.
+var presets = map[string]*Provider{
+	"google": {
+		Issuer: "https://accounts.google.com",
+		Claims: ClaimMapping{Subject: "sub", Email: "email", EmailVerified: "email_verified", Name: "name", Picture: "picture"},
+		OAuth2: oauth2.Config{Scopes: []string{"openid", "email", "profile"}},
+	},
+	"github": {
+		UserInfoURL: "https://api.github.com/user",
+		EmailsURL:   "https://api.github.com/user/emails",
+		Claims:      ClaimMapping{Subject: "id", Email: "email", EmailVerified: "email_verified", Name: "login", Picture: "avatar_url"},
+		OAuth2:      oauth2.Config{Endpoint: github.Endpoint, Scopes: []string{"read:user", "user:email"}},
+	},
+}
+
+// defaultClaims dipakai untuk penyedia OIDC tanpa preset
+var defaultClaims = ClaimMapping{Subject: "sub", Email: "email", EmailVerified: "email_verified", Name: "preferred_username", Picture: "picture"}
+
+// Init memuat penyedia dari cfg.Providers (OAUTH_PROVIDERS, default "google,github") dan
+// pengaturan masing-masing dari cfg.Clients, yang bisa diisi dengan OAUTH_<NAMA>_*.
+// Penyedia tanpa client ID dilewati.
+//
+//	OAUTH_<NAMA>_CLIENT_ID, OAUTH_<NAMA>_CLIENT_SECRET
+//	OAUTH_<NAMA>_ISSUER                        (OIDC, memakai discovery dan JWKS)
+//	OAUTH_<NAMA>_AUTH_URL, OAUTH_<NAMA>_TOKEN_URL (OAuth2 biasa)
+//	OAUTH_<NAMA>_USERINFO_URL, OAUTH_<NAMA>_EMAILS_URL
+//	OAUTH_<NAMA>_SCOPES                        (dipisahkan koma atau spasi)
+//	OAUTH_<NAMA>_CLAIM_SUBJECT, _CLAIM_EMAIL, _CLAIM_EMAIL_VERIFIED, _CLAIM_NAME, _CLAIM_PICTURE
+//
+// Redirect URL dibentuk dari OAUTH_REDIRECT_BASE_URL + "/auth/<nama>/callback".
+func Init(cfg config.OAuthConfig) error {
+	providers := map[string]*Provider{}
+	for _, name := range cfg.Providers {
+		if name == "" {
+			continue
+		}
+		provider, err := providerFromConfig(name, cfg.Clients[name], cfg.RedirectBaseURL)
+		if err != nil {
+			return err
+		}
+		if provider == nil {
+			log.Printf("OAuth provider %s has no client ID configured, skipping", name)
+			continue
+		}
+		providers[name] = provider
+	}
+
+	registry = providers
+	return nil
+}
+
+func providerFromConfig(name string, client config.OAuthClientConfig, baseURL string) (*Provider, error) {
+	if client.ClientID == "" {
+		return nil, nil
+	}
+
+	preset, hasPreset := presets[name]
+	if !hasPreset {
+		preset = &Provider{}
+	}
+	p := &Provider{
+		Name:        name,
+		Issuer:      preset.Issuer,
+		UserInfoURL: preset.UserInfoURL,
+		EmailsURL:   preset.EmailsURL,
+		Claims:      preset.Claims,
+		OAuth2:      preset.OAuth2,
+	}
+	if !hasPreset {
+		p.Claims = defaultClaims
+		p.OAuth2.Scopes = []string{"openid", "email", "profile"}
+	}
+
+	p.OAuth2.ClientID = client.ClientID
+	p.OAuth2.ClientSecret = client.ClientSecret
+	p.OAuth2.RedirectURL = baseURL + "/auth/" + name + "/callback"
+
+	for _, override := range []struct {
+		value  string
+		target *string
+	}{
+		{client.Issuer, &p.Issuer},
+		{client.AuthURL, &p.OAuth2.Endpoint.AuthURL},
+		{client.TokenURL, &p.OAuth2.Endpoint.TokenURL},
+		{client.UserInfoURL, &p.UserInfoURL},
+		{client.EmailsURL, &p.EmailsURL},
+		{client.ClaimSubject, &p.Claims.Subject},
+		{client.ClaimEmail, &p.Claims.Email},
+		{client.ClaimEmailVerified, &p.Claims.EmailVerified},
+		{client.ClaimName, &p.Claims.Name},
+		{client.ClaimPicture, &p.Claims.Picture},
+	} {
+		if override.value != "" {
+			*override.target = override.value
+		}
+	}
+	if len(client.Scopes) > 0 {
+		p.OAuth2.Scopes = client.Scopes
+	}
+
+	if !p.IsOIDC() && (p.OAuth2.Endpoint.AuthURL == "" || p.OAuth2.Endpoint.TokenURL == "" || p.UserInfoURL == "") {
+		return nil, fmt.Errorf("OAuth provider %s needs OAUTH_%s_ISSUER, or AUTH_URL, TOKEN_URL and USERINFO_URL", name, strings.ToUpper(name))
+	}
+	return p, nil
+}
+
+// Get mengembalikan penyedia berdasarkan nama
+func Get(name string) (*Provider, bool) {
+	p, ok := registry[name]
+	return p, ok
+}
+
+// Names mengembalikan nama semua penyedia yang aktif, terurut
+func Names() []string {
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
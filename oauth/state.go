@@ -0,0 +1,123 @@
+// oauth/state.go
+package oauth
+
+import (
+	"errors"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+
+	"golang.org/x/oauth2"
+	"gorm.io/gorm/clause"
+
+	"github.com/mfuadfakhruzzaki/backend-api/config"
+	"github.com/mfuadfakhruzzaki/backend-api/models"
+	"github.com/mfuadfakhruzzaki/backend-api/utils"
+)
+
+const (
+	// StateCookieName adalah cookie yang mengikat state OAuth ke browser yang memulai login
+	StateCookieName = "oauth_state"
+	// StateTTL adalah masa berlaku state sejak login dimulai
+	StateTTL = 10 * time.Minute
+)
+
+var (
+	ErrInvalidState       = errors.New("invalid or expired OAuth state")
+	ErrRedirectNotAllowed = errors.New("redirect target is not allowed")
+)
+
+// NewState membuat state acak dan PKCE verifier baru lalu menyimpannya di database.
+// linkUserID diisi jika login dimulai untuk menautkan identitas ke akun yang sedang login.
+func NewState(provider, redirectTo string, linkUserID *uint) (*models.OAuthState, error) {
+	if redirectTo != "" && !IsAllowedRedirect(redirectTo) {
+		return nil, ErrRedirectNotAllowed
+	}
+
+	state, err := utils.RandomToken(32)
+	if err != nil {
+		return nil, err
+	}
+	nonce, err := utils.RandomToken(24)
+	if err != nil {
+		return nil, err
+	}
+
+	record := &models.OAuthState{
+		State:        state,
+		Provider:     provider,
+		CodeVerifier: oauth2.GenerateVerifier(),
+		Nonce:        nonce,
+		RedirectTo:   redirectTo,
+		LinkUserID:   linkUserID,
+		ExpiresAt:    time.Now().Add(StateTTL),
+	}
+	if err := config.DB.Create(record).Error; err != nil {
+		return nil, err
+	}
+
+	// Bersihkan state yang tidak pernah dipakai
+	config.DB.Where("expires_at < ?", time.Now()).Delete(&models.OAuthState{})
+
+	return record, nil
+}
+
+// ConsumeState memvalidasi state dari callback terhadap cookie browser dan menghapusnya,
+// sehingga setiap state hanya bisa dipakai satu kali
+func ConsumeState(provider, state, cookieState string) (*models.OAuthState, error) {
+	if state == "" || cookieState == "" || state != cookieState {
+		return nil, ErrInvalidState
+	}
+
+	var records []models.OAuthState
+	err := config.DB.Clauses(clause.Returning{}).
+		Where("state = ? AND provider = ?", state, provider).
+		Delete(&records).Error
+	if err != nil {
+		return nil, err
+	}
+	if len(records) != 1 || time.Now().After(records[0].ExpiresAt) {
+		return nil, ErrInvalidState
+	}
+	return &records[0], nil
+}
+
+// IsAllowedRedirect memeriksa target redirect setelah login terhadap OAUTH_REDIRECT_ALLOWLIST
+// (config.App.OAuth.RedirectAllowlist). Path relatif di server ini selalu diizinkan.
+func IsAllowedRedirect(target string) bool {
+	if strings.HasPrefix(target, "/") && !strings.HasPrefix(target, "//") && !strings.Contains(target, "\\") {
+		return true
+	}
+
+	u, err := url.Parse(target)
+	if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" || u.User != nil {
+		return false
+	}
+
+	for _, entry := range config.App.OAuth.RedirectAllowlist {
+		allowed, err := url.Parse(entry)
+		if err != nil || allowed.Host == "" {
+			continue
+		}
+		if u.Scheme == allowed.Scheme && strings.EqualFold(u.Host, allowed.Host) && pathAllowed(u.Path, allowed.Path) {
+			return true
+		}
+	}
+	return false
+}
+
+// pathAllowed memeriksa apakah target sama dengan path yang diizinkan atau berada di bawahnya.
+// Segmen "." dan ".." diselesaikan dulu seperti yang dilakukan browser, dan batasnya harus
+// "/" sehingga "/app" tidak mencocokkan "/application".
+func pathAllowed(target, allowed string) bool {
+	allowed = strings.TrimSuffix(allowed, "/")
+	if allowed == "" {
+		return true
+	}
+	if target == "" {
+		return false
+	}
+	target = path.Clean(target)
+	return target == allowed || strings.HasPrefix(target, allowed+"/")
+}
@@ -0,0 +1,240 @@
+// passkey/ceremony.go
+package passkey
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/go-webauthn/webauthn/webauthn"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/mfuadfakhruzzaki/backend-api/config"
+	"github.com/mfuadfakhruzzaki/backend-api/models"
+	"github.com/mfuadfakhruzzaki/backend-api/utils"
+)
+
+const (
+	ceremonyRegistration = "registration"
+	ceremonyLogin        = "login"
+)
+
+var ErrInvalidSession = errors.New("invalid or expired passkey session")
+
+// BeginRegistration membuat opsi untuk navigator.credentials.create() dan mengembalikan
+// token sesi yang harus dikirim kembali ke FinishRegistration
+func BeginRegistration(user *models.User, name string) (*protocol.CredentialCreation, string, error) {
+	if WebAuthn == nil {
+		return nil, "", ErrNotConfigured
+	}
+	if err := ensureHandle(user); err != nil {
+		return nil, "", err
+	}
+	u, err := LoadUser(user)
+	if err != nil {
+		return nil, "", err
+	}
+
+	// Authenticator yang sudah terdaftar tidak didaftarkan dua kali
+	exclusions := make([]protocol.CredentialDescriptor, 0, len(u.Credentials))
+	for _, c := range u.WebAuthnCredentials() {
+		exclusions = append(exclusions, c.Descriptor())
+	}
+
+	creation, session, err := WebAuthn.BeginRegistration(u, webauthn.WithExclusions(exclusions))
+	if err != nil {
+		return nil, "", err
+	}
+	token, err := saveSession(ceremonyRegistration, &user.ID, name, session)
+	if err != nil {
+		return nil, "", err
+	}
+	return creation, token, nil
+}
+
+// FinishRegistration memverifikasi respons authenticator dan menyimpan passkey baru
+func FinishRegistration(user *models.User, token string, r *http.Request) (*models.WebAuthnCredential, error) {
+	if WebAuthn == nil {
+		return nil, ErrNotConfigured
+	}
+	record, session, err := consumeSession(ceremonyRegistration, token)
+	if err != nil {
+		return nil, err
+	}
+	if record.UserID == nil || *record.UserID != user.ID {
+		return nil, ErrInvalidSession
+	}
+
+	u, err := LoadUser(user)
+	if err != nil {
+		return nil, err
+	}
+	credential, err := WebAuthn.FinishRegistration(u, *session, r)
+	if err != nil {
+		return nil, err
+	}
+
+	name := record.Name
+	if name == "" {
+		name = "Passkey"
+	}
+	stored := fromWebAuthnCredential(user.ID, name, credential)
+	if err := config.DB.Create(&stored).Error; err != nil {
+		return nil, err
+	}
+	return &stored, nil
+}
+
+// BeginLogin membuat opsi untuk navigator.credentials.get() tanpa menyebut pengguna;
+// authenticator memilih passkey dan mengirim user handle-nya
+func BeginLogin() (*protocol.CredentialAssertion, string, error) {
+	if WebAuthn == nil {
+		return nil, "", ErrNotConfigured
+	}
+	assertion, session, err := WebAuthn.BeginDiscoverableLogin(webauthn.WithUserVerification(protocol.VerificationRequired))
+	if err != nil {
+		return nil, "", err
+	}
+	token, err := saveSession(ceremonyLogin, nil, "", session)
+	if err != nil {
+		return nil, "", err
+	}
+	return assertion, token, nil
+}
+
+// FinishLogin memverifikasi assertion, memeriksa sign count dan mengembalikan pemilik passkey
+func FinishLogin(token string, r *http.Request) (*models.User, error) {
+	if WebAuthn == nil {
+		return nil, ErrNotConfigured
+	}
+	_, session, err := consumeSession(ceremonyLogin, token)
+	if err != nil {
+		return nil, err
+	}
+
+	var owner *User
+	handler := func(rawID, userHandle []byte) (webauthn.User, error) {
+		var user models.User
+		if err := config.DB.Where("web_authn_handle = ?", userHandle).First(&user).Error; err != nil {
+			return nil, ErrUnknownCredential
+		}
+		u, err := LoadUser(&user)
+		if err != nil {
+			return nil, err
+		}
+		owner = u
+		return u, nil
+	}
+
+	credential, err := WebAuthn.FinishDiscoverableLogin(handler, *session, r)
+	if err != nil {
+		return nil, err
+	}
+	stored := owner.find(credential.ID)
+	if stored == nil {
+		return nil, ErrUnknownCredential
+	}
+
+	if credential.Authenticator.CloneWarning {
+		config.DB.Model(stored).Update("clone_warning", true)
+		return nil, ErrCredentialCloned
+	}
+
+	// Sign count hanya boleh naik; syarat di WHERE mencegah dua login bersamaan memakai nilai yang sama
+	now := time.Now()
+	result := config.DB.Model(&models.WebAuthnCredential{}).
+		Where("id = ? AND (sign_count < ? OR sign_count = 0)", stored.ID, credential.Authenticator.SignCount).
+		Updates(map[string]interface{}{
+			"sign_count":   credential.Authenticator.SignCount,
+			"backup_state": credential.Flags.BackupState,
+			"last_used_at": now,
+		})
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected != 1 {
+		config.DB.Model(stored).Update("clone_warning", true)
+		return nil, ErrCredentialCloned
+	}
+
+	return owner.Model, nil
+}
+
+// ensureHandle membuat user handle acak untuk pengguna yang belum memilikinya
+func ensureHandle(user *models.User) error {
+	if len(user.WebAuthnHandle) > 0 {
+		return nil
+	}
+	handle := make([]byte, 32)
+	if _, err := rand.Read(handle); err != nil {
+		return err
+	}
+	result := config.DB.Model(&models.User{}).
+		Where("id = ? AND web_authn_handle IS NULL", user.ID).
+		Update("web_authn_handle", handle)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		// Permintaan lain sudah membuat handle lebih dulu
+		return config.DB.Select("web_authn_handle").First(user, user.ID).Error
+	}
+	user.WebAuthnHandle = handle
+	return nil
+}
+
+func saveSession(ceremony string, userID *uint, name string, session *webauthn.SessionData) (string, error) {
+	data, err := json.Marshal(session)
+	if err != nil {
+		return "", err
+	}
+	token, err := utils.RandomToken(32)
+	if err != nil {
+		return "", err
+	}
+
+	record := models.WebAuthnSession{
+		TokenHash: utils.HashToken(token),
+		Ceremony:  ceremony,
+		UserID:    userID,
+		Name:      name,
+		Data:      data,
+		ExpiresAt: time.Now().Add(ceremonyTimeout),
+	}
+	if err := config.DB.Create(&record).Error; err != nil {
+		return "", err
+	}
+
+	// Bersihkan ceremony yang tidak pernah diselesaikan
+	config.DB.Where("expires_at < ?", time.Now()).Delete(&models.WebAuthnSession{})
+
+	return token, nil
+}
+
+// consumeSession mengambil dan menghapus sesi ceremony sehingga challenge hanya bisa dipakai sekali
+func consumeSession(ceremony, token string) (*models.WebAuthnSession, *webauthn.SessionData, error) {
+	if token == "" {
+		return nil, nil, ErrInvalidSession
+	}
+
+	var records []models.WebAuthnSession
+	err := config.DB.Clauses(clause.Returning{}).
+		Where("token_hash = ? AND ceremony = ?", utils.HashToken(token), ceremony).
+		Delete(&records).Error
+	if err != nil && err != gorm.ErrRecordNotFound {
+		return nil, nil, err
+	}
+	if len(records) != 1 || time.Now().After(records[0].ExpiresAt) {
+		return nil, nil, ErrInvalidSession
+	}
+
+	var session webauthn.SessionData
+	if err := json.Unmarshal(records[0].Data, &session); err != nil {
+		return nil, nil, err
+	}
+	return &records[0], &session, nil
+}
@@ -0,0 +1,134 @@
+// passkey/passkey.go
+package passkey
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/go-webauthn/webauthn/webauthn"
+
+	"github.com/mfuadfakhruzzaki/backend-api/config"
+	"github.com/mfuadfakhruzzaki/backend-api/models"
+)
+
+// ceremonyTimeout adalah waktu yang diberikan kepada pengguna untuk menyelesaikan registrasi atau login
+const ceremonyTimeout = 5 * time.Minute
+
+var (
+	ErrNotConfigured     = errors.New("passkeys are not configured")
+	ErrCredentialCloned  = errors.New("passkey sign count went backwards, the authenticator may have been cloned")
+	ErrUnknownCredential = errors.New("unknown passkey")
+)
+
+// WebAuthn adalah relying party yang dipakai aplikasi, diisi oleh Init
+var WebAuthn *webauthn.WebAuthn
+
+// Init menyiapkan relying party dari konfigurasi:
+//
+//	WEBAUTHN_RP_ID       domain aplikasi tanpa skema dan port (default "localhost")
+//	WEBAUTHN_RP_ORIGINS  origin frontend yang diizinkan, dipisahkan koma (default "http://localhost:8080")
+//	WEBAUTHN_RP_NAME     nama yang ditampilkan oleh authenticator
+func Init(cfg config.WebAuthnConfig) error {
+	timeout := webauthn.TimeoutConfig{Enforce: true, Timeout: ceremonyTimeout, TimeoutUVD: ceremonyTimeout}
+	w, err := webauthn.New(&webauthn.Config{
+		RPID:          cfg.RPID,
+		RPDisplayName: cfg.RPName,
+		RPOrigins:     cfg.RPOrigins,
+		// Passkey disimpan di authenticator (discoverable) dan selalu memakai verifikasi pengguna
+		// (biometrik/PIN), sehingga login dengan passkey tidak membutuhkan kode 2FA lagi
+		AuthenticatorSelection: protocol.AuthenticatorSelection{
+			ResidentKey:      protocol.ResidentKeyRequirementRequired,
+			UserVerification: protocol.VerificationRequired,
+		},
+		AttestationPreference: protocol.PreferNoAttestation,
+		Timeouts:              webauthn.TimeoutsConfig{Login: timeout, Registration: timeout},
+	})
+	if err != nil {
+		return fmt.Errorf("invalid WebAuthn configuration: %w", err)
+	}
+	WebAuthn = w
+	return nil
+}
+
+// User menghubungkan models.User dan passkey-nya dengan antarmuka webauthn.User
+type User struct {
+	Model       *models.User
+	Credentials []models.WebAuthnCredential
+}
+
+// LoadUser memuat pengguna beserta passkey-nya
+func LoadUser(user *models.User) (*User, error) {
+	var credentials []models.WebAuthnCredential
+	if err := config.DB.Where("user_id = ?", user.ID).Find(&credentials).Error; err != nil {
+		return nil, err
+	}
+	return &User{Model: user, Credentials: credentials}, nil
+}
+
+func (u *User) WebAuthnID() []byte          { return u.Model.WebAuthnHandle }
+func (u *User) WebAuthnName() string        { return u.Model.Email }
+func (u *User) WebAuthnDisplayName() string { return u.Model.Username }
+func (u *User) WebAuthnIcon() string        { return "" }
+
+func (u *User) WebAuthnCredentials() []webauthn.Credential {
+	credentials := make([]webauthn.Credential, len(u.Credentials))
+	for i, c := range u.Credentials {
+		credentials[i] = toWebAuthnCredential(c)
+	}
+	return credentials
+}
+
+// find mengembalikan passkey milik pengguna dengan credential ID tertentu
+func (u *User) find(credentialID []byte) *models.WebAuthnCredential {
+	for i := range u.Credentials {
+		if string(u.Credentials[i].CredentialID) == string(credentialID) {
+			return &u.Credentials[i]
+		}
+	}
+	return nil
+}
+
+func toWebAuthnCredential(c models.WebAuthnCredential) webauthn.Credential {
+	var transports []protocol.AuthenticatorTransport
+	for _, t := range strings.Split(c.Transports, ",") {
+		if t != "" {
+			transports = append(transports, protocol.AuthenticatorTransport(t))
+		}
+	}
+	return webauthn.Credential{
+		ID:              c.CredentialID,
+		PublicKey:       c.PublicKey,
+		AttestationType: c.AttestationType,
+		Transport:       transports,
+		Flags: webauthn.CredentialFlags{
+			BackupEligible: c.BackupEligible,
+			BackupState:    c.BackupState,
+		},
+		Authenticator: webauthn.Authenticator{
+			AAGUID:    c.AAGUID,
+			SignCount: c.SignCount,
+		},
+	}
+}
+
+func fromWebAuthnCredential(userID uint, name string, c *webauthn.Credential) models.WebAuthnCredential {
+	transports := make([]string, len(c.Transport))
+	for i, t := range c.Transport {
+		transports[i] = string(t)
+	}
+	return models.WebAuthnCredential{
+		UserID:          userID,
+		Name:            name,
+		CredentialID:    c.ID,
+		PublicKey:       c.PublicKey,
+		AttestationType: c.AttestationType,
+		Transports:      strings.Join(transports, ","),
+		AAGUID:          c.Authenticator.AAGUID,
+		SignCount:       c.Authenticator.SignCount,
+		BackupEligible:  c.Flags.BackupEligible,
+		BackupState:     c.Flags.BackupState,
+	}
+}
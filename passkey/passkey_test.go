@@ -0,0 +1,296 @@
+// passkey/passkey_test.go
+package passkey
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/glebarez/sqlite"
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/go-webauthn/webauthn/protocol/webauthncbor"
+	"github.com/go-webauthn/webauthn/protocol/webauthncose"
+	"gorm.io/gorm"
+
+	"github.com/mfuadfakhruzzaki/backend-api/config"
+	"github.com/mfuadfakhruzzaki/backend-api/models"
+)
+
+const (
+	testRPID   = "localhost"
+	testOrigin = "http://localhost:8080"
+)
+
+// Flag authenticator data, lihat https://www.w3.org/TR/webauthn-2/#flags
+const (
+	flagUserPresent  = 0x01
+	flagUserVerified = 0x04
+	flagAttested     = 0x40
+)
+
+// softAuthenticator adalah authenticator perangkat lunak dengan satu passkey ES256
+type softAuthenticator struct {
+	key          *ecdsa.PrivateKey
+	credentialID []byte
+	userHandle   []byte
+}
+
+func newSoftAuthenticator(t *testing.T) *softAuthenticator {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	id := make([]byte, 16)
+	rand.Read(id)
+	return &softAuthenticator{key: key, credentialID: id}
+}
+
+func (a *softAuthenticator) authData(flags byte, signCount uint32, attested []byte) []byte {
+	rpIDHash := sha256.Sum256([]byte(testRPID))
+	data := append(rpIDHash[:], flags)
+	data = binary.BigEndian.AppendUint32(data, signCount)
+	return append(data, attested...)
+}
+
+func (a *softAuthenticator) clientData(t *testing.T, ceremony string, challenge []byte, origin string) []byte {
+	t.Helper()
+	data, err := json.Marshal(map[string]string{
+		"type":      ceremony,
+		"challenge": base64.RawURLEncoding.EncodeToString(challenge),
+		"origin":    origin,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+// register membuat respons navigator.credentials.create() dengan attestation "none"
+func (a *softAuthenticator) register(t *testing.T, challenge []byte, origin string, flags byte) []byte {
+	t.Helper()
+	publicKey, err := webauthncbor.Marshal(webauthncose.EC2PublicKeyData{
+		PublicKeyData: webauthncose.PublicKeyData{
+			KeyType:   int64(webauthncose.EllipticKey),
+			Algorithm: int64(webauthncose.AlgES256),
+		},
+		Curve:  1, // P-256
+		XCoord: a.key.PublicKey.X.FillBytes(make([]byte, 32)),
+		YCoord: a.key.PublicKey.Y.FillBytes(make([]byte, 32)),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	attested := make([]byte, 16) // AAGUID kosong
+	attested = binary.BigEndian.AppendUint16(attested, uint16(len(a.credentialID)))
+	attested = append(attested, a.credentialID...)
+	attested = append(attested, publicKey...)
+
+	attestation, err := webauthncbor.Marshal(map[string]interface{}{
+		"fmt":      "none",
+		"attStmt":  map[string]interface{}{},
+		"authData": a.authData(flags|flagAttested, 0, attested),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return a.credentialJSON(t, map[string]string{
+		"clientDataJSON":    base64.RawURLEncoding.EncodeToString(a.clientData(t, "webauthn.create", challenge, origin)),
+		"attestationObject": base64.RawURLEncoding.EncodeToString(attestation),
+	})
+}
+
+// login membuat respons navigator.credentials.get() untuk passkey yang dapat ditemukan
+func (a *softAuthenticator) login(t *testing.T, challenge []byte, origin string, flags byte, signCount uint32) []byte {
+	t.Helper()
+	authData := a.authData(flags, signCount, nil)
+	clientData := a.clientData(t, "webauthn.get", challenge, origin)
+	clientDataHash := sha256.Sum256(clientData)
+	digest := sha256.Sum256(append(append([]byte{}, authData...), clientDataHash[:]...))
+	signature, err := ecdsa.SignASN1(rand.Reader, a.key, digest[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	return a.credentialJSON(t, map[string]string{
+		"clientDataJSON":    base64.RawURLEncoding.EncodeToString(clientData),
+		"authenticatorData": base64.RawURLEncoding.EncodeToString(authData),
+		"signature":         base64.RawURLEncoding.EncodeToString(signature),
+		"userHandle":        base64.RawURLEncoding.EncodeToString(a.userHandle),
+	})
+}
+
+func (a *softAuthenticator) credentialJSON(t *testing.T, response map[string]string) []byte {
+	t.Helper()
+	id := base64.RawURLEncoding.EncodeToString(a.credentialID)
+	body, err := json.Marshal(map[string]interface{}{"id": id, "rawId": id, "type": "public-key", "response": response})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return body
+}
+
+func setupTest(t *testing.T) *models.User {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open("file:"+t.Name()+"?mode=memory&cache=shared"), &gorm.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.AutoMigrate(&models.User{}, &models.WebAuthnCredential{}, &models.WebAuthnSession{}); err != nil {
+		t.Fatal(err)
+	}
+	config.DB = db
+
+	if err := Init(config.WebAuthnConfig{RPID: testRPID, RPOrigins: []string{testOrigin}, RPName: "Test"}); err != nil {
+		t.Fatal(err)
+	}
+
+	user := &models.User{Email: "budi@example.com", Username: "budi", Password: "x"}
+	if err := db.Create(user).Error; err != nil {
+		t.Fatal(err)
+	}
+	return user
+}
+
+func request(body []byte) *http.Request {
+	return httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(body))
+}
+
+func TestRegisterAndLoginRoundTrip(t *testing.T) {
+	user := setupTest(t)
+	authenticator := newSoftAuthenticator(t)
+
+	creation, token, err := BeginRegistration(user, "Laptop")
+	if err != nil {
+		t.Fatal(err)
+	}
+	authenticator.userHandle = user.WebAuthnHandle
+	if !bytes.Equal(creation.Response.User.ID.(protocol.URLEncodedBase64), user.WebAuthnHandle) {
+		t.Fatalf("creation user ID = %v, want the user handle", creation.Response.User.ID)
+	}
+
+	body := authenticator.register(t, creation.Response.Challenge, testOrigin, flagUserPresent|flagUserVerified)
+	credential, err := FinishRegistration(user, token, request(body))
+	if err != nil {
+		t.Fatalf("FinishRegistration: %v", err)
+	}
+	if credential.Name != "Laptop" || !bytes.Equal(credential.CredentialID, authenticator.credentialID) {
+		t.Fatalf("stored credential = %+v", credential)
+	}
+	if _, err := FinishRegistration(user, token, request(body)); !errors.Is(err, ErrInvalidSession) {
+		t.Fatalf("reusing the registration session: error = %v, want ErrInvalidSession", err)
+	}
+
+	// Kasus dijalankan berurutan karena sign count tersimpan dibawa ke kasus berikutnya
+	tests := []struct {
+		name      string
+		origin    string
+		flags     byte
+		signCount uint32
+		reuse     bool
+		wantErr   error
+		anyErr    bool
+	}{
+		{name: "valid", origin: testOrigin, flags: flagUserPresent | flagUserVerified, signCount: 1},
+		{name: "higher sign count", origin: testOrigin, flags: flagUserPresent | flagUserVerified, signCount: 5},
+		{name: "replayed session", origin: testOrigin, flags: flagUserPresent | flagUserVerified, signCount: 6, reuse: true, wantErr: ErrInvalidSession},
+		{name: "wrong origin", origin: "https://evil.example.com", flags: flagUserPresent | flagUserVerified, signCount: 7, anyErr: true},
+		{name: "user not verified", origin: testOrigin, flags: flagUserPresent, signCount: 8, anyErr: true},
+		{name: "sign count went backwards", origin: testOrigin, flags: flagUserPresent | flagUserVerified, signCount: 2, wantErr: ErrCredentialCloned},
+	}
+	var lastToken string
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assertion, token, err := BeginLogin()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.reuse {
+				token = lastToken
+			}
+			lastToken = token
+
+			body := authenticator.login(t, assertion.Response.Challenge, tt.origin, tt.flags, tt.signCount)
+			owner, err := FinishLogin(token, request(body))
+			switch {
+			case tt.wantErr != nil:
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("FinishLogin error = %v, want %v", err, tt.wantErr)
+				}
+			case tt.anyErr:
+				if err == nil {
+					t.Fatal("FinishLogin accepted the assertion")
+				}
+			default:
+				if err != nil {
+					t.Fatalf("FinishLogin: %v", err)
+				}
+				if owner.ID != user.ID {
+					t.Fatalf("FinishLogin returned user %d, want %d", owner.ID, user.ID)
+				}
+			}
+		})
+	}
+
+	var stored models.WebAuthnCredential
+	config.DB.First(&stored, credential.ID)
+	if stored.SignCount != 5 || !stored.CloneWarning || stored.LastUsedAt == nil {
+		t.Fatalf("stored credential after logins = %+v", stored)
+	}
+}
+
+func TestFinishRegistrationRejects(t *testing.T) {
+	tests := []struct {
+		name      string
+		otherUser bool
+		origin    string
+		challenge []byte
+		flags     byte
+		wantErr   error
+	}{
+		{name: "session of another user", otherUser: true, origin: testOrigin, flags: flagUserPresent | flagUserVerified, wantErr: ErrInvalidSession},
+		{name: "wrong challenge", origin: testOrigin, challenge: []byte("not the challenge"), flags: flagUserPresent | flagUserVerified},
+		{name: "wrong origin", origin: "https://evil.example.com", flags: flagUserPresent | flagUserVerified},
+		{name: "user not verified", origin: testOrigin, flags: flagUserPresent},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			user := setupTest(t)
+			creation, token, err := BeginRegistration(user, "")
+			if err != nil {
+				t.Fatal(err)
+			}
+			challenge := []byte(creation.Response.Challenge)
+			if tt.challenge != nil {
+				challenge = tt.challenge
+			}
+			target := user
+			if tt.otherUser {
+				target = &models.User{Email: "other@example.com", Username: "other", Password: "x"}
+				config.DB.Create(target)
+			}
+
+			body := newSoftAuthenticator(t).register(t, challenge, tt.origin, tt.flags)
+			_, err = FinishRegistration(target, token, request(body))
+			if err == nil {
+				t.Fatal("FinishRegistration accepted the credential")
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Fatalf("FinishRegistration error = %v, want %v", err, tt.wantErr)
+			}
+			var count int64
+			config.DB.Model(&models.WebAuthnCredential{}).Count(&count)
+			if count != 0 {
+				t.Fatalf("%d credentials stored, want none", count)
+			}
+		})
+	}
+}
@@ -0,0 +1,66 @@
+// passwordpolicy/breached.go
+package passwordpolicy
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// PrefixLength adalah panjang awalan hash yang menjadi nama file
+const PrefixLength = 5
+
+// Data password bocor disimpan dengan model k-anonymity seperti range API Have I Been Pwned:
+// hash SHA-1 (hex huruf besar) dikelompokkan berdasarkan 5 karakter pertamanya. Setiap
+// kelompok adalah file <AWALAN>.txt di PASSWORD_BREACHED_DIR dengan baris
+//
+//	<35 KARAKTER SISA HASH>:<JUMLAH KEMUNCULAN>
+//
+// Format ini sama dengan hasil haveibeenpwned-downloader tanpa opsi single file, dan juga
+// bisa dibuat dari daftar sendiri dengan `go run ./cmd/breached-import`. Hanya satu file kecil
+// yang dibaca untuk setiap pemeriksaan, dan password tidak pernah disimpan dalam bentuk asli.
+
+// HashPassword mengembalikan hash SHA-1 hex huruf besar yang dipakai sebagai kunci data bocor
+func HashPassword(password string) string {
+	sum := sha1.Sum([]byte(password))
+	return strings.ToUpper(hex.EncodeToString(sum[:]))
+}
+
+// breachCount mengembalikan berapa kali password muncul di data bocor, 0 jika tidak ada
+// atau dir kosong
+func breachCount(dir, password string) (int, error) {
+	if dir == "" {
+		return 0, nil
+	}
+	hash := HashPassword(password)
+	file, err := os.Open(filepath.Join(dir, hash[:PrefixLength]+".txt"))
+	if errors.Is(err, os.ErrNotExist) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+
+	suffix := hash[PrefixLength:]
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		found, count, _ := strings.Cut(line, ":")
+		if !strings.EqualFold(found, suffix) {
+			continue
+		}
+		n, err := strconv.Atoi(strings.TrimSpace(count))
+		if err != nil || n < 1 {
+			// Daftar tanpa jumlah kemunculan dihitung sebagai satu kali
+			n = 1
+		}
+		return n, nil
+	}
+	return 0, scanner.Err()
+}
@@ -0,0 +1,72 @@
+// passwordpolicy/messages.go
+package passwordpolicy
+
+import (
+	"fmt"
+	"strings"
+)
+
+// messages berisi penjelasan setiap pelanggaran per bahasa. %d diisi dengan Violation.Value.
+var messages = map[string]map[string]string{
+	"en": {
+		TooShort:         "Password must be at least %d characters long.",
+		TooLong:          "Password must be at most %d characters long.",
+		TooFewClasses:    "Password must mix at least %d of: lowercase letters, uppercase letters, digits and symbols.",
+		ContainsEmail:    "Password must not contain your email address.",
+		ContainsUsername: "Password must not contain your username.",
+		Common:           "This password is too common. Please choose a less predictable one.",
+		Breached:         "This password has appeared in a data breach and is unsafe to use. Please choose a different one.",
+	},
+	"id": {
+		TooShort:         "Password minimal %d karakter.",
+		TooLong:          "Password maksimal %d karakter.",
+		TooFewClasses:    "Password harus memuat minimal %d dari: huruf kecil, huruf besar, angka dan simbol.",
+		ContainsEmail:    "Password tidak boleh memuat alamat email Anda.",
+		ContainsUsername: "Password tidak boleh memuat username Anda.",
+		Common:           "Password ini terlalu umum. Silakan pilih password yang lebih sulit ditebak.",
+		Breached:         "Password ini pernah muncul dalam kebocoran data dan tidak aman dipakai. Silakan pilih password lain.",
+	},
+}
+
+// summaries adalah pesan utama yang menyertai daftar pelanggaran
+var summaries = map[string]string{
+	"en": "Password does not meet the requirements",
+	"id": "Password tidak memenuhi syarat",
+}
+
+// defaultLanguage dipakai jika bahasa yang diminta tidak tersedia
+const defaultLanguage = "en"
+
+// Language memilih bahasa pesan dari header Accept-Language, mis. "id-ID,id;q=0.9,en;q=0.8".
+// Urutan di header dipakai apa adanya; bobot q tidak dihitung ulang.
+func Language(acceptLanguage string) string {
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		tag, _, _ := strings.Cut(strings.TrimSpace(part), ";")
+		base, _, _ := strings.Cut(strings.ToLower(tag), "-")
+		if _, ok := messages[base]; ok {
+			return base
+		}
+	}
+	return defaultLanguage
+}
+
+// Message mengembalikan penjelasan pelanggaran dalam bahasa lang
+func (v Violation) Message(lang string) string {
+	table, ok := messages[lang]
+	if !ok {
+		table = messages[defaultLanguage]
+	}
+	format := table[v.Code]
+	if strings.Contains(format, "%d") {
+		return fmt.Sprintf(format, v.Value)
+	}
+	return format
+}
+
+// Summary mengembalikan pesan utama untuk password yang ditolak dalam bahasa lang
+func Summary(lang string) string {
+	if summary, ok := summaries[lang]; ok {
+		return summary
+	}
+	return summaries[defaultLanguage]
+}
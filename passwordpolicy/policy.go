@@ -0,0 +1,172 @@
+// passwordpolicy/policy.go
+package passwordpolicy
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/mfuadfakhruzzaki/backend-api/config"
+	"github.com/mfuadfakhruzzaki/backend-api/utils"
+)
+
+// Policy adalah aturan password yang berlaku untuk registrasi, ganti password dan reset password
+type Policy struct {
+	MinLength int // dalam karakter
+	MaxLength int // dalam karakter, tidak boleh melebihi utils.MaxPasswordLength byte
+	// MinClasses adalah jumlah minimum jenis karakter yang berbeda
+	// (huruf kecil, huruf besar, angka, simbol)
+	MinClasses int
+	// BreachedDir adalah folder hash password bocor berformat k-anonymity, lihat breached.go
+	BreachedDir string
+	// BreachedMinCount adalah berapa kali password harus muncul di data bocor untuk ditolak
+	BreachedMinCount int
+}
+
+var current = Policy{MinLength: 10, MaxLength: 128, MinClasses: 2, BreachedMinCount: 1}
+
+// Init memakai aturan dari konfigurasi (PASSWORD_MIN_LENGTH, PASSWORD_MAX_LENGTH,
+// PASSWORD_MIN_CHARACTER_CLASSES, PASSWORD_BREACHED_DIR dan PASSWORD_BREACHED_MIN_COUNT)
+func Init(cfg config.PasswordConfig) error {
+	policy := Policy{
+		MinLength:        cfg.MinLength,
+		MaxLength:        cfg.MaxLength,
+		MinClasses:       cfg.MinCharacterClasses,
+		BreachedDir:      cfg.BreachedDir,
+		BreachedMinCount: cfg.BreachedMinCount,
+	}
+
+	switch {
+	case policy.MinLength < 1:
+		return fmt.Errorf("PASSWORD_MIN_LENGTH must be at least 1")
+	case policy.MaxLength < policy.MinLength:
+		return fmt.Errorf("PASSWORD_MAX_LENGTH must not be less than PASSWORD_MIN_LENGTH")
+	case policy.BreachedMinCount < 0:
+		return fmt.Errorf("PASSWORD_BREACHED_MIN_COUNT must not be negative")
+	case policy.MaxLength*utf8.UTFMax > utils.MaxPasswordLength:
+		return fmt.Errorf("PASSWORD_MAX_LENGTH must be at most %d", utils.MaxPasswordLength/utf8.UTFMax)
+	case policy.MinClasses < 0 || policy.MinClasses > 4:
+		return fmt.Errorf("PASSWORD_MIN_CHARACTER_CLASSES must be between 0 and 4")
+	}
+	if policy.BreachedDir != "" {
+		if info, err := os.Stat(policy.BreachedDir); err != nil || !info.IsDir() {
+			return fmt.Errorf("PASSWORD_BREACHED_DIR %q is not a directory", policy.BreachedDir)
+		}
+	} else {
+		log.Println("PASSWORD_BREACHED_DIR is not set, only a built-in list of common passwords is rejected")
+	}
+
+	current = policy
+	return nil
+}
+
+// Current mengembalikan aturan yang berlaku
+func Current() Policy {
+	return current
+}
+
+// Violation adalah satu aturan yang dilanggar. Value berisi angka untuk pesan, mis. panjang minimum.
+type Violation struct {
+	Code  string `json:"code"`
+	Value int    `json:"value,omitempty"`
+}
+
+// Kode pelanggaran
+const (
+	TooShort         = "too_short"
+	TooLong          = "too_long"
+	TooFewClasses    = "too_few_classes"
+	ContainsEmail    = "contains_email"
+	ContainsUsername = "contains_username"
+	Common           = "common"
+	Breached         = "breached"
+)
+
+// Check memeriksa password terhadap aturan yang berlaku. email dan username milik pengguna
+// dipakai untuk menolak password yang memuatnya; keduanya boleh kosong.
+func Check(password, email, username string) []Violation {
+	policy := current
+	var violations []Violation
+
+	length := utf8.RuneCountInString(password)
+	if length < policy.MinLength {
+		violations = append(violations, Violation{Code: TooShort, Value: policy.MinLength})
+	}
+	if length > policy.MaxLength {
+		// Password yang terlalu panjang tidak perlu diperiksa lebih jauh
+		return append(violations, Violation{Code: TooLong, Value: policy.MaxLength})
+	}
+	if characterClasses(password) < policy.MinClasses {
+		violations = append(violations, Violation{Code: TooFewClasses, Value: policy.MinClasses})
+	}
+
+	lower := strings.ToLower(password)
+	localPart, _, _ := strings.Cut(strings.ToLower(email), "@")
+	if containsPart(lower, localPart) || (email != "" && strings.Contains(lower, strings.ToLower(email))) {
+		violations = append(violations, Violation{Code: ContainsEmail})
+	}
+	if containsPart(lower, strings.ToLower(username)) {
+		violations = append(violations, Violation{Code: ContainsUsername})
+	}
+
+	if commonPasswords[lower] {
+		violations = append(violations, Violation{Code: Common})
+	} else if count, err := breachCount(policy.BreachedDir, password); err != nil {
+		// Data bocor yang tidak terbaca tidak boleh menghalangi pengguna mengganti password
+		log.Printf("Breached password lookup failed: %v", err)
+	} else if count >= policy.BreachedMinCount && count > 0 {
+		violations = append(violations, Violation{Code: Breached})
+	}
+
+	return violations
+}
+
+// containsPart menolak bagian email atau username yang cukup panjang untuk berarti
+func containsPart(password, part string) bool {
+	return utf8.RuneCountInString(part) >= 3 && strings.Contains(password, part)
+}
+
+func characterClasses(password string) int {
+	var lower, upper, digit, other bool
+	for _, r := range password {
+		switch {
+		case unicode.IsLower(r):
+			lower = true
+		case unicode.IsUpper(r):
+			upper = true
+		case unicode.IsDigit(r):
+			digit = true
+		default:
+			other = true
+		}
+	}
+	count := 0
+	for _, present := range []bool{lower, upper, digit, other} {
+		if present {
+			count++
+		}
+	}
+	return count
+}
+
+// commonPasswords ditolak walaupun PASSWORD_BREACHED_DIR tidak diatur. Daftar ini hanya
+// berisi password populer yang lolos aturan panjang bawaan.
+var commonPasswords = map[string]bool{}
+
+func init() {
+	for _, p := range []string{
+		"1234567890", "12345678910", "123456789a", "1q2w3e4r5t", "1qaz2wsx3edc",
+		"qwertyuiop", "qwerty12345", "qwerty123456", "q1w2e3r4t5", "asdfghjkl1",
+		"password1!", "password12", "password123", "password1234", "p@ssw0rd123",
+		"passw0rd123", "iloveyou12", "iloveyou123", "welcome123", "welcome1234",
+		"letmein123", "abc1234567", "abcdefg123", "admin12345", "administrator",
+		"football123", "baseball123", "sunshine123", "princess123", "dragon1234",
+		"monkey12345", "superman123", "trustno1234", "changeme123", "zaq12wsxcde",
+		"indonesia123", "bismillah123", "sayangku123", "rahasia123", "katasandi123",
+	} {
+		commonPasswords[p] = true
+	}
+}
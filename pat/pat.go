@@ -0,0 +1,91 @@
+// pat/pat.go
+package pat
+
+import (
+	"errors"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/mfuadfakhruzzaki/backend-api/config"
+	"github.com/mfuadfakhruzzaki/backend-api/models"
+	"github.com/mfuadfakhruzzaki/backend-api/utils"
+)
+
+const (
+	// TokenPrefix menandai personal access token sehingga middleware bisa membedakannya dari JWT
+	// dan pemindai rahasia bisa menemukannya jika tidak sengaja dipublikasikan
+	TokenPrefix = "dqt_pat_"
+	// displayPrefixLength adalah panjang awal token yang disimpan untuk ditampilkan
+	displayPrefixLength = len(TokenPrefix) + 6
+	// lastUsedInterval membatasi seberapa sering LastUsedAt ditulis ke database
+	lastUsedInterval = time.Minute
+)
+
+var ErrInvalidToken = errors.New("invalid or expired personal access token")
+
+// IsToken menandai string yang berbentuk personal access token
+func IsToken(token string) bool {
+	return strings.HasPrefix(token, TokenPrefix)
+}
+
+// Generate membuat token baru dan mengembalikan token aslinya (hanya ditampilkan sekali),
+// awalan untuk ditampilkan, dan hash untuk disimpan
+func Generate() (token, prefix, hash string, err error) {
+	random, err := utils.RandomToken(32)
+	if err != nil {
+		return "", "", "", err
+	}
+	token = TokenPrefix + random
+	return token, token[:displayPrefixLength], utils.HashToken(token), nil
+}
+
+// NormalizeScopes memeriksa dan mengurutkan scope; scope yang tidak dikenal menghasilkan error
+func NormalizeScopes(scopes []string) ([]string, error) {
+	seen := map[string]bool{}
+	var normalized []string
+	for _, scope := range scopes {
+		scope = strings.ToLower(strings.TrimSpace(scope))
+		if _, ok := models.TokenScopes[scope]; !ok {
+			return nil, errors.New("unknown scope " + scope)
+		}
+		if !seen[scope] {
+			seen[scope] = true
+			normalized = append(normalized, scope)
+		}
+	}
+	if len(normalized) == 0 {
+		return nil, errors.New("at least one scope is required")
+	}
+	sort.Strings(normalized)
+	return normalized, nil
+}
+
+// Scopes mengembalikan daftar scope milik token
+func Scopes(token *models.PersonalAccessToken) []string {
+	if token.Scopes == "" {
+		return nil
+	}
+	return strings.Split(token.Scopes, ",")
+}
+
+// Authenticate mencari token yang masih berlaku beserta pemiliknya dan mencatat pemakaiannya
+func Authenticate(token, ip string) (*models.PersonalAccessToken, *models.User, error) {
+	var record models.PersonalAccessToken
+	err := config.DB.Where("token_hash = ? AND expires_at > ?", utils.HashToken(token), time.Now()).First(&record).Error
+	if err != nil {
+		return nil, nil, ErrInvalidToken
+	}
+
+	var user models.User
+	if err := config.DB.Select("id", "email").Where("deleted_at IS NULL").First(&user, record.UserID).Error; err != nil {
+		return nil, nil, ErrInvalidToken
+	}
+
+	// Hindari menulis ke database pada setiap permintaan dari skrip yang sibuk
+	now := time.Now()
+	if record.LastUsedAt == nil || now.Sub(*record.LastUsedAt) > lastUsedInterval || record.LastUsedIP != ip {
+		config.DB.Model(&record).Updates(map[string]interface{}{"last_used_at": now, "last_used_ip": ip})
+	}
+	return &record, &user, nil
+}
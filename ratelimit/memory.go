@@ -0,0 +1,90 @@
+// ratelimit/memory.go
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+// sweepInterval adalah jarak antar pembersihan bucket yang sudah penuh kembali
+const sweepInterval = time.Minute
+
+type bucket struct {
+	tokens  float64
+	updated time.Time
+	period  time.Duration
+}
+
+// MemoryStore menyimpan bucket di memori proses. Cocok untuk satu instance; jika aplikasi
+// berjalan di beberapa instance setiap instance punya batasnya sendiri.
+type MemoryStore struct {
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+	now       func() time.Time // diganti di test untuk mengatur waktu
+}
+
+// NewMemoryStore membuat store di memori
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{buckets: map[string]*bucket{}, lastSweep: time.Now(), now: time.Now}
+}
+
+// Take mengambil satu token dari bucket key
+func (s *MemoryStore) Take(_ context.Context, key string, rate Rate) (Result, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	s.sweep(now)
+
+	b, ok := s.buckets[key]
+	if !ok {
+		b = &bucket{tokens: float64(rate.Limit), updated: now}
+		s.buckets[key] = b
+	}
+	b.period = rate.Period
+	b.tokens, b.updated = refill(b.tokens, b.updated, now, rate), now
+
+	return take(&b.tokens, rate), nil
+}
+
+// sweep menghapus bucket yang sudah penuh kembali, karena isinya sama dengan bucket baru
+func (s *MemoryStore) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < sweepInterval {
+		return
+	}
+	s.lastSweep = now
+	for key, b := range s.buckets {
+		if now.Sub(b.updated) >= b.period {
+			delete(s.buckets, key)
+		}
+	}
+}
+
+// perNanosecond adalah laju pengisian bucket
+func perNanosecond(rate Rate) float64 {
+	return float64(rate.Limit) / float64(rate.Period)
+}
+
+func refill(tokens float64, updated, now time.Time, rate Rate) float64 {
+	if elapsed := now.Sub(updated); elapsed > 0 {
+		tokens += float64(elapsed) * perNanosecond(rate)
+	}
+	return math.Min(tokens, float64(rate.Limit))
+}
+
+// take mengambil satu token jika ada dan menghitung Result
+func take(tokens *float64, rate Rate) Result {
+	result := Result{}
+	if *tokens >= 1 {
+		*tokens--
+		result.Allowed = true
+	} else {
+		result.RetryAfter = time.Duration(math.Ceil((1 - *tokens) / perNanosecond(rate)))
+	}
+	result.Remaining = int(math.Floor(*tokens))
+	result.Reset = time.Duration(math.Ceil((float64(rate.Limit) - *tokens) / perNanosecond(rate)))
+	return result
+}
@@ -0,0 +1,196 @@
+// ratelimit/ratelimit.go
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mfuadfakhruzzaki/backend-api/config"
+)
+
+// Rate adalah kebijakan token bucket: paling banyak Limit permintaan sekaligus, dan
+// bucket terisi kembali penuh dalam Period
+type Rate struct {
+	Limit  int
+	Period time.Duration
+}
+
+// String mengembalikan rate dalam format yang sama dengan environment variable, mis. "5/1h0m0s"
+func (r Rate) String() string {
+	return fmt.Sprintf("%d/%s", r.Limit, r.Period)
+}
+
+// Result adalah hasil pengambilan satu token dari bucket
+type Result struct {
+	Allowed   bool
+	Remaining int
+	// RetryAfter adalah waktu sampai satu token tersedia lagi, hanya diisi jika ditolak
+	RetryAfter time.Duration
+	// Reset adalah waktu sampai bucket penuh lagi
+	Reset time.Duration
+}
+
+// Store adalah backend penyimpanan bucket. Take harus atomik untuk satu key sehingga
+// permintaan bersamaan (juga dari beberapa instance aplikasi untuk store bersama) tidak
+// bisa melebihi batas.
+type Store interface {
+	Take(ctx context.Context, key string, rate Rate) (Result, error)
+}
+
+// Default adalah store yang dipakai middleware, diganti oleh Init
+var Default Store = NewMemoryStore()
+
+// defaultPolicies adalah batas bawaan per grup route, bisa ditimpa dengan RATE_LIMIT_<NAMA>
+var defaultPolicies = map[string]Rate{
+	"global":   {Limit: 300, Period: time.Minute}, // semua permintaan per IP
+	"register": {Limit: 5, Period: time.Hour},     // setiap registrasi mengirim email
+	"login":    {Limit: 20, Period: time.Minute},  // login dan langkah kedua login
+	"verify":   {Limit: 10, Period: 15 * time.Minute},
+	"email":    {Limit: 5, Period: 15 * time.Minute}, // endpoint yang mengirim email
+	"api":      {Limit: 600, Period: time.Minute},    // API yang membutuhkan login, per pengguna
+	"export":   {Limit: 5, Period: time.Hour},
+	"upload":   {Limit: 20, Period: time.Hour},
+}
+
+var (
+	mu       sync.RWMutex
+	policies = copyPolicies(defaultPolicies)
+)
+
+// Init memilih store dari cfg.Store (RATE_LIMIT_STORE, "memory" atau "redis") dan memakai
+// batas per grup route dari cfg.Policies (RATE_LIMIT_<NAMA>), mis. RATE_LIMIT_REGISTER=3/1h
+// atau RATE_LIMIT_GLOBAL=off. Store redis memakai RATE_LIMIT_REDIS_URL
+// (mis. redis://:password@localhost:6379/0) dan dibutuhkan jika aplikasi berjalan
+// di lebih dari satu instance.
+func Init(cfg config.RateLimitConfig) error {
+	loaded := copyPolicies(defaultPolicies)
+	for name, value := range cfg.Policies {
+		name = strings.ToLower(name)
+		if _, ok := defaultPolicies[name]; !ok {
+			return fmt.Errorf("RATE_LIMIT_%s: unknown rate limit group %q", strings.ToUpper(name), name)
+		}
+		if strings.EqualFold(value, "off") {
+			delete(loaded, name)
+			continue
+		}
+		rate, err := ParseRate(value)
+		if err != nil {
+			return fmt.Errorf("RATE_LIMIT_%s: %w", strings.ToUpper(name), err)
+		}
+		loaded[name] = rate
+	}
+
+	store, err := New(cfg)
+	if err != nil {
+		return err
+	}
+
+	mu.Lock()
+	policies = loaded
+	Default = store
+	mu.Unlock()
+	return nil
+}
+
+// New membuat store dari konfigurasi
+func New(cfg config.RateLimitConfig) (Store, error) {
+	switch cfg.Store {
+	case "", "memory":
+		return NewMemoryStore(), nil
+	case "redis":
+		return NewRedisStore(cfg.RedisURL)
+	default:
+		return nil, fmt.Errorf("unknown RATE_LIMIT_STORE %q", cfg.Store)
+	}
+}
+
+// Close menutup koneksi store yang dipakai, mis. ke Redis, saat aplikasi dimatikan
+func Close() error {
+	mu.RLock()
+	store := Default
+	mu.RUnlock()
+	if closer, ok := store.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
+
+// ParseRate membaca rate dalam format "<limit>/<periode>", mis. "5/1h" atau "100/1m"
+func ParseRate(value string) (Rate, error) {
+	limit, period, ok := strings.Cut(value, "/")
+	if !ok {
+		return Rate{}, fmt.Errorf("invalid rate %q, expected <limit>/<period> such as 5/1h", value)
+	}
+	n, err := strconv.Atoi(strings.TrimSpace(limit))
+	if err != nil || n < 1 {
+		return Rate{}, fmt.Errorf("invalid limit in rate %q", value)
+	}
+	d, err := time.ParseDuration(strings.TrimSpace(period))
+	if err != nil || d <= 0 {
+		return Rate{}, fmt.Errorf("invalid period in rate %q", value)
+	}
+	return Rate{Limit: n, Period: d}, nil
+}
+
+// Take mengambil satu token dari bucket grup route policy untuk satu subjek, mis. "ip:1.2.3.4"
+// atau "user:42". ok bernilai false jika grup tersebut tidak dibatasi. Jika store gagal,
+// permintaan tetap diizinkan supaya gangguan pada Redis tidak mematikan seluruh API.
+func Take(ctx context.Context, policy, subject string) (rate Rate, result Result, ok bool) {
+	mu.RLock()
+	rate, ok = policies[policy]
+	store := Default
+	mu.RUnlock()
+	if !ok {
+		return rate, result, false
+	}
+
+	result, err := store.Take(ctx, "ratelimit:"+policy+":"+subject, rate)
+	if err != nil {
+		logStoreError(err)
+		return rate, Result{Allowed: true, Remaining: rate.Limit}, true
+	}
+	return rate, result, true
+}
+
+// Policies mengembalikan semua batas yang aktif, terurut berdasarkan nama, untuk dicatat saat startup
+func Policies() []string {
+	mu.RLock()
+	defer mu.RUnlock()
+	list := make([]string, 0, len(policies))
+	for name, rate := range policies {
+		list = append(list, name+"="+rate.String())
+	}
+	sort.Strings(list)
+	return list
+}
+
+func copyPolicies(src map[string]Rate) map[string]Rate {
+	dst := make(map[string]Rate, len(src))
+	for name, rate := range src {
+		dst[name] = rate
+	}
+	return dst
+}
+
+var (
+	errMu          sync.Mutex
+	lastStoreError time.Time
+)
+
+// logStoreError mencatat kegagalan store paling banyak sekali per menit
+func logStoreError(err error) {
+	errMu.Lock()
+	defer errMu.Unlock()
+	if time.Since(lastStoreError) < time.Minute {
+		return
+	}
+	lastStoreError = time.Now()
+	log.Printf("Rate limit store error, requests are let through: %v", err)
+}
@@ -0,0 +1,163 @@
+// ratelimit/ratelimit_test.go
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+// testStore adalah store dengan jam yang bisa dimajukan oleh test
+type testStore struct {
+	Store
+	advance func(time.Duration)
+}
+
+func newMemoryTestStore(t *testing.T) testStore {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	store := NewMemoryStore()
+	store.now = func() time.Time { return now }
+	return testStore{Store: store, advance: func(d time.Duration) { now = now.Add(d) }}
+}
+
+// newRedisTestStore memakai miniredis sebagai pengganti Redis; TIME di script mengikuti SetTime
+func newRedisTestStore(t *testing.T) testStore {
+	server := miniredis.RunT(t)
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	server.SetTime(now)
+
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { client.Close() })
+	return testStore{
+		Store: NewRedisStoreWithClient(client),
+		advance: func(d time.Duration) {
+			now = now.Add(d)
+			server.SetTime(now)
+		},
+	}
+}
+
+func TestTokenBucketRefill(t *testing.T) {
+	// Tiga token yang terisi kembali satu per detik
+	rate := Rate{Limit: 3, Period: 3 * time.Second}
+
+	type step struct {
+		advance       time.Duration
+		wantAllowed   bool
+		wantRemaining int
+		wantRetry     time.Duration
+	}
+	tests := []struct {
+		name  string
+		steps []step
+	}{
+		{
+			name: "burst up to the limit",
+			steps: []step{
+				{wantAllowed: true, wantRemaining: 2},
+				{wantAllowed: true, wantRemaining: 1},
+				{wantAllowed: true, wantRemaining: 0},
+				{wantAllowed: false, wantRemaining: 0, wantRetry: time.Second},
+			},
+		},
+		{
+			name: "partial refill",
+			steps: []step{
+				{wantAllowed: true, wantRemaining: 2},
+				{wantAllowed: true, wantRemaining: 1},
+				{wantAllowed: true, wantRemaining: 0},
+				{advance: 500 * time.Millisecond, wantAllowed: false, wantRemaining: 0, wantRetry: 500 * time.Millisecond},
+				{advance: 500 * time.Millisecond, wantAllowed: true, wantRemaining: 0},
+				{advance: 2 * time.Second, wantAllowed: true, wantRemaining: 1},
+			},
+		},
+		{
+			name: "refill is capped at the limit",
+			steps: []step{
+				{wantAllowed: true, wantRemaining: 2},
+				{advance: time.Hour, wantAllowed: true, wantRemaining: 2},
+				{wantAllowed: true, wantRemaining: 1},
+			},
+		},
+	}
+
+	stores := []struct {
+		name string
+		new  func(*testing.T) testStore
+	}{
+		{name: "memory", new: newMemoryTestStore},
+		{name: "redis", new: newRedisTestStore},
+	}
+	for _, s := range stores {
+		for _, tt := range tests {
+			t.Run(s.name+"/"+tt.name, func(t *testing.T) {
+				store := s.new(t)
+				for i, step := range tt.steps {
+					store.advance(step.advance)
+					result, err := store.Take(context.Background(), "ratelimit:test:ip:1.2.3.4", rate)
+					if err != nil {
+						t.Fatalf("step %d: %v", i, err)
+					}
+					if result.Allowed != step.wantAllowed || result.Remaining != step.wantRemaining {
+						t.Fatalf("step %d: allowed=%v remaining=%d, want allowed=%v remaining=%d",
+							i, result.Allowed, result.Remaining, step.wantAllowed, step.wantRemaining)
+					}
+					if !step.wantAllowed && result.RetryAfter != step.wantRetry {
+						t.Fatalf("step %d: RetryAfter = %s, want %s", i, result.RetryAfter, step.wantRetry)
+					}
+				}
+			})
+		}
+	}
+}
+
+func TestBucketsAreSeparatePerKey(t *testing.T) {
+	rate := Rate{Limit: 1, Period: time.Minute}
+	for _, s := range []struct {
+		name string
+		new  func(*testing.T) testStore
+	}{{"memory", newMemoryTestStore}, {"redis", newRedisTestStore}} {
+		t.Run(s.name, func(t *testing.T) {
+			store := s.new(t)
+			ctx := context.Background()
+			for _, key := range []string{"ratelimit:login:ip:1.1.1.1", "ratelimit:login:ip:2.2.2.2"} {
+				if result, err := store.Take(ctx, key, rate); err != nil || !result.Allowed {
+					t.Fatalf("first request for %s: %+v, %v", key, result, err)
+				}
+			}
+			if result, _ := store.Take(ctx, "ratelimit:login:ip:1.1.1.1", rate); result.Allowed {
+				t.Fatal("second request for the same key was allowed")
+			}
+		})
+	}
+}
+
+func TestParseRate(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    Rate
+		wantErr bool
+	}{
+		{value: "5/1h", want: Rate{Limit: 5, Period: time.Hour}},
+		{value: " 100 / 1m ", want: Rate{Limit: 100, Period: time.Minute}},
+		{value: "5", wantErr: true},
+		{value: "0/1m", wantErr: true},
+		{value: "x/1m", wantErr: true},
+		{value: "5/soon", wantErr: true},
+		{value: "5/-1m", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := ParseRate(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseRate(%q) error = %v, wantErr %v", tt.value, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Fatalf("ParseRate(%q) = %v, want %v", tt.value, got, tt.want)
+			}
+		})
+	}
+}
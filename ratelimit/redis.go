@@ -0,0 +1,89 @@
+// ratelimit/redis.go
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// tokenBucketScript menjalankan satu pengambilan token secara atomik di Redis. Waktu diambil
+// dari server Redis sehingga jam yang berbeda antar instance aplikasi tidak berpengaruh.
+//
+//	KEYS[1] = key bucket, ARGV[1] = limit, ARGV[2] = periode dalam milidetik
+//	hasil   = {diizinkan (0/1), sisa token, retry after ms, reset ms}
+var tokenBucketScript = redis.NewScript(`
+local limit = tonumber(ARGV[1])
+local period = tonumber(ARGV[2])
+local t = redis.call('TIME')
+local now = tonumber(t[1]) * 1000 + math.floor(tonumber(t[2]) / 1000)
+local rate = limit / period
+
+local state = redis.call('HMGET', KEYS[1], 'tokens', 'ts')
+local tokens = tonumber(state[1])
+local ts = tonumber(state[2])
+if tokens == nil or ts == nil then
+	tokens = limit
+	ts = now
+end
+tokens = math.min(limit, tokens + math.max(0, now - ts) * rate)
+
+local allowed = 0
+local retry = 0
+if tokens >= 1 then
+	tokens = tokens - 1
+	allowed = 1
+else
+	retry = math.ceil((1 - tokens) / rate)
+end
+
+redis.call('HSET', KEYS[1], 'tokens', tostring(tokens), 'ts', now)
+redis.call('PEXPIRE', KEYS[1], period)
+return {allowed, math.floor(tokens), retry, math.ceil((limit - tokens) / rate)}
+`)
+
+// RedisStore menyimpan bucket di Redis sehingga batas berlaku bersama untuk semua instance aplikasi
+type RedisStore struct {
+	client redis.UniversalClient
+}
+
+// NewRedisStore membuat store Redis dari URL seperti redis://:password@localhost:6379/0
+func NewRedisStore(url string) (*RedisStore, error) {
+	if url == "" {
+		return nil, errors.New("RATE_LIMIT_REDIS_URL is required for the redis rate limit store")
+	}
+	options, err := redis.ParseURL(url)
+	if err != nil {
+		return nil, err
+	}
+	return NewRedisStoreWithClient(redis.NewClient(options)), nil
+}
+
+// NewRedisStoreWithClient membuat store dari client Redis yang sudah ada
+func NewRedisStoreWithClient(client redis.UniversalClient) *RedisStore {
+	return &RedisStore{client: client}
+}
+
+// Close menutup koneksi ke Redis
+func (s *RedisStore) Close() error {
+	return s.client.Close()
+}
+
+// Take mengambil satu token dari bucket key
+func (s *RedisStore) Take(ctx context.Context, key string, rate Rate) (Result, error) {
+	values, err := tokenBucketScript.Run(ctx, s.client, []string{key}, rate.Limit, rate.Period.Milliseconds()).Int64Slice()
+	if err != nil {
+		return Result{}, err
+	}
+	if len(values) != 4 {
+		return Result{}, errors.New("unexpected rate limit script result")
+	}
+	return Result{
+		Allowed:    values[0] == 1,
+		Remaining:  int(values[1]),
+		RetryAfter: time.Duration(values[2]) * time.Millisecond,
+		Reset:      time.Duration(values[3]) * time.Millisecond,
+	}, nil
+}
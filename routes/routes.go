@@ -6,47 +6,140 @@ import (
 
 	"github.com/mfuadfakhruzzaki/backend-api/controllers"
 	"github.com/mfuadfakhruzzaki/backend-api/middleware"
+	"github.com/mfuadfakhruzzaki/backend-api/models"
 )
 
 func RegisterRoutes(router *gin.Engine) {
 	// Set up CORS middleware
 	router.Use(cors.New(cors.Config{
 		AllowOrigins:     []string{"*"}, 
-		AllowMethods:     []string{"GET", "POST", "PUT", "DELETE"},
+		AllowMethods:     []string{"GET", "POST", "PUT", "PATCH", "DELETE"},
 		AllowHeaders:     []string{"Origin", "Content-Type", "Authorization"},
 		ExposeHeaders:    []string{"Content-Length"},
 		AllowCredentials: true,
 	}))
 
+	// Rate limits per IP for everything, and stricter limits per route group (see ratelimit package)
+	router.Use(middleware.RateLimit("global", middleware.KeyByIP))
+	registerLimit := middleware.RateLimit("register", middleware.KeyByIP)
+	loginLimit := middleware.RateLimit("login", middleware.KeyByIP)
+	verifyLimit := middleware.RateLimit("verify", middleware.KeyByIP)
+	emailLimit := middleware.RateLimit("email", middleware.KeyByIP)
+	userVerifyLimit := middleware.RateLimit("verify", middleware.KeyByUser)
+	userEmailLimit := middleware.RateLimit("email", middleware.KeyByUser)
+	uploadLimit := middleware.RateLimit("upload", middleware.KeyByUser)
+	exportLimit := middleware.RateLimit("export", middleware.KeyByUser)
+
 	// Public Routes
 	public := router.Group("/")
 	{
 		// Registration and Login Endpoints
-		public.POST("/auth/register", controllers.Register)      // Konsisten menggunakan /auth/
-		public.POST("/auth/login", controllers.Login)
+		public.POST("/auth/register", registerLimit, controllers.Register)      // Konsisten menggunakan /auth/
+		public.POST("/auth/login", loginLimit, controllers.Login)
+		public.POST("/auth/2fa/verify", loginLimit, controllers.VerifyTwoFactorLogin) // Second step of login with 2FA
+
+		// Password Reset Endpoints
+		public.POST("/auth/password/forgot", emailLimit, controllers.ForgotPassword)
+		public.POST("/auth/password/reset", verifyLimit, controllers.ResetPassword)
+
+		// Passwordless Email Login Endpoints
+		public.POST("/auth/magic-link", emailLimit, controllers.RequestMagicLink)
+		public.POST("/auth/magic-link/verify", loginLimit, controllers.VerifyMagicLink)
+
+		// Passkey (WebAuthn) Login Endpoints
+		public.POST("/auth/passkey/login/begin", loginLimit, controllers.BeginPasskeyLogin)
+		public.POST("/auth/passkey/login/finish", loginLimit, controllers.FinishPasskeyLogin)
 
 		// Endpoint untuk verifikasi email
-		public.POST("/auth/verify-email", controllers.VerifyEmail)
+		public.POST("/auth/verify-email", verifyLimit, controllers.VerifyEmail)
 
-		// OAuth Google Endpoints
-		public.GET("/auth/google/login", controllers.GoogleLogin)
-		public.GET("/auth/google/callback", controllers.GoogleCallback)
+		// OAuth2/OIDC Endpoints (google, github, dan penyedia lain dari OAUTH_PROVIDERS)
+		public.GET("/auth/providers", controllers.OAuthProviders)
+		public.GET("/auth/:provider/login", loginLimit, controllers.OAuthLogin)
+		public.GET("/auth/:provider/callback", loginLimit, controllers.OAuthCallback)
 
-		// OAuth GitHub Endpoints
-		public.GET("/auth/github/login", controllers.GithubLogin)
-		public.GET("/auth/github/callback", controllers.GithubCallback)
+		// Public keys for verifying our tokens from other services
+		public.GET("/.well-known/jwks.json", controllers.JWKS)
 	}
 
-	// Protected Routes with JWT Middleware
+	// Protected Routes with JWT Middleware. Personal access tokens can only call the routes
+	// below that declare a scope with RequireScope.
 	api := router.Group("/api")
 	api.Use(middleware.JWTMiddleware()) // JWT Middleware untuk proteksi endpoint
+	api.Use(middleware.RateLimit("api", middleware.KeyByUser))
 	{
 		// Package Endpoints
-		api.GET("/packages", controllers.GetPackages)              // Get all packages
-		api.POST("/packages/:id/select", controllers.SelectPackage) // Select package by ID
+		api.GET("/packages", middleware.RequireScope(models.ScopePackagesRead), controllers.GetPackages)              // Get all packages
+		api.POST("/packages/:id/select", middleware.RequireScope(models.ScopePackagesWrite), controllers.SelectPackage) // Select package by ID
 
 		// User Endpoints
-		api.POST("/users/profile/picture", controllers.UploadProfilePicture) // Upload profile picture
-		api.GET("/users/profile", controllers.GetProfile)                    // Get user profile
+		api.POST("/users/profile/picture", uploadLimit, controllers.UploadProfilePicture) // Upload profile picture
+		api.GET("/users/profile", middleware.RequireScope(models.ScopeProfileRead), controllers.GetProfile)                    // Get user profile
+		api.PATCH("/users/profile", controllers.UpdateProfile)               // Update username and phone number
+		api.PUT("/users/password", controllers.ChangePassword)               // Change password
+		api.POST("/users/email", userEmailLimit, controllers.RequestEmailChange)             // Request email change
+		api.POST("/users/email/verify", userVerifyLimit, controllers.ConfirmEmailChange)      // Confirm email change with code
+		api.GET("/users/export", middleware.NoImpersonation(), exportLimit, controllers.ExportUserData) // Export personal data (JSON/ZIP)
+		api.POST("/users/deletion", controllers.RequestAccountDeletion)      // Schedule account deletion
+		api.DELETE("/users/deletion", controllers.CancelAccountDeletion)     // Cancel scheduled deletion
+
+		// Two-Factor Authentication Endpoints
+		api.GET("/users/2fa", controllers.GetTwoFactorStatus)
+		api.POST("/users/2fa/setup", controllers.SetupTwoFactor)
+		api.POST("/users/2fa/enable", userVerifyLimit, controllers.EnableTwoFactor)
+		api.POST("/users/2fa/disable", userVerifyLimit, controllers.DisableTwoFactor)
+		api.POST("/users/2fa/recovery-codes", controllers.RegenerateRecoveryCodes)
+
+		// Passkey Endpoints
+		api.GET("/users/passkeys", controllers.GetPasskeys)
+		api.POST("/users/passkeys/register/begin", controllers.BeginPasskeyRegistration)
+		api.POST("/users/passkeys/register/finish", controllers.FinishPasskeyRegistration)
+		api.DELETE("/users/passkeys/:id", controllers.DeletePasskey)
+
+		// Linked OAuth Identity Endpoints
+		api.GET("/users/identities", controllers.GetIdentities)
+		api.POST("/users/identities/:provider/link", controllers.LinkIdentity)
+		api.DELETE("/users/identities/:id", controllers.UnlinkIdentity)
+
+		// Personal Access Token Endpoints (session only, tokens cannot manage tokens)
+		api.GET("/users/tokens/scopes", controllers.GetTokenScopes)
+		api.GET("/users/tokens", controllers.GetPersonalAccessTokens)
+		api.POST("/users/tokens", controllers.CreatePersonalAccessToken)
+		api.DELETE("/users/tokens/:id", controllers.DeletePersonalAccessToken)
+
+		// Session Endpoints (session only)
+		api.GET("/users/sessions", controllers.GetSessions)
+		api.DELETE("/users/sessions", controllers.RevokeOtherSessions)
+		api.DELETE("/users/sessions/:id", controllers.RevokeSession)
+		api.GET("/users/activity", controllers.GetRecentActivity) // Recent security events on the account
+
+		// Organization Endpoints
+		api.POST("/organizations", middleware.RequireScope(models.ScopeOrganizationsWrite), controllers.CreateOrganization)
+		api.GET("/organizations", middleware.RequireScope(models.ScopeOrganizationsRead), controllers.GetOrganizations)
+		api.POST("/organizations/:id/members", middleware.RequireScope(models.ScopeOrganizationsWrite), controllers.AddOrganizationMember)
+		api.GET("/organizations/:id/lines", middleware.RequireScope(models.ScopeOrganizationsRead), controllers.GetOrganizationLines)
+		api.POST("/organizations/:id/lines/import", middleware.RequireScope(models.ScopeOrganizationsWrite), uploadLimit, controllers.ImportOrganizationLines)   // CSV bulk import
+		api.POST("/organizations/:id/lines/package", middleware.RequireScope(models.ScopeOrganizationsWrite), controllers.AssignPackageToLines)     // Bulk package assignment
+		api.PUT("/organizations/:id/lines/:lineId/spend-limit", middleware.RequireScope(models.ScopeOrganizationsWrite), controllers.UpdateLineSpendLimit)
+		api.POST("/organizations/:id/lines/:lineId/usage", middleware.RequireScope(models.ScopeUsageWrite), controllers.RecordLineUsage)
+		api.GET("/organizations/:id/report", middleware.RequireScope(models.ScopeUsageRead), controllers.GetOrganizationReport)            // Usage and billing report
+	}
+
+	// Admin Routes (JWT + admin role)
+	admin := api.Group("/admin")
+	admin.Use(middleware.AdminMiddleware())
+	{
+		admin.GET("/users", controllers.AdminSearchUsers)                           // Search by email, username or phone
+		admin.GET("/users/:id", controllers.AdminGetUser)                           // Package, verification and login methods
+		admin.POST("/users/:id/verify-email", controllers.AdminVerifyEmail)         // Manually verify an email address
+		admin.POST("/users/:id/verify-phone", controllers.AdminVerifyPhone)         // Manually verify a phone number
+		admin.PUT("/users/:id/status", controllers.AdminSetUserStatus)              // Suspend, ban or reactivate
+		admin.POST("/users/:id/logout", controllers.AdminLogoutUser)                // Revoke all sessions
+		admin.POST("/users/:id/password-reset", controllers.AdminSendPasswordReset) // Email a password reset link
+		admin.POST("/users/:id/impersonate", controllers.AdminImpersonateUser)      // Read-only token acting as the user
+		admin.DELETE("/users/:id/2fa", controllers.AdminResetTwoFactor)             // Reset 2FA for a user who lost their device
+		admin.DELETE("/users/:id/lockout", controllers.AdminUnlockUser)             // Lift a failed-login lockout
+		admin.GET("/audit-logs", controllers.AdminGetAuditLogs)                     // Search the security audit log
+		admin.GET("/config", controllers.AdminGetConfig)                            // Running configuration, secrets redacted
 	}
 }
@@ -0,0 +1,32 @@
+// seeds/admins.go
+package seeds
+
+import (
+	"fmt"
+
+	"github.com/mfuadfakhruzzaki/backend-api/config"
+	"github.com/mfuadfakhruzzaki/backend-api/models"
+)
+
+// SeedAdmins memberi role admin kepada akun yang emailnya ada di ADMIN_EMAILS (dipisahkan koma,
+// config.App.Account.AdminEmails).
+// Akun harus sudah terdaftar dan emailnya terverifikasi, supaya orang lain tidak bisa mendaftar
+// dengan alamat itu lalu menjadi admin. Role admin tidak dicabut otomatis jika email dihapus
+// dari daftar.
+func SeedAdmins() {
+	emails := config.App.Account.AdminEmails
+	if len(emails) == 0 {
+		return
+	}
+
+	result := config.DB.Model(&models.User{}).
+		Where("LOWER(email) IN ? AND email_verified = ? AND role <> ?", emails, true, models.RoleAdmin).
+		Update("role", models.RoleAdmin)
+	if result.Error != nil {
+		fmt.Printf("Gagal memberi role admin: %v\n", result.Error)
+		return
+	}
+	if result.RowsAffected > 0 {
+		fmt.Printf("%d akun diberi role admin.\n", result.RowsAffected)
+	}
+}
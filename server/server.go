@@ -0,0 +1,65 @@
+// server/server.go
+package server
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"net/http"
+
+	"github.com/mfuadfakhruzzaki/backend-api/config"
+)
+
+// Server adalah server HTTP(S) aplikasi dengan timeout dari konfigurasi
+type Server struct {
+	http *http.Server
+	tls  bool
+}
+
+// New membuat server untuk handler. Jika TLS diaktifkan, sertifikat langsung dibaca sehingga
+// file yang salah ditolak saat aplikasi dijalankan.
+func New(cfg config.ServerConfig, handler http.Handler) (*Server, error) {
+	srv := &http.Server{
+		Addr:              cfg.Addr(),
+		Handler:           handler,
+		ReadTimeout:       cfg.ReadTimeout,
+		ReadHeaderTimeout: cfg.ReadHeaderTimeout,
+		WriteTimeout:      cfg.WriteTimeout,
+		IdleTimeout:       cfg.IdleTimeout,
+	}
+	if cfg.TLSEnabled() {
+		certs, err := newCertReloader(cfg.TLSCertFile, cfg.TLSKeyFile)
+		if err != nil {
+			return nil, err
+		}
+		srv.TLSConfig = &tls.Config{MinVersion: tls.VersionTLS12, GetCertificate: certs.GetCertificate}
+	}
+	return &Server{http: srv, tls: cfg.TLSEnabled()}, nil
+}
+
+// Addr mengembalikan alamat listen server
+func (s *Server) Addr() string {
+	return s.http.Addr
+}
+
+// ListenAndServe menerima koneksi sampai Shutdown dipanggil. Setelah Shutdown, nilai
+// kembaliannya nil.
+func (s *Server) ListenAndServe() error {
+	var err error
+	if s.tls {
+		// Sertifikat diambil dari TLSConfig.GetCertificate, bukan dari file
+		err = s.http.ListenAndServeTLS("", "")
+	} else {
+		err = s.http.ListenAndServe()
+	}
+	if errors.Is(err, http.ErrServerClosed) {
+		return nil
+	}
+	return err
+}
+
+// Shutdown berhenti menerima koneksi baru dan menunggu permintaan yang sedang berjalan
+// selesai, paling lama sampai ctx berakhir
+func (s *Server) Shutdown(ctx context.Context) error {
+	return s.http.Shutdown(ctx)
+}
@@ -0,0 +1,85 @@
+// server/tls.go
+package server
+
+import (
+	"crypto/tls"
+	"fmt"
+	"log"
+	"os"
+	"sync"
+	"time"
+)
+
+// certCheckInterval adalah jarak minimum antara dua pemeriksaan perubahan file sertifikat
+const certCheckInterval = 30 * time.Second
+
+// certReloader menyajikan sertifikat TLS dari file dan membacanya ulang jika file berubah,
+// mis. setelah diperpanjang oleh certbot atau cert-manager
+type certReloader struct {
+	certFile string
+	keyFile  string
+
+	mu      sync.Mutex
+	cert    *tls.Certificate
+	modTime time.Time // waktu perubahan terbaru dari kedua file saat terakhir dibaca
+	checked time.Time
+}
+
+func newCertReloader(certFile, keyFile string) (*certReloader, error) {
+	r := &certReloader{certFile: certFile, keyFile: keyFile}
+	modTime, err := r.latestModTime()
+	if err != nil {
+		return nil, err
+	}
+	if err := r.load(modTime); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// GetCertificate dipakai sebagai tls.Config.GetCertificate. Jika sertifikat baru gagal dibaca,
+// sertifikat lama tetap dipakai supaya server tidak berhenti melayani HTTPS.
+func (r *certReloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if now := time.Now(); now.Sub(r.checked) >= certCheckInterval {
+		r.checked = now
+		modTime, err := r.latestModTime()
+		if err != nil {
+			log.Printf("Failed to check TLS certificate, keeping the current one: %v", err)
+		} else if !modTime.Equal(r.modTime) {
+			if err := r.load(modTime); err != nil {
+				log.Printf("Failed to reload TLS certificate, keeping the current one: %v", err)
+			} else {
+				log.Printf("Reloaded TLS certificate from %s", r.certFile)
+			}
+		}
+	}
+	return r.cert, nil
+}
+
+// load membaca pasangan sertifikat dan kunci; pemanggil memegang r.mu kecuali saat inisialisasi
+func (r *certReloader) load(modTime time.Time) error {
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return fmt.Errorf("loading TLS certificate %s: %w", r.certFile, err)
+	}
+	r.cert = &cert
+	r.modTime = modTime
+	return nil
+}
+
+func (r *certReloader) latestModTime() (time.Time, error) {
+	var latest time.Time
+	for _, path := range []string{r.certFile, r.keyFile} {
+		info, err := os.Stat(path)
+		if err != nil {
+			return time.Time{}, err
+		}
+		if info.ModTime().After(latest) {
+			latest = info.ModTime()
+		}
+	}
+	return latest, nil
+}
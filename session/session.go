@@ -0,0 +1,110 @@
+// session/session.go
+package session
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/mfuadfakhruzzaki/backend-api/config"
+	"github.com/mfuadfakhruzzaki/backend-api/models"
+	"github.com/mfuadfakhruzzaki/backend-api/utils"
+)
+
+const (
+	// activityInterval membatasi seberapa sering LastActiveAt ditulis ke database
+	activityInterval = time.Minute
+	// retention adalah lama sesi yang kedaluwarsa atau dicabut tetap disimpan sebelum dihapus
+	retention = 30 * 24 * time.Hour
+	// maxDeviceNameLength sama dengan ukuran kolom DeviceName
+	maxDeviceNameLength = 100
+	// maxUserAgentLength sama dengan ukuran kolom UserAgent
+	maxUserAgentLength = 512
+)
+
+var ErrInvalidSession = errors.New("session has been revoked or has expired")
+
+// Device menjelaskan dari mana login dilakukan
+type Device struct {
+	// Name adalah nama yang diberikan klien (header X-Device-Name); jika kosong
+	// nama dibentuk dari UserAgent
+	Name      string
+	UserAgent string
+	IP        string
+}
+
+// Create mencatat sesi baru untuk login dengan metode tertentu dan mengembalikannya.
+// PublicID sesi dimasukkan ke token akses sebagai claim sid.
+func Create(userID uint, method string, device Device) (*models.Session, error) {
+	publicID, err := utils.RandomToken(24)
+	if err != nil {
+		return nil, err
+	}
+
+	name := strings.TrimSpace(device.Name)
+	if name == "" {
+		name = utils.DeviceName(device.UserAgent)
+	}
+
+	now := time.Now()
+	s := models.Session{
+		UserID:       userID,
+		PublicID:     publicID,
+		Method:       method,
+		DeviceName:   utils.Truncate(name, maxDeviceNameLength),
+		UserAgent:    utils.Truncate(device.UserAgent, maxUserAgentLength),
+		IPAddress:    device.IP,
+		LastActiveAt: now,
+		ExpiresAt:    now.Add(utils.TokenTTL),
+	}
+	if err := config.DB.Create(&s).Error; err != nil {
+		return nil, err
+	}
+
+	// Bersihkan sesi lama milik pengguna ini
+	config.DB.Where("user_id = ? AND (expires_at < ? OR revoked_at < ?)", userID, now.Add(-retention), now.Add(-retention)).
+		Delete(&models.Session{})
+
+	return &s, nil
+}
+
+// Validate memeriksa bahwa sesi milik pengguna masih aktif dan mencatat aktivitasnya
+func Validate(publicID string, userID uint, ip string) error {
+	var s models.Session
+	err := config.DB.Where("public_id = ? AND user_id = ? AND revoked_at IS NULL AND expires_at > ?", publicID, userID, time.Now()).
+		First(&s).Error
+	if err != nil {
+		return ErrInvalidSession
+	}
+
+	// Hindari menulis ke database pada setiap permintaan
+	now := time.Now()
+	if now.Sub(s.LastActiveAt) > activityInterval || s.IPAddress != ip {
+		config.DB.Model(&s).Updates(map[string]interface{}{"last_active_at": now, "ip_address": ip})
+	}
+	return nil
+}
+
+// Extend memperpanjang sesi saat token baru diterbitkan untuk sesi yang sama
+func Extend(publicID string) error {
+	return config.DB.Model(&models.Session{}).Where("public_id = ? AND revoked_at IS NULL", publicID).
+		Update("expires_at", time.Now().Add(utils.TokenTTL)).Error
+}
+
+// Revoke mencabut satu sesi milik pengguna; mengembalikan false jika sesi tidak ditemukan
+// atau sudah tidak aktif
+func Revoke(userID, sessionID uint) (bool, error) {
+	result := config.DB.Model(&models.Session{}).
+		Where("id = ? AND user_id = ? AND revoked_at IS NULL", sessionID, userID).
+		Update("revoked_at", time.Now())
+	return result.RowsAffected == 1, result.Error
+}
+
+// RevokeAll mencabut semua sesi aktif pengguna kecuali sesi dengan PublicID exceptPublicID
+// (boleh kosong untuk mencabut semuanya), mis. setelah password diganti
+func RevokeAll(userID uint, exceptPublicID string) (int64, error) {
+	result := config.DB.Model(&models.Session{}).
+		Where("user_id = ? AND revoked_at IS NULL AND expires_at > ? AND public_id <> ?", userID, time.Now(), exceptPublicID).
+		Update("revoked_at", time.Now())
+	return result.RowsAffected, result.Error
+}
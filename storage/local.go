@@ -0,0 +1,208 @@
+// storage/local.go
+package storage
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// LocalStorage menyimpan file di filesystem lokal dan menyajikannya lewat Handler
+type LocalStorage struct {
+	Root           string
+	BaseURL        string
+	PublicPrefixes []string
+	signingKey     []byte
+}
+
+// NewLocalStorage membuat backend filesystem lokal
+func NewLocalStorage(root, baseURL string, signingKey []byte, publicPrefixes []string) *LocalStorage {
+	return &LocalStorage{
+		Root:           root,
+		BaseURL:        strings.TrimSuffix(baseURL, "/"),
+		PublicPrefixes: publicPrefixes,
+		signingKey:     signingKey,
+	}
+}
+
+func (s *LocalStorage) path(key string) (string, error) {
+	key, err := sanitizeKey(key)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(s.Root, filepath.FromSlash(key)), nil
+}
+
+// Put menulis file secara atomik melalui file sementara
+func (s *LocalStorage) Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".upload-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := io.Copy(tmp, r); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+func (s *LocalStorage) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	path, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+	file, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+	return file, err
+}
+
+func (s *LocalStorage) Delete(ctx context.Context, key string) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// List hanya menelusuri direktori dari bagian prefix sebelum "/" terakhir, bukan seluruh Root
+func (s *LocalStorage) List(ctx context.Context, prefix string) ([]string, error) {
+	prefix = strings.TrimPrefix(prefix, "/")
+	start := s.Root
+	if i := strings.LastIndex(prefix, "/"); i > 0 {
+		dir, err := s.path(prefix[:i])
+		if err != nil {
+			return nil, err
+		}
+		start = dir
+	}
+
+	var keys []string
+	err := filepath.WalkDir(start, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+		if d.IsDir() || strings.HasPrefix(d.Name(), ".") {
+			return nil
+		}
+		rel, err := filepath.Rel(s.Root, path)
+		if err != nil {
+			return err
+		}
+		if key := filepath.ToSlash(rel); strings.HasPrefix(key, prefix) {
+			keys = append(keys, key)
+		}
+		return nil
+	})
+	return keys, err
+}
+
+func (s *LocalStorage) URL(ctx context.Context, key string) (string, error) {
+	key, err := sanitizeKey(key)
+	if err != nil {
+		return "", err
+	}
+	return s.BaseURL + "/" + key, nil
+}
+
+// SignedURL membuat URL dengan parameter expires dan signature (HMAC-SHA256)
+func (s *LocalStorage) SignedURL(ctx context.Context, key string, ttl time.Duration) (string, error) {
+	key, err := sanitizeKey(key)
+	if err != nil {
+		return "", err
+	}
+	expires := strconv.FormatInt(time.Now().Add(ttl).Unix(), 10)
+	query := url.Values{"expires": {expires}, "signature": {s.sign(key, expires)}}
+	return s.BaseURL + "/" + key + "?" + query.Encode(), nil
+}
+
+func (s *LocalStorage) sign(key, expires string) string {
+	mac := hmac.New(sha256.New, s.signingKey)
+	fmt.Fprintf(mac, "%s\n%s", key, expires)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// isPublic menandai key yang boleh diakses tanpa URL bertanda tangan
+func (s *LocalStorage) isPublic(key string) bool {
+	for _, prefix := range s.PublicPrefixes {
+		if strings.HasPrefix(key, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// Handler menyajikan file; objek privat hanya bisa diakses dengan URL bertanda tangan yang belum kedaluwarsa.
+// Didaftarkan pada route dengan parameter wildcard "*key".
+func (s *LocalStorage) Handler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key, err := sanitizeKey(c.Param("key"))
+		if err != nil {
+			c.AbortWithStatus(http.StatusNotFound)
+			return
+		}
+
+		if !s.isPublic(key) {
+			expires := c.Query("expires")
+			unix, err := strconv.ParseInt(expires, 10, 64)
+			if err != nil || time.Now().Unix() > unix ||
+				!hmac.Equal([]byte(c.Query("signature")), []byte(s.sign(key, expires))) {
+				c.AbortWithStatus(http.StatusForbidden)
+				return
+			}
+		}
+
+		path, _ := s.path(key)
+		if info, err := os.Stat(path); err != nil || info.IsDir() {
+			c.AbortWithStatus(http.StatusNotFound)
+			return
+		}
+		c.File(path)
+	}
+}
+
+// RegisterRoutes mendaftarkan route untuk menyajikan upload jika backend yang dipakai adalah storage lokal.
+// Backend S3 menyajikan file langsung dari bucket sehingga tidak membutuhkan route.
+func RegisterRoutes(router *gin.Engine) {
+	if local, ok := Default.(*LocalStorage); ok {
+		router.GET(local.BaseURL+"/*key", local.Handler())
+		router.HEAD(local.BaseURL+"/*key", local.Handler())
+	}
+}
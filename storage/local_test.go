@@ -0,0 +1,50 @@
+// storage/local_test.go
+package storage
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestLocalList(t *testing.T) {
+	s := NewLocalStorage(t.TempDir(), "http://localhost:8080/uploads", []byte("key"), nil)
+	ctx := context.Background()
+	for _, key := range []string{
+		"profile_pictures/user_1_a.jpg",
+		"profile_pictures/user_1_b.jpg",
+		"profile_pictures/user_2_a.jpg",
+		"exports/user_1_1700000000.json",
+		"exports/old/user_1_1600000000.json",
+		"readme.txt",
+	} {
+		if err := s.Put(ctx, key, strings.NewReader("x"), 1, "application/octet-stream"); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		prefix  string
+		want    []string
+		wantErr bool
+	}{
+		{prefix: "profile_pictures/user_1_", want: []string{"profile_pictures/user_1_a.jpg", "profile_pictures/user_1_b.jpg"}},
+		{prefix: "/profile_pictures/user_2_", want: []string{"profile_pictures/user_2_a.jpg"}},
+		{prefix: "exports/", want: []string{"exports/old/user_1_1600000000.json", "exports/user_1_1700000000.json"}},
+		{prefix: "documents/", want: nil},
+		{prefix: "../profile_pictures/", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.prefix, func(t *testing.T) {
+			keys, err := s.List(ctx, tt.prefix)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("List(%q) error = %v, wantErr %v", tt.prefix, err, tt.wantErr)
+			}
+			slices.Sort(keys)
+			if !slices.Equal(keys, tt.want) {
+				t.Fatalf("List(%q) = %v, want %v", tt.prefix, keys, tt.want)
+			}
+		})
+	}
+}
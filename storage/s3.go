@@ -0,0 +1,135 @@
+// storage/s3.go
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+)
+
+// defaultPresignTTL dipakai untuk URL objek publik jika bucket tidak punya URL publik
+const defaultPresignTTL = time.Hour
+
+// S3Options adalah konfigurasi backend S3-compatible (AWS S3, MinIO, dll.)
+type S3Options struct {
+	Endpoint      string
+	Region        string
+	Bucket        string
+	AccessKey     string
+	SecretKey     string
+	UseSSL        bool
+	PublicBaseURL string
+}
+
+// S3Storage menyimpan file di bucket S3-compatible
+type S3Storage struct {
+	client        *minio.Client
+	bucket        string
+	publicBaseURL string
+}
+
+// NewS3Storage membuat backend S3 dan memastikan bucket dapat diakses
+func NewS3Storage(opts S3Options) (*S3Storage, error) {
+	if opts.Endpoint == "" || opts.Bucket == "" {
+		return nil, errors.New("STORAGE_S3_ENDPOINT and STORAGE_S3_BUCKET are required for the s3 storage driver")
+	}
+
+	client, err := minio.New(opts.Endpoint, &minio.Options{
+		Creds:  credentials.NewStaticV4(opts.AccessKey, opts.SecretKey, ""),
+		Secure: opts.UseSSL,
+		Region: opts.Region,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	exists, err := client.BucketExists(ctx, opts.Bucket)
+	if err != nil {
+		return nil, fmt.Errorf("checking bucket %q: %w", opts.Bucket, err)
+	}
+	if !exists {
+		return nil, fmt.Errorf("bucket %q does not exist", opts.Bucket)
+	}
+
+	return &S3Storage{
+		client:        client,
+		bucket:        opts.Bucket,
+		publicBaseURL: strings.TrimSuffix(opts.PublicBaseURL, "/"),
+	}, nil
+}
+
+func (s *S3Storage) Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error {
+	key, err := sanitizeKey(key)
+	if err != nil {
+		return err
+	}
+	_, err = s.client.PutObject(ctx, s.bucket, key, r, size, minio.PutObjectOptions{ContentType: contentType})
+	return err
+}
+
+func (s *S3Storage) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	key, err := sanitizeKey(key)
+	if err != nil {
+		return nil, err
+	}
+
+	// GetObject baru menghubungi server saat dibaca, jadi Stat dipakai untuk mendeteksi objek yang tidak ada
+	if _, err := s.client.StatObject(ctx, s.bucket, key, minio.StatObjectOptions{}); err != nil {
+		if minio.ToErrorResponse(err).Code == "NoSuchKey" {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return s.client.GetObject(ctx, s.bucket, key, minio.GetObjectOptions{})
+}
+
+func (s *S3Storage) Delete(ctx context.Context, key string) error {
+	key, err := sanitizeKey(key)
+	if err != nil {
+		return err
+	}
+	return s.client.RemoveObject(ctx, s.bucket, key, minio.RemoveObjectOptions{})
+}
+
+func (s *S3Storage) List(ctx context.Context, prefix string) ([]string, error) {
+	var keys []string
+	for object := range s.client.ListObjects(ctx, s.bucket, minio.ListObjectsOptions{Prefix: prefix, Recursive: true}) {
+		if object.Err != nil {
+			return nil, object.Err
+		}
+		keys = append(keys, object.Key)
+	}
+	return keys, nil
+}
+
+// URL memakai STORAGE_S3_PUBLIC_URL jika ada, selain itu URL presigned
+func (s *S3Storage) URL(ctx context.Context, key string) (string, error) {
+	if s.publicBaseURL == "" {
+		return s.SignedURL(ctx, key, defaultPresignTTL)
+	}
+	key, err := sanitizeKey(key)
+	if err != nil {
+		return "", err
+	}
+	return s.publicBaseURL + "/" + key, nil
+}
+
+func (s *S3Storage) SignedURL(ctx context.Context, key string, ttl time.Duration) (string, error) {
+	key, err := sanitizeKey(key)
+	if err != nil {
+		return "", err
+	}
+	u, err := s.client.PresignedGetObject(ctx, s.bucket, key, ttl, nil)
+	if err != nil {
+		return "", err
+	}
+	return u.String(), nil
+}
@@ -0,0 +1,220 @@
+// storage/s3_test.go
+package storage
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/johannesboyne/gofakes3"
+	"github.com/johannesboyne/gofakes3/backend/s3mem"
+)
+
+const testBucket = "uploads"
+
+// newTestS3 menjalankan server S3 tiruan di memori sebagai pengganti MinIO
+func newTestS3(t *testing.T, publicBaseURL string) *S3Storage {
+	t.Helper()
+	backend := s3mem.New()
+	if err := backend.CreateBucket(testBucket); err != nil {
+		t.Fatal(err)
+	}
+	server := httptest.NewServer(gofakes3.New(backend).Server())
+	t.Cleanup(server.Close)
+
+	s, err := NewS3Storage(S3Options{
+		Endpoint:      strings.TrimPrefix(server.URL, "http://"),
+		Region:        "us-east-1",
+		Bucket:        testBucket,
+		AccessKey:     "test",
+		SecretKey:     "test-secret",
+		PublicBaseURL: publicBaseURL,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func TestNewS3StorageMissingBucket(t *testing.T) {
+	server := httptest.NewServer(gofakes3.New(s3mem.New()).Server())
+	defer server.Close()
+
+	_, err := NewS3Storage(S3Options{
+		Endpoint:  strings.TrimPrefix(server.URL, "http://"),
+		Region:    "us-east-1",
+		Bucket:    "missing",
+		AccessKey: "test",
+		SecretKey: "test-secret",
+	})
+	if err == nil {
+		t.Fatal("expected an error for a bucket that does not exist")
+	}
+}
+
+func TestS3PutGet(t *testing.T) {
+	s := newTestS3(t, "")
+	ctx := context.Background()
+
+	tests := []struct {
+		name    string
+		putKey  string
+		getKey  string
+		body    string
+		wantErr error
+		invalid bool
+	}{
+		{name: "round trip", putKey: "profile_pictures/user_1.jpg", getKey: "profile_pictures/user_1.jpg", body: "jpeg bytes"},
+		{name: "leading slash", putKey: "/documents/a.pdf", getKey: "documents/a.pdf", body: "pdf bytes"},
+		{name: "overwrite", putKey: "profile_pictures/user_1.jpg", getKey: "profile_pictures/user_1.jpg", body: "new jpeg"},
+		{name: "missing object", getKey: "profile_pictures/none.jpg", wantErr: ErrNotFound},
+		{name: "path traversal", putKey: "../secret", invalid: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.putKey != "" {
+				err := s.Put(ctx, tt.putKey, strings.NewReader(tt.body), int64(len(tt.body)), "application/octet-stream")
+				if tt.invalid {
+					if err == nil {
+						t.Fatal("expected Put to reject the key")
+					}
+					return
+				}
+				if err != nil {
+					t.Fatalf("Put: %v", err)
+				}
+			}
+
+			r, err := s.Get(ctx, tt.getKey)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("Get error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Get: %v", err)
+			}
+			defer r.Close()
+			got, err := io.ReadAll(r)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tt.body {
+				t.Fatalf("Get = %q, want %q", got, tt.body)
+			}
+		})
+	}
+}
+
+func TestS3ListAndDelete(t *testing.T) {
+	s := newTestS3(t, "")
+	ctx := context.Background()
+	for _, key := range []string{"profile_pictures/user_1_a.jpg", "profile_pictures/user_1_b.jpg", "profile_pictures/user_2_a.jpg"} {
+		if err := s.Put(ctx, key, strings.NewReader("x"), 1, "image/jpeg"); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	keys, err := s.List(ctx, "profile_pictures/user_1_")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(keys) != 2 {
+		t.Fatalf("List = %v, want the two objects of user 1", keys)
+	}
+
+	if err := s.Delete(ctx, keys[0]); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Get(ctx, keys[0]); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("Get after Delete error = %v, want ErrNotFound", err)
+	}
+}
+
+func TestS3SignedURL(t *testing.T) {
+	ctx := context.Background()
+	body := []byte("private document")
+
+	tests := []struct {
+		name          string
+		publicBaseURL string
+		ttl           time.Duration
+		wantURL       string
+		wantExpires   string
+	}{
+		{name: "presigned", ttl: 15 * time.Minute, wantExpires: "900"},
+		{name: "presigned default ttl for URL", wantExpires: "3600"},
+		{name: "public base URL", publicBaseURL: "https://cdn.example.com/", wantURL: "https://cdn.example.com/documents/a.pdf"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestS3(t, tt.publicBaseURL)
+			if err := s.Put(ctx, "documents/a.pdf", bytes.NewReader(body), int64(len(body)), "application/pdf"); err != nil {
+				t.Fatal(err)
+			}
+
+			var link string
+			var err error
+			if tt.ttl > 0 {
+				link, err = s.SignedURL(ctx, "documents/a.pdf", tt.ttl)
+			} else {
+				link, err = s.URL(ctx, "documents/a.pdf")
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.wantURL != "" {
+				if link != tt.wantURL {
+					t.Fatalf("URL = %q, want %q", link, tt.wantURL)
+				}
+				return
+			}
+
+			u, err := url.Parse(link)
+			if err != nil {
+				t.Fatal(err)
+			}
+			query := u.Query()
+			if query.Get("X-Amz-Signature") == "" {
+				t.Fatalf("URL %q is not signed", link)
+			}
+			if got := query.Get("X-Amz-Expires"); got != tt.wantExpires {
+				t.Fatalf("X-Amz-Expires = %s, want %s", got, tt.wantExpires)
+			}
+
+			response, err := http.Get(link)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer response.Body.Close()
+			got, _ := io.ReadAll(response.Body)
+			if response.StatusCode != http.StatusOK || !bytes.Equal(got, body) {
+				t.Fatalf("GET signed URL = %d %q, want 200 %q", response.StatusCode, got, body)
+			}
+		})
+	}
+}
+
+func TestS3InvalidKeys(t *testing.T) {
+	s := newTestS3(t, "")
+	ctx := context.Background()
+
+	for _, key := range []string{"", "/", "a/../b", `a\b`} {
+		t.Run(key, func(t *testing.T) {
+			if _, err := s.SignedURL(ctx, key, time.Minute); err == nil {
+				t.Fatalf("SignedURL(%q) accepted an invalid key", key)
+			}
+			if _, err := s.Get(ctx, key); err == nil {
+				t.Fatalf("Get(%q) accepted an invalid key", key)
+			}
+		})
+	}
+}
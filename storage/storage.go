@@ -0,0 +1,146 @@
+// storage/storage.go
+package storage
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/mfuadfakhruzzaki/backend-api/config"
+)
+
+// ErrNotFound dikembalikan jika objek tidak ada di storage
+var ErrNotFound = errors.New("object not found")
+
+// Storage adalah backend penyimpanan file upload. Key berupa path relatif
+// seperti "profile_pictures/user_1_abcd_512.jpg".
+type Storage interface {
+	// Put menyimpan objek dengan key tertentu, menimpa objek lama jika ada
+	Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error
+	// Get membuka objek untuk dibaca; pemanggil wajib menutupnya
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	// Delete menghapus objek; menghapus objek yang tidak ada bukan error
+	Delete(ctx context.Context, key string) error
+	// List mengembalikan semua key yang diawali prefix
+	List(ctx context.Context, prefix string) ([]string, error)
+	// URL mengembalikan URL untuk objek publik
+	URL(ctx context.Context, key string) (string, error)
+	// SignedURL mengembalikan URL sementara untuk objek privat
+	SignedURL(ctx context.Context, key string, ttl time.Duration) (string, error)
+}
+
+// Default adalah backend yang dipakai aplikasi, diisi oleh Init
+var Default Storage
+
+// Init memilih backend berdasarkan cfg.Driver (STORAGE_DRIVER, "local" atau "s3")
+func Init(cfg config.StorageConfig) error {
+	backend, err := New(cfg)
+	if err != nil {
+		return err
+	}
+	Default = backend
+	return nil
+}
+
+// New membuat backend dari konfigurasi
+func New(cfg config.StorageConfig) (Storage, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	switch cfg.Driver {
+	case "s3":
+		return NewS3Storage(S3Options{
+			Endpoint:      cfg.S3.Endpoint,
+			Region:        cfg.S3.Region,
+			Bucket:        cfg.S3.Bucket,
+			AccessKey:     cfg.S3.AccessKey,
+			SecretKey:     cfg.S3.SecretKey,
+			UseSSL:        cfg.S3.UseSSL,
+			PublicBaseURL: cfg.S3.PublicURL,
+		})
+	default:
+		prefixes := cfg.PublicPrefixes
+		if len(prefixes) == 0 {
+			prefixes = []string{ProfilePicturePrefix}
+		}
+		return NewLocalStorage(cfg.LocalRoot, "/uploads", signingKey(cfg.SigningKey), prefixes), nil
+	}
+}
+
+// signingKey mengembalikan kunci HMAC untuk URL bertanda tangan milik storage lokal
+func signingKey(key string) []byte {
+	if key != "" {
+		return []byte(key)
+	}
+
+	// Tanpa kunci tetap, URL bertanda tangan hanya berlaku selama proses berjalan
+	log.Println("STORAGE_SIGNING_KEY is not set, using a random key for signed upload URLs")
+	random := make([]byte, 32)
+	if _, err := rand.Read(random); err != nil {
+		panic(err)
+	}
+	return random
+}
+
+// ProfilePicturePrefix adalah prefix key untuk foto profil
+const ProfilePicturePrefix = "profile_pictures/"
+
+// legacyURLPrefix adalah awalan path lama sebelum file disimpan sebagai key
+const legacyURLPrefix = "/uploads/"
+
+// KeyFromLegacyPath mengubah path lama "/uploads/..." menjadi key storage
+func KeyFromLegacyPath(path string) string {
+	return strings.TrimPrefix(path, legacyURLPrefix)
+}
+
+// IsExternalURL menandai nilai yang berupa URL eksternal, misalnya avatar dari OAuth
+func IsExternalURL(value string) bool {
+	return strings.HasPrefix(value, "http://") || strings.HasPrefix(value, "https://")
+}
+
+// ResolveURL mengubah key (atau path lama) menjadi URL publik.
+// URL eksternal dikembalikan apa adanya.
+func ResolveURL(ctx context.Context, value string) string {
+	if value == "" || IsExternalURL(value) || Default == nil {
+		return value
+	}
+	url, err := Default.URL(ctx, KeyFromLegacyPath(value))
+	if err != nil {
+		log.Printf("Failed to resolve storage URL for %s: %v", value, err)
+		return value
+	}
+	return url
+}
+
+// ProfilePictureKeys mengembalikan semua key foto profil milik pengguna,
+// termasuk format lama "user_<id>.<ext>"
+func ProfilePictureKeys(ctx context.Context, s Storage, userID uint) ([]string, error) {
+	prefix := fmt.Sprintf("%suser_%d", ProfilePicturePrefix, userID)
+	keys, err := s.List(ctx, prefix)
+	if err != nil {
+		return nil, err
+	}
+
+	var owned []string
+	for _, key := range keys {
+		// Hindari key milik user lain dengan ID yang diawali angka yang sama (user_1 vs user_12)
+		if rest := strings.TrimPrefix(key, prefix); strings.HasPrefix(rest, "_") || strings.HasPrefix(rest, ".") {
+			owned = append(owned, key)
+		}
+	}
+	return owned, nil
+}
+
+// sanitizeKey menolak key yang bisa keluar dari root storage
+func sanitizeKey(key string) (string, error) {
+	key = strings.TrimPrefix(key, "/")
+	if key == "" || strings.Contains(key, "..") || strings.Contains(key, "\\") {
+		return "", fmt.Errorf("invalid storage key %q", key)
+	}
+	return key, nil
+}
@@ -1,14 +1,15 @@
 package utils
 
 import (
+	"errors"
 	"fmt"
 	"log"
 	"math/rand"
-	"os"
-	"strconv"
 	"time"
 
 	"gopkg.in/gomail.v2"
+
+	"github.com/mfuadfakhruzzaki/backend-api/config"
 )
 
 // letters berisi karakter yang akan digunakan untuk menghasilkan kode verifikasi
@@ -26,39 +27,99 @@ func GenerateVerificationCode() string {
 
 // SendVerificationEmail mengirimkan email verifikasi dengan kode ke pengguna
 func SendVerificationEmail(recipientEmail string, verificationCode string) error {
-	// Mendapatkan konfigurasi SMTP dari environment variables
-	smtpHost := os.Getenv("SMTP_HOST")
-	smtpPort := os.Getenv("SMTP_PORT")
-	senderEmail := os.Getenv("SMTP_SENDER")
-	senderPassword := os.Getenv("SMTP_PASSWORD")
-
-	// Verifikasi apakah variabel environment telah diatur dengan benar
-	if smtpHost == "" || smtpPort == "" || senderEmail == "" || senderPassword == "" {
-		log.Fatal("SMTP configuration is missing in environment variables")
-		return fmt.Errorf("SMTP configuration is missing in environment variables")
+	body := fmt.Sprintf("Welcome to Data Quota Tracker!\n\nYour verification code is: %s\n\nPlease enter this code to verify your email and start using the app.", verificationCode)
+	if err := sendEmail(recipientEmail, "Email Verification for Data Quota Tracker", body); err != nil {
+		return err
+	}
+
+	fmt.Printf("Verification email sent to %s with code %s\n", recipientEmail, verificationCode)
+	return nil
+}
+
+// SendEmailChangeCode mengirimkan kode verifikasi ke alamat email baru
+func SendEmailChangeCode(newEmail string, code string) error {
+	body := fmt.Sprintf("We received a request to change the email address of your Data Quota Tracker account to this address.\n\nYour confirmation code is: %s\n\nIf you did not request this change, you can ignore this email.", code)
+	return sendEmail(newEmail, "Confirm your new email address", body)
+}
+
+// SendEmailChangedNotice memberi tahu alamat email lama bahwa email akun telah diganti
+func SendEmailChangedNotice(oldEmail string, newEmail string) error {
+	body := fmt.Sprintf("The email address of your Data Quota Tracker account was changed to %s.\n\nIf you did not make this change, please contact support immediately.", newEmail)
+	return sendEmail(oldEmail, "Your email address was changed", body)
+}
+
+// SendAccountDeletionScheduled memberi tahu pengguna bahwa akunnya akan dihapus
+func SendAccountDeletionScheduled(recipientEmail string, scheduledAt time.Time) error {
+	body := fmt.Sprintf("Your Data Quota Tracker account is scheduled for deletion on %s.\n\nLog in and cancel the deletion before that date if you want to keep your account.", scheduledAt.Format("2 January 2006 15:04 MST"))
+	return sendEmail(recipientEmail, "Your account is scheduled for deletion", body)
+}
+
+// SendTwoFactorResetNotice memberi tahu pengguna bahwa 2FA akunnya direset oleh admin
+func SendTwoFactorResetNotice(recipientEmail string) error {
+	body := "Two-factor authentication on your Data Quota Tracker account was turned off by our support team at your request.\n\nYou can log in with your password and set up two-factor authentication again from your profile.\n\nIf you did not ask support for this, please contact us immediately."
+	return sendEmail(recipientEmail, "Two-factor authentication was reset", body)
+}
+
+// SendAccountLockedNotice memberi tahu pengguna bahwa login dengan password ke akunnya
+// dikunci sementara karena terlalu banyak percobaan yang gagal
+func SendAccountLockedNotice(recipientEmail string, lockedUntil time.Time) error {
+	body := fmt.Sprintf("We noticed several failed attempts to log in to your Data Quota Tracker account, so password login is locked until %s.\n\nIf these attempts were not you, someone may be trying to guess your password. You can still log in with a login link or a passkey, and we recommend changing your password and turning on two-factor authentication.\n\nIf you need access sooner, please contact our support team.", lockedUntil.Format("2 January 2006 15:04 MST"))
+	return sendEmail(recipientEmail, "Failed login attempts on your account", body)
+}
+
+// SendMagicLink mengirimkan tautan dan kode login tanpa password. link boleh kosong
+// jika frontend untuk tautan tidak dikonfigurasi; pengguna tetap bisa memakai kodenya.
+func SendMagicLink(recipientEmail string, link string, code string, ttl time.Duration) error {
+	body := "We received a request to log in to your Data Quota Tracker account.\n\n"
+	if link != "" {
+		body += fmt.Sprintf("Click this link to log in:\n%s\n\nOr enter this code: %s\n\n", link, code)
+	} else {
+		body += fmt.Sprintf("Your login code is: %s\n\n", code)
+	}
+	body += fmt.Sprintf("The link and code expire in %d minutes and can only be used once. If you did not try to log in, you can ignore this email.", int(ttl.Minutes()))
+	return sendEmail(recipientEmail, "Your login link for Data Quota Tracker", body)
+}
+
+// SendPasswordResetLink mengirimkan tautan reset password. Jika frontend untuk tautan tidak
+// dikonfigurasi (link kosong), token dikirim apa adanya untuk dimasukkan secara manual.
+func SendPasswordResetLink(recipientEmail string, link string, token string, ttl time.Duration) error {
+	body := "We received a request to reset the password of your Data Quota Tracker account.\n\n"
+	if link != "" {
+		body += fmt.Sprintf("Click this link to choose a new password:\n%s\n\n", link)
+	} else {
+		body += fmt.Sprintf("Your password reset token is:\n%s\n\n", token)
+	}
+	body += fmt.Sprintf("The link expires in %d minutes and can only be used once. If you did not ask to reset your password, you can ignore this email; your password will not change.", int(ttl.Minutes()))
+	return sendEmail(recipientEmail, "Reset your Data Quota Tracker password", body)
+}
+
+// SendPasswordChangedNotice memberi tahu pengguna bahwa password akunnya baru saja diganti
+func SendPasswordChangedNotice(recipientEmail string) error {
+	body := "The password of your Data Quota Tracker account was just reset.\n\nIf you did not do this, please reset your password again right away and contact our support team."
+	return sendEmail(recipientEmail, "Your password was changed", body)
+}
+
+// sendEmail mengirimkan email teks biasa menggunakan konfigurasi SMTP. Konfigurasi sudah
+// divalidasi saat aplikasi dijalankan, sehingga kesalahan di sini hanya mengembalikan error.
+func sendEmail(recipientEmail string, subject string, body string) error {
+	smtp := config.App.SMTP
+	if smtp.Host == "" || smtp.Sender == "" {
+		return errors.New("SMTP is not configured")
 	}
 
 	// Membuat pesan email
 	m := gomail.NewMessage()
-	m.SetHeader("From", senderEmail)
+	m.SetHeader("From", smtp.Sender)
 	m.SetHeader("To", recipientEmail)
-	m.SetHeader("Subject", "Email Verification for Data Quota Tracker")
-	m.SetBody("text/plain", fmt.Sprintf("Welcome to Data Quota Tracker!\n\nYour verification code is: %s\n\nPlease enter this code to verify your email and start using the app.", verificationCode))
-
-	// Membuat dialer untuk mengirim email
-	port, err := strconv.Atoi(smtpPort)
-	if err != nil {
-		log.Fatalf("Invalid SMTP port: %v", err)
-		return fmt.Errorf("invalid SMTP port: %v", err)
-	}
-	d := gomail.NewDialer(smtpHost, port, senderEmail, senderPassword)
+	m.SetHeader("Subject", subject)
+	m.SetBody("text/plain", body)
 
 	// Kirim email
+	d := gomail.NewDialer(smtp.Host, smtp.Port, smtp.Sender, smtp.Password)
 	if err := d.DialAndSend(m); err != nil {
-		log.Printf("Failed to send verification email to %s: %v", recipientEmail, err)
+		log.Printf("Failed to send email to %s: %v", recipientEmail, err)
 		return err
 	}
 
-	fmt.Printf("Verification email sent to %s with code %s\n", recipientEmail, verificationCode)
 	return nil
 }
@@ -1,14 +1,166 @@
 // utils/hash.go
 package utils
 
-import "golang.org/x/crypto/bcrypt"
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
 
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/mfuadfakhruzzaki/backend-api/config"
+)
+
+// MaxPasswordLength membatasi panjang password (dalam byte) agar hashing tidak bisa
+// dipakai untuk membebani server
+const MaxPasswordLength = 1024
+
+// ErrPasswordTooLong dikembalikan HashPassword untuk password yang lebih panjang dari MaxPasswordLength
+var ErrPasswordTooLong = fmt.Errorf("password must be at most %d bytes", MaxPasswordLength)
+
+// Argon2Params adalah parameter Argon2id. Parameter disimpan di dalam setiap hash, sehingga
+// mengubahnya tidak merusak hash lama; hash lama diperbarui saat pengguna login berikutnya.
+type Argon2Params struct {
+	Memory      uint32 // dalam KiB
+	Iterations  uint32
+	Parallelism uint8
+	SaltLength  uint32
+	KeyLength   uint32
+}
+
+// defaultArgon2Params mengikuti rekomendasi minimum OWASP (19 MiB, 2 iterasi, 1 thread),
+// cukup ringan untuk pod kecil
+var defaultArgon2Params = Argon2Params{Memory: 19 * 1024, Iterations: 2, Parallelism: 1, SaltLength: 16, KeyLength: 32}
+
+var argon2Params = defaultArgon2Params
+
+// InitPasswordHashing memakai parameter Argon2id dari konfigurasi (PASSWORD_ARGON2_MEMORY_KIB,
+// PASSWORD_ARGON2_ITERATIONS dan PASSWORD_ARGON2_PARALLELISM)
+func InitPasswordHashing(cfg config.PasswordConfig) error {
+	params := defaultArgon2Params
+	params.Memory = cfg.Argon2MemoryKiB
+	params.Iterations = cfg.Argon2Iterations
+	params.Parallelism = cfg.Argon2Parallelism
+	switch {
+	case params.Memory == 0:
+		return errors.New("PASSWORD_ARGON2_MEMORY_KIB must be a positive number")
+	case params.Iterations == 0:
+		return errors.New("PASSWORD_ARGON2_ITERATIONS must be a positive number")
+	case params.Parallelism == 0:
+		return errors.New("PASSWORD_ARGON2_PARALLELISM must be between 1 and 255")
+	case params.Memory < 8*uint32(params.Parallelism):
+		return errors.New("PASSWORD_ARGON2_MEMORY_KIB must be at least 8 KiB per thread")
+	}
+	argon2Params = params
+	return nil
+}
+
+// HashPassword membuat hash Argon2id dalam format PHC:
+//
+//	$argon2id$v=19$m=<memori KiB>,t=<iterasi>,p=<thread>$<salt>$<hash>
+//
+// Awalan "$argon2id$" menandai versi format; hash bcrypt lama ("$2a$", "$2b$", "$2y$")
+// tetap bisa diverifikasi.
 func HashPassword(password string) (string, error) {
-    bytes, err := bcrypt.GenerateFromPassword([]byte(password), 14)
-    return string(bytes), err
+	if len(password) > MaxPasswordLength {
+		return "", ErrPasswordTooLong
+	}
+	params := argon2Params
+	salt := make([]byte, params.SaltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+	key := argon2.IDKey([]byte(password), salt, params.Iterations, params.Memory, params.Parallelism, params.KeyLength)
+
+	return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s",
+		argon2.Version, params.Memory, params.Iterations, params.Parallelism,
+		base64.RawStdEncoding.EncodeToString(salt), base64.RawStdEncoding.EncodeToString(key)), nil
 }
 
+// CheckPasswordHash memeriksa password terhadap hash Argon2id atau bcrypt lama
 func CheckPasswordHash(password, hash string) bool {
-    err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(password))
-    return err == nil
+	if len(password) > MaxPasswordLength {
+		return false
+	}
+	if isBcryptHash(hash) {
+		return bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)) == nil
+	}
+
+	params, salt, key, err := decodeArgon2Hash(hash)
+	if err != nil {
+		return false
+	}
+	computed := argon2.IDKey([]byte(password), salt, params.Iterations, params.Memory, params.Parallelism, uint32(len(key)))
+	return subtle.ConstantTimeCompare(computed, key) == 1
+}
+
+// PasswordNeedsRehash menandai hash yang dibuat dengan bcrypt atau dengan parameter Argon2id
+// yang berbeda dari konfigurasi saat ini. Panggil setelah CheckPasswordHash berhasil, selagi
+// password aslinya masih tersedia.
+func PasswordNeedsRehash(hash string) bool {
+	if isBcryptHash(hash) {
+		return true
+	}
+	params, salt, key, err := decodeArgon2Hash(hash)
+	if err != nil {
+		return true
+	}
+	current := argon2Params
+	return params.Memory != current.Memory || params.Iterations != current.Iterations ||
+		params.Parallelism != current.Parallelism || uint32(len(salt)) != current.SaltLength ||
+		uint32(len(key)) != current.KeyLength
+}
+
+func isBcryptHash(hash string) bool {
+	return strings.HasPrefix(hash, "$2a$") || strings.HasPrefix(hash, "$2b$") || strings.HasPrefix(hash, "$2y$")
+}
+
+// decodeArgon2Hash membaca hash berformat PHC yang dibuat oleh HashPassword
+func decodeArgon2Hash(hash string) (Argon2Params, []byte, []byte, error) {
+	var params Argon2Params
+	parts := strings.Split(hash, "$")
+	if len(parts) != 6 || parts[0] != "" || parts[1] != "argon2id" {
+		return params, nil, nil, errors.New("not an argon2id hash")
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return params, nil, nil, errors.New("unsupported argon2 version")
+	}
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &params.Memory, &params.Iterations, &params.Parallelism); err != nil {
+		return params, nil, nil, err
+	}
+	if params.Memory == 0 || params.Iterations == 0 || params.Parallelism == 0 {
+		return params, nil, nil, errors.New("invalid argon2 parameters")
+	}
+
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return params, nil, nil, err
+	}
+	key, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil || len(key) == 0 {
+		return params, nil, nil, errors.New("invalid argon2 hash")
+	}
+	params.SaltLength, params.KeyLength = uint32(len(salt)), uint32(len(key))
+	return params, salt, key, nil
+}
+
+var (
+	dummyHashOnce sync.Once
+	dummyHash     string
+)
+
+// DummyPasswordCheck melakukan perbandingan hash yang sama mahalnya dengan CheckPasswordHash,
+// dipakai saat pengguna tidak ditemukan agar waktu respons tidak membocorkan email yang terdaftar
+func DummyPasswordCheck(password string) {
+	dummyHashOnce.Do(func() {
+		dummyHash, _ = HashPassword("dummy-password-for-timing")
+	})
+	CheckPasswordHash(password, dummyHash)
 }
@@ -0,0 +1,100 @@
+package utils
+
+import (
+	"errors"
+	"strings"
+	"unicode"
+)
+
+// Jenis identifier yang bisa dipakai untuk login
+const (
+	IdentifierEmail    = "email"
+	IdentifierUsername = "username"
+	IdentifierPhone    = "phone"
+)
+
+// defaultCountryCode dipakai untuk nomor lokal yang diawali 0 (mis. 0812... menjadi +62812...)
+const defaultCountryCode = "62"
+
+var (
+	ErrInvalidPhoneNumber = errors.New("invalid phone number")
+	ErrInvalidUsername    = errors.New("username must be 3-32 characters, contain a letter, and may only contain letters, digits, dots, dashes and underscores")
+)
+
+// NormalizeEmail menghapus spasi dan mengubah email ke huruf kecil. Email dibandingkan
+// tanpa memperhatikan huruf besar/kecil di seluruh aplikasi.
+func NormalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+// NormalizeUsername menghapus spasi di awal dan akhir username. Huruf besar/kecil tetap
+// disimpan seperti yang diketik, tetapi username dibandingkan dengan LOWER().
+func NormalizeUsername(username string) string {
+	return strings.TrimSpace(username)
+}
+
+// ValidateUsername memastikan username tidak bisa tertukar dengan email atau nomor telepon saat login
+func ValidateUsername(username string) error {
+	if len(username) < 3 || len(username) > 32 {
+		return ErrInvalidUsername
+	}
+	hasLetter := false
+	for _, r := range username {
+		switch {
+		case r < unicode.MaxASCII && unicode.IsLetter(r):
+			hasLetter = true
+		case (r >= '0' && r <= '9') || r == '.' || r == '-' || r == '_':
+		default:
+			return ErrInvalidUsername
+		}
+	}
+	if !hasLetter {
+		return ErrInvalidUsername
+	}
+	return nil
+}
+
+// NormalizePhoneNumber mengubah nomor telepon ke format E.164 (+<kode negara><nomor>).
+// Spasi, tanda hubung, titik dan kurung diabaikan; nomor lokal yang diawali 0 dianggap
+// nomor Indonesia.
+func NormalizePhoneNumber(phone string) (string, error) {
+	phone = strings.TrimSpace(phone)
+	var digits strings.Builder
+	for i, r := range phone {
+		switch {
+		case r >= '0' && r <= '9':
+			digits.WriteRune(r)
+		case r == '+' && i == 0:
+		case r == ' ' || r == '-' || r == '.' || r == '(' || r == ')':
+		default:
+			return "", ErrInvalidPhoneNumber
+		}
+	}
+
+	number := digits.String()
+	switch {
+	case strings.HasPrefix(phone, "+"):
+	case strings.HasPrefix(phone, "00"):
+		number = number[2:]
+	case strings.HasPrefix(number, "0"):
+		number = defaultCountryCode + number[1:]
+	}
+	// E.164 membatasi nomor sampai 15 digit
+	if len(number) < 8 || len(number) > 15 || number[0] == '0' {
+		return "", ErrInvalidPhoneNumber
+	}
+	return "+" + number, nil
+}
+
+// ClassifyIdentifier menentukan apakah identifier login berupa email, nomor telepon atau
+// username, dan mengembalikan bentuk normalnya
+func ClassifyIdentifier(identifier string) (kind, normalized string) {
+	identifier = strings.TrimSpace(identifier)
+	if strings.Contains(identifier, "@") {
+		return IdentifierEmail, NormalizeEmail(identifier)
+	}
+	if phone, err := NormalizePhoneNumber(identifier); err == nil {
+		return IdentifierPhone, phone
+	}
+	return IdentifierUsername, NormalizeUsername(identifier)
+}
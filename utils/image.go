@@ -0,0 +1,213 @@
+// utils/image.go
+package utils
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"net/http"
+
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp" // Mendaftarkan decoder WebP
+)
+
+// ProfilePictureSizes adalah ukuran (piksel, persegi) thumbnail yang dibuat, dari yang terbesar
+var ProfilePictureSizes = []int{512, 256, 128, 64}
+
+const (
+	// MaxImageBytes adalah ukuran file maksimum yang diterima
+	MaxImageBytes = 10 << 20
+	// maxImagePixels mencegah "decompression bomb" dengan membatasi jumlah piksel
+	maxImagePixels = 40_000_000
+	jpegQuality    = 85
+)
+
+var (
+	ErrUnsupportedImage = errors.New("unsupported image type, only JPEG, PNG and WebP are allowed")
+	ErrInvalidImage     = errors.New("image is malformed and could not be decoded")
+	ErrImageTooLarge    = errors.New("image dimensions are too large")
+)
+
+// ImageVariant adalah satu ukuran hasil re-encode
+type ImageVariant struct {
+	Size int
+	Data []byte
+}
+
+// ProcessedImage adalah hasil pipeline pemrosesan foto profil
+type ProcessedImage struct {
+	Hash        string
+	Extension   string
+	ContentType string
+	Variants    []ImageVariant
+}
+
+// ProcessProfileImage memeriksa tipe gambar berdasarkan isinya, men-decode gambar, memotongnya
+// menjadi persegi, lalu meng-encode ulang ke beberapa ukuran. Karena gambar di-encode ulang,
+// metadata seperti EXIF tidak ikut tersimpan.
+func ProcessProfileImage(data []byte) (*ProcessedImage, error) {
+	contentType := http.DetectContentType(data)
+	switch contentType {
+	case "image/jpeg", "image/png", "image/webp":
+	default:
+		return nil, ErrUnsupportedImage
+	}
+
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrInvalidImage
+	}
+	if cfg.Width <= 0 || cfg.Height <= 0 || cfg.Width*cfg.Height > maxImagePixels {
+		return nil, ErrImageTooLarge
+	}
+
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrInvalidImage
+	}
+
+	// Gambar dengan transparansi tetap PNG, selain itu disimpan sebagai JPEG
+	result := &ProcessedImage{Extension: ".jpg", ContentType: "image/jpeg"}
+	if opaque, ok := src.(interface{ Opaque() bool }); contentType != "image/jpeg" && (!ok || !opaque.Opaque()) {
+		result.Extension, result.ContentType = ".png", "image/png"
+	}
+
+	sum := sha256.Sum256(data)
+	result.Hash = hex.EncodeToString(sum[:])[:16]
+
+	crop := centerSquare(src.Bounds())
+	largest := min(ProfilePictureSizes[0], crop.Dx())
+	base := image.NewNRGBA(image.Rect(0, 0, largest, largest))
+	draw.CatmullRom.Scale(base, base.Bounds(), src, crop, draw.Src, nil)
+
+	// Orientasi EXIF diterapkan sebelum metadata dibuang. Potongan persegi di tengah
+	// tidak berubah oleh rotasi, jadi cukup diterapkan pada gambar yang sudah kecil.
+	if contentType == "image/jpeg" {
+		base = applyOrientation(base, jpegOrientation(data))
+	}
+
+	for _, size := range ProfilePictureSizes {
+		size = min(size, largest)
+		img := base
+		if size != largest {
+			img = image.NewNRGBA(image.Rect(0, 0, size, size))
+			draw.CatmullRom.Scale(img, img.Bounds(), base, base.Bounds(), draw.Src, nil)
+		}
+
+		var buf bytes.Buffer
+		if result.ContentType == "image/png" {
+			err = png.Encode(&buf, img)
+		} else {
+			err = jpeg.Encode(&buf, img, &jpeg.Options{Quality: jpegQuality})
+		}
+		if err != nil {
+			return nil, err
+		}
+		result.Variants = append(result.Variants, ImageVariant{Size: size, Data: buf.Bytes()})
+	}
+
+	return result, nil
+}
+
+// centerSquare mengembalikan persegi terbesar di tengah bounds
+func centerSquare(b image.Rectangle) image.Rectangle {
+	side := min(b.Dx(), b.Dy())
+	x := b.Min.X + (b.Dx()-side)/2
+	y := b.Min.Y + (b.Dy()-side)/2
+	return image.Rect(x, y, x+side, y+side)
+}
+
+// applyOrientation memutar/membalik gambar persegi sesuai tag EXIF Orientation (1-8)
+func applyOrientation(img *image.NRGBA, orientation int) *image.NRGBA {
+	if orientation < 2 || orientation > 8 {
+		return img
+	}
+
+	n := img.Bounds().Dx() - 1
+	out := image.NewNRGBA(img.Bounds())
+	for y := 0; y <= n; y++ {
+		for x := 0; x <= n; x++ {
+			var sx, sy int
+			switch orientation {
+			case 2: // cermin horizontal
+				sx, sy = n-x, y
+			case 3: // putar 180
+				sx, sy = n-x, n-y
+			case 4: // cermin vertikal
+				sx, sy = x, n-y
+			case 5: // transpose
+				sx, sy = y, x
+			case 6: // putar 90 searah jarum jam
+				sx, sy = y, n-x
+			case 7: // transverse
+				sx, sy = n-y, n-x
+			case 8: // putar 90 berlawanan arah jarum jam
+				sx, sy = n-y, x
+			}
+			out.SetNRGBA(x, y, img.NRGBAAt(sx, sy))
+		}
+	}
+	return out
+}
+
+// jpegOrientation membaca tag Orientation dari segmen APP1 Exif, atau 1 jika tidak ada
+func jpegOrientation(data []byte) int {
+	if len(data) < 4 || data[0] != 0xFF || data[1] != 0xD8 {
+		return 1
+	}
+
+	for i := 2; i+4 <= len(data); {
+		if data[i] != 0xFF {
+			return 1
+		}
+		marker := data[i+1]
+		length := int(binary.BigEndian.Uint16(data[i+2:]))
+		if marker == 0xDA || length < 2 || i+2+length > len(data) {
+			return 1 // awal data gambar atau segmen rusak
+		}
+		segment := data[i+4 : i+2+length]
+		if marker == 0xE1 && len(segment) > 6 && string(segment[:6]) == "Exif\x00\x00" {
+			return exifOrientation(segment[6:])
+		}
+		i += 2 + length
+	}
+	return 1
+}
+
+// exifOrientation membaca tag 0x0112 dari IFD0 di header TIFF
+func exifOrientation(tiff []byte) int {
+	if len(tiff) < 8 {
+		return 1
+	}
+
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 1
+	}
+
+	offset := int(order.Uint32(tiff[4:]))
+	if offset < 8 || offset+2 > len(tiff) {
+		return 1
+	}
+	entries := int(order.Uint16(tiff[offset:]))
+	for e := 0; e < entries; e++ {
+		entry := offset + 2 + e*12
+		if entry+12 > len(tiff) {
+			return 1
+		}
+		if order.Uint16(tiff[entry:]) == 0x0112 {
+			return int(order.Uint16(tiff[entry+8:]))
+		}
+	}
+	return 1
+}
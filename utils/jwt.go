@@ -2,65 +2,157 @@
 package utils
 
 import (
+	"crypto/rand"
+	"encoding/hex"
 	"errors"
 	"fmt"
-	"os"
+	"strconv"
 	"time"
 
-	"github.com/dgrijalva/jwt-go"
+	"github.com/golang-jwt/jwt/v5"
+
+	"github.com/mfuadfakhruzzaki/backend-api/config"
 )
 
-var JWT_SECRET = os.Getenv("JWT_SECRET")
+// TokenTTL adalah masa berlaku token login, sekaligus masa berlaku sesinya
+const TokenTTL = 72 * time.Hour
+
+// tokenLeeway adalah toleransi selisih jam antar server saat memeriksa exp, nbf dan iat
+const tokenLeeway = 30 * time.Second
+
+// ImpersonationTTL adalah masa berlaku token impersonasi admin; sengaja singkat dan tidak bisa diperpanjang
+const ImpersonationTTL = 15 * time.Minute
+
+// defaultTokenIssuer dipakai untuk iss dan aud jika JWT_ISSUER/JWT_AUDIENCE tidak diisi
+const defaultTokenIssuer = "backend-api"
+
+// Claims adalah isi token akses. Subject berisi ID pengguna sehingga token tetap
+// berlaku walaupun email pengguna berubah; Email hanya sebagai informasi. SessionID
+// menunjuk sesi login sehingga token bisa dicabut sebelum kedaluwarsa.
+type Claims struct {
+	Email     string `json:"email,omitempty"`
+	SessionID string `json:"sid,omitempty"`
+	// Actor diisi pada token impersonasi: sub tetap pengguna yang dilihat, act.sub adalah
+	// admin yang sebenarnya memakai token (RFC 8693)
+	Actor *ActorClaim `json:"act,omitempty"`
+	jwt.RegisteredClaims
+}
 
-// Pastikan JWT_SECRET ter-set
-func init() {
-    if JWT_SECRET == "" {
-        fmt.Println("JWT_SECRET is not set in the environment")
-    }
+// ActorClaim menunjuk pihak yang bertindak atas nama pengguna
+type ActorClaim struct {
+	Subject string `json:"sub"`
 }
 
-// GenerateJWT membuat token JWT berdasarkan email pengguna
-func GenerateJWT(email string) (string, error) {
-    mySigningKey := []byte(JWT_SECRET)
+// UserID mengembalikan ID pengguna dari claim sub
+func (c *Claims) UserID() (uint, error) {
+	id, err := strconv.ParseUint(c.Subject, 10, 64)
+	if err != nil || id == 0 {
+		return 0, errors.New("invalid subject claim")
+	}
+	return uint(id), nil
+}
 
-    token := jwt.New(jwt.SigningMethodHS256)
-    claims := token.Claims.(jwt.MapClaims)
+// ImpersonatorID mengembalikan ID admin dari claim act.sub; false jika bukan token impersonasi
+func (c *Claims) ImpersonatorID() (uint, bool) {
+	if c.Actor == nil {
+		return 0, false
+	}
+	id, err := strconv.ParseUint(c.Actor.Subject, 10, 64)
+	if err != nil || id == 0 {
+		return 0, false
+	}
+	return uint(id), true
+}
 
-    claims["authorized"] = true
-    claims["email"] = email
-    claims["exp"] = time.Now().Add(time.Hour * 72).Unix()
+func tokenIssuer() string {
+	if issuer := config.App.JWT.Issuer; issuer != "" {
+		return issuer
+	}
+	return defaultTokenIssuer
+}
 
-    tokenString, err := token.SignedString(mySigningKey)
-    if err != nil {
-        return "", err
-    }
+func tokenAudience() string {
+	if audience := config.App.JWT.Audience; audience != "" {
+		return audience
+	}
+	return defaultTokenIssuer
+}
 
-    fmt.Println("Token generated:", tokenString) // Debugging output
-    return tokenString, nil
+// GenerateJWT membuat token akses untuk sesi login pengguna, ditandatangani dengan kunci aktif
+// dan header kid agar penerima bisa memilih kunci verifikasi dari JWKS
+func GenerateJWT(userID uint, email string, sessionID string) (string, error) {
+	return signToken(Claims{Email: email, SessionID: sessionID}, userID, TokenTTL)
 }
 
-// ValidateToken memvalidasi token JWT dan mengembalikan email pengguna jika valid
-func ValidateToken(tokenString string) (string, error) {
-    mySigningKey := []byte(JWT_SECRET)
-
-    token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-        if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-            return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
-        }
-        return mySigningKey, nil
-    })
-
-    if err != nil {
-        return "", err
-    }
-
-    if claims, ok := token.Claims.(jwt.MapClaims); ok && token.Valid {
-        email, ok := claims["email"].(string)
-        if !ok {
-            return "", errors.New("invalid token claims")
-        }
-        return email, nil
-    }
-
-    return "", errors.New("invalid token")
+// GenerateImpersonationJWT membuat token berumur ImpersonationTTL yang bertindak sebagai
+// pengguna userID, dengan admin adminID di claim act
+func GenerateImpersonationJWT(userID uint, email string, adminID uint) (string, time.Time, error) {
+	expiresAt := time.Now().Add(ImpersonationTTL)
+	claims := Claims{Email: email, Actor: &ActorClaim{Subject: strconv.FormatUint(uint64(adminID), 10)}}
+	token, err := signToken(claims, userID, ImpersonationTTL)
+	return token, expiresAt, err
+}
+
+// signToken melengkapi registered claims lalu menandatangani token dengan kunci aktif
+func signToken(claims Claims, userID uint, ttl time.Duration) (string, error) {
+	if jwtKeys == nil {
+		return "", errors.New("JWT keys are not initialized")
+	}
+	key := jwtKeys.signing
+
+	jti := make([]byte, 16)
+	if _, err := rand.Read(jti); err != nil {
+		return "", err
+	}
+
+	now := time.Now()
+	claims.RegisteredClaims = jwt.RegisteredClaims{
+		Subject:   strconv.FormatUint(uint64(userID), 10),
+		Issuer:    tokenIssuer(),
+		Audience:  jwt.ClaimStrings{tokenAudience()},
+		IssuedAt:  jwt.NewNumericDate(now),
+		NotBefore: jwt.NewNumericDate(now),
+		ExpiresAt: jwt.NewNumericDate(now.Add(ttl)),
+		ID:        hex.EncodeToString(jti),
+	}
+
+	token := jwt.NewWithClaims(key.Method, claims)
+	token.Header["kid"] = key.ID
+	return token.SignedString(key.Private)
+}
+
+// ValidateToken memvalidasi tanda tangan, iss, aud, exp, nbf dan iat lalu mengembalikan claims
+func ValidateToken(tokenString string) (*Claims, error) {
+	if jwtKeys == nil {
+		return nil, errors.New("JWT keys are not initialized")
+	}
+
+	claims := &Claims{}
+	_, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		key, ok := jwtKeys.verify[kid]
+		if !ok {
+			return nil, fmt.Errorf("unknown signing key %q", kid)
+		}
+		// Algoritma harus sesuai dengan jenis kunci, bukan dipilih oleh token
+		if token.Method.Alg() != key.Method.Alg() {
+			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+		}
+		return key.Public, nil
+	},
+		jwt.WithValidMethods([]string{jwt.SigningMethodRS256.Alg(), jwt.SigningMethodEdDSA.Alg()}),
+		jwt.WithIssuer(tokenIssuer()),
+		jwt.WithAudience(tokenAudience()),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+		jwt.WithLeeway(tokenLeeway),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := claims.UserID(); err != nil {
+		return nil, err
+	}
+	return claims, nil
 }
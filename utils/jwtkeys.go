@@ -0,0 +1,213 @@
+// utils/jwtkeys.go
+package utils
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"github.com/mfuadfakhruzzaki/backend-api/config"
+)
+
+// minRSAKeyBits adalah ukuran minimum kunci RSA yang diterima
+const minRSAKeyBits = 2048
+
+// jwtKey adalah satu kunci JWT. Kunci yang hanya berisi public key dipakai untuk
+// memverifikasi token lama selama masa rotasi.
+type jwtKey struct {
+	ID      string
+	Method  jwt.SigningMethod
+	Private crypto.Signer
+	Public  crypto.PublicKey
+}
+
+// jwtKeySet berisi kunci penandatangan aktif dan semua kunci verifikasi berdasarkan kid
+type jwtKeySet struct {
+	signing *jwtKey
+	verify  map[string]*jwtKey
+}
+
+var jwtKeys *jwtKeySet
+
+// InitJWTKeys memuat kunci JWT dari cfg.KeysDir (JWT_KEYS_DIR). Setiap file *.pem di folder itu adalah
+// satu kunci dengan kid = nama file tanpa ekstensi:
+//
+//   - private key (RSA minimal 2048 bit atau Ed25519) bisa dipakai untuk menandatangani
+//     dan sekaligus memverifikasi
+//   - public key hanya dipakai untuk memverifikasi token yang ditandatangani kunci lama
+//
+// cfg.SigningKID (JWT_SIGNING_KID) memilih kunci penandatangan; boleh kosong jika hanya ada satu private key.
+// Rotasi: tambahkan kunci baru, ganti JWT_SIGNING_KID, dan simpan public key lama sampai
+// semua token lama kedaluwarsa.
+func InitJWTKeys(cfg config.JWTConfig) error {
+	if err := cfg.Validate(); err != nil {
+		return err
+	}
+	dir := cfg.KeysDir
+
+	keys, err := loadJWTKeys(dir)
+	if err != nil {
+		return err
+	}
+	set, err := newJWTKeySet(keys, cfg.SigningKID)
+	if err != nil {
+		return fmt.Errorf("%s: %w", dir, err)
+	}
+	jwtKeys = set
+	return nil
+}
+
+func loadJWTKeys(dir string) ([]*jwtKey, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.pem"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(paths)
+
+	var keys []*jwtKey
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		kid := strings.TrimSuffix(filepath.Base(path), ".pem")
+		key, err := parseJWTKey(kid, data)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+func newJWTKeySet(keys []*jwtKey, signingKID string) (*jwtKeySet, error) {
+	set := &jwtKeySet{verify: map[string]*jwtKey{}}
+	var privateKeys []*jwtKey
+	for _, key := range keys {
+		set.verify[key.ID] = key
+		if key.Private != nil {
+			privateKeys = append(privateKeys, key)
+		}
+	}
+
+	switch {
+	case signingKID != "":
+		key, ok := set.verify[signingKID]
+		if !ok || key.Private == nil {
+			return nil, fmt.Errorf("JWT_SIGNING_KID %q does not name a private key", signingKID)
+		}
+		set.signing = key
+	case len(privateKeys) == 1:
+		set.signing = privateKeys[0]
+	case len(privateKeys) == 0:
+		return nil, errors.New("no JWT private key found")
+	default:
+		return nil, errors.New("several JWT private keys found, set JWT_SIGNING_KID to choose one")
+	}
+	return set, nil
+}
+
+// parseJWTKey membaca satu kunci PEM (PKCS#8, PKCS#1 atau PKIX) dan menentukan algoritmanya
+func parseJWTKey(kid string, data []byte) (*jwtKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("no PEM block found")
+	}
+
+	var parsed interface{}
+	var err error
+	switch block.Type {
+	case "PRIVATE KEY":
+		parsed, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "RSA PRIVATE KEY":
+		parsed, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "PUBLIC KEY":
+		parsed, err = x509.ParsePKIXPublicKey(block.Bytes)
+	case "RSA PUBLIC KEY":
+		parsed, err = x509.ParsePKCS1PublicKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("unsupported PEM block %q", block.Type)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	key := &jwtKey{ID: kid}
+	switch k := parsed.(type) {
+	case *rsa.PrivateKey:
+		key.Private, key.Public = k, &k.PublicKey
+	case *rsa.PublicKey:
+		key.Public = k
+	case ed25519.PrivateKey:
+		key.Private, key.Public = k, k.Public()
+	case ed25519.PublicKey:
+		key.Public = k
+	default:
+		return nil, fmt.Errorf("unsupported key type %T, use RSA or Ed25519", parsed)
+	}
+
+	switch pub := key.Public.(type) {
+	case *rsa.PublicKey:
+		if pub.N.BitLen() < minRSAKeyBits {
+			return nil, fmt.Errorf("RSA key must be at least %d bits", minRSAKeyBits)
+		}
+		key.Method = jwt.SigningMethodRS256
+	case ed25519.PublicKey:
+		key.Method = jwt.SigningMethodEdDSA
+	}
+	return key, nil
+}
+
+// JSONWebKey adalah public key dalam format JWK (RFC 7517)
+type JSONWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+}
+
+// JSONWebKeySet adalah isi /.well-known/jwks.json
+type JSONWebKeySet struct {
+	Keys []JSONWebKey `json:"keys"`
+}
+
+// JWKS mengembalikan semua kunci verifikasi yang aktif, terurut berdasarkan kid
+func JWKS() JSONWebKeySet {
+	set := JSONWebKeySet{Keys: []JSONWebKey{}}
+	if jwtKeys == nil {
+		return set
+	}
+
+	for _, key := range jwtKeys.verify {
+		jwk := JSONWebKey{Kid: key.ID, Use: "sig", Alg: key.Method.Alg()}
+		switch pub := key.Public.(type) {
+		case *rsa.PublicKey:
+			jwk.Kty = "RSA"
+			jwk.N = base64.RawURLEncoding.EncodeToString(pub.N.Bytes())
+			jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes())
+		case ed25519.PublicKey:
+			jwk.Kty = "OKP"
+			jwk.Crv = "Ed25519"
+			jwk.X = base64.RawURLEncoding.EncodeToString(pub)
+		}
+		set.Keys = append(set.Keys, jwk)
+	}
+	sort.Slice(set.Keys, func(i, j int) bool { return set.Keys[i].Kid < set.Keys[j].Kid })
+	return set
+}
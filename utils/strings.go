@@ -0,0 +1,25 @@
+// utils/strings.go
+package utils
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
+// EscapeLike meng-escape karakter wildcard pola LIKE; query harus memakai ESCAPE '\'
+func EscapeLike(s string) string {
+	return likeEscaper.Replace(s)
+}
+
+// Truncate memotong s menjadi paling banyak n byte tanpa memotong di tengah karakter UTF-8
+func Truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n]
+}
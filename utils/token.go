@@ -0,0 +1,39 @@
+// utils/token.go
+package utils
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"math/big"
+)
+
+// RandomToken menghasilkan token acak yang aman untuk URL dari n byte acak
+func RandomToken(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// HashToken menghasilkan hash SHA-256 (hex) dari token acak untuk disimpan di database.
+// Hanya untuk token dengan entropi tinggi atau yang percobaannya dibatasi, bukan untuk password.
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// RandomDigits menghasilkan n digit angka acak
+func RandomDigits(n int) (string, error) {
+	b := make([]byte, n)
+	for i := range b {
+		d, err := rand.Int(rand.Reader, big.NewInt(10))
+		if err != nil {
+			return "", err
+		}
+		b[i] = '0' + byte(d.Int64())
+	}
+	return string(b), nil
+}
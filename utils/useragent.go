@@ -0,0 +1,61 @@
+// utils/useragent.go
+package utils
+
+import (
+	"strings"
+)
+
+// userAgentBrowsers dan userAgentSystems dicocokkan berurutan; yang lebih spesifik di depan
+// (mis. Edge dan Opera juga menyebut Chrome, Chrome juga menyebut Safari)
+var userAgentBrowsers = []struct{ token, name string }{
+	{"Edg/", "Edge"},
+	{"OPR/", "Opera"},
+	{"SamsungBrowser/", "Samsung Internet"},
+	{"Firefox/", "Firefox"},
+	{"CriOS/", "Chrome"},
+	{"Chrome/", "Chrome"},
+	{"Safari/", "Safari"},
+	{"curl/", "curl"},
+	{"python-requests/", "Python"},
+	{"Go-http-client/", "Go"},
+	{"PostmanRuntime/", "Postman"},
+	{"okhttp/", "Android app"},
+}
+
+var userAgentSystems = []struct{ token, name string }{
+	{"Windows", "Windows"},
+	{"Android", "Android"},
+	{"iPhone", "iPhone"},
+	{"iPad", "iPad"},
+	{"Mac OS X", "macOS"},
+	{"CrOS", "ChromeOS"},
+	{"Linux", "Linux"},
+}
+
+// DeviceName membuat nama perangkat yang mudah dibaca dari User-Agent, mis. "Chrome on Windows"
+func DeviceName(userAgent string) string {
+	var browser, system string
+	for _, b := range userAgentBrowsers {
+		if strings.Contains(userAgent, b.token) {
+			browser = b.name
+			break
+		}
+	}
+	for _, s := range userAgentSystems {
+		if strings.Contains(userAgent, s.token) {
+			system = s.name
+			break
+		}
+	}
+
+	switch {
+	case browser != "" && system != "":
+		return browser + " on " + system
+	case browser != "":
+		return browser
+	case system != "":
+		return system
+	default:
+		return "Unknown device"
+	}
+}